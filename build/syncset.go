@@ -5,14 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"sort"
 	"strings"
 
 	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/syncset"
 	webhooks "github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
 	utils "github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
-	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -20,13 +19,13 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/pointer"
 
 	"github.com/ghodss/yaml"
 )
 
 const (
 	serviceName string = "validation-webhook"
+	servicePort int32  = 443
 	repoName    string = "managed-cluster-validating-webhooks"
 )
 
@@ -37,7 +36,9 @@ var (
 	templateFile  = flag.String("outfile", "", "Path to where the SelectorSyncSet template should be written")
 	excludes      = flag.String("exclude", "debug-hook", "Comma-separated list of webhook names to skip")
 	only          = flag.String("only", "", "Only include these comma-separated webhooks")
+	disabledCaps  = flag.String("disabled-capabilities", "", "Comma-separated cluster capabilities which are disabled, the webhooks requiring them are skipped")
 	showHookNames = flag.Bool("showhooks", false, "Print registered webhook names and exit")
+	configFile    = flag.String("config-file", "", "YAML file holding the settings of the webhooks, eg the API versions they are called for, if any")
 
 	namespace = flag.String("namespace", "openshift-validation-webhook", "In what namespace should resources exist?")
 
@@ -77,6 +78,12 @@ func createClusterRole() *rbacv1.ClusterRole {
 				Resources: []string{"groups"},
 				Verbs:     []string{"list", "get"},
 			},
+			// (scc-validation): Read the live version of default SCCs
+			{
+				APIGroups: []string{"security.openshift.io"},
+				Resources: []string{"securitycontextconstraints"},
+				Verbs:     []string{"get"},
+			},
 		},
 	}
 }
@@ -291,7 +298,7 @@ func createService() *corev1.Service {
 			Ports: []corev1.ServicePort{
 				{
 					Name: "https",
-					Port: 443,
+					Port: servicePort,
 					TargetPort: intstr.IntOrString{
 						IntVal: int32(*listenPort),
 						Type:   intstr.Int,
@@ -303,52 +310,6 @@ func createService() *corev1.Service {
 	}
 }
 
-// hookToResources turns a Webhook into a ValidatingWebhookConfiguration and Service.
-// The Webhook is expected to implement Rules() which will return a
-func createValidatingWebhookConfiguration(hook webhooks.Webhook) admissionregv1.ValidatingWebhookConfiguration {
-	failPolicy := hook.FailurePolicy()
-	timeout := hook.TimeoutSeconds()
-	matchPolicy := hook.MatchPolicy()
-	sideEffects := hook.SideEffects()
-
-	return admissionregv1.ValidatingWebhookConfiguration{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "ValidatingWebhookConfiguration",
-			APIVersion: "admissionregistration.k8s.io/v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name: fmt.Sprintf("sre-%s", hook.Name()),
-
-			Annotations: map[string]string{
-				// service.beta.openshift.io/inject-cabundle annotation will instruct
-				// service-ca-operator to install a CA cert in the
-				// ValidatingWebhookConfiguration object, which is required for
-				// Kubernetes to communicate securely to the Service.
-				"service.beta.openshift.io/inject-cabundle": "true",
-			},
-		},
-		Webhooks: []admissionregv1.ValidatingWebhook{
-			{
-				AdmissionReviewVersions: []string{"v1"},
-				TimeoutSeconds:          &timeout,
-				SideEffects:             &sideEffects,
-				MatchPolicy:             &matchPolicy,
-				Name:                    fmt.Sprintf("%s.managed.openshift.io", hook.Name()),
-				ObjectSelector:          hook.ObjectSelector(),
-				FailurePolicy:           &failPolicy,
-				ClientConfig: admissionregv1.WebhookClientConfig{
-					Service: &admissionregv1.ServiceReference{
-						Namespace: *namespace,
-						Path:      pointer.StringPtr(hook.GetURI()),
-						Name:      serviceName,
-					},
-				},
-				Rules: hook.Rules(),
-			},
-		},
-	}
-}
-
 func sliceContains(needle string, haystack []string) bool {
 	for _, hay := range haystack {
 		if hay == needle {
@@ -361,6 +322,14 @@ func sliceContains(needle string, haystack []string) bool {
 func main() {
 	flag.Parse()
 
+	// The webhooks are registered as the configuration file sets them up
+	if *configFile != "" {
+		if err := config.LoadFile(*configFile); err != nil {
+			fmt.Printf("Couldn't load the configuration file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	skip := strings.Split(*excludes, ",")
 	onlyInclude := strings.Split(*only, "")
 
@@ -373,16 +342,16 @@ func main() {
 	templateResources.Add(utils.DefaultLabelSelector(), runtime.RawExtension{Object: createService()})
 	templateResources.Add(utils.DefaultLabelSelector(), runtime.RawExtension{Object: createDaemonSet()})
 
-	// Collect all of our webhook names and prepare to sort them all so the
-	// resulting SelectorSyncSet is always sorted.
-	hookNames := make([]string, 0)
-	for name := range webhooks.Webhooks {
-		hookNames = append(hookNames, name)
+	// Webhook names come sorted, so the resulting SelectorSyncSet is always
+	// sorted.
+	hooks := webhooks.Webhooks
+	if *disabledCaps != "" {
+		hooks = hooks.WithoutDisabledCapabilities(strings.Split(*disabledCaps, ","))
 	}
-	sort.Strings(hookNames)
+	hookNames := hooks.Names()
 	seen := make(map[string]bool)
 	for _, hookName := range hookNames {
-		hook := webhooks.Webhooks[hookName]
+		hook := hooks[hookName]
 		if seen[hook().GetURI()] {
 			panic(fmt.Sprintf("Duplicate hook URI: %s", hook().GetURI()))
 		}
@@ -402,7 +371,17 @@ func main() {
 		if len(onlyInclude) > 0 && !sliceContains(hook().Name(), onlyInclude) {
 			continue
 		}
-		templateResources.Add(hook().SyncSetLabelSelector(), runtime.RawExtension{Raw: syncset.Encode(createValidatingWebhookConfiguration(hook()))})
+		service := webhooks.ServiceConfig{
+			Namespace: *namespace,
+			Name:      serviceName,
+			Port:      servicePort,
+		}
+		configuration, err := webhooks.WebhookConfiguration(hook(), service)
+		if err != nil {
+			fmt.Printf("Couldn't render the configuration of %s: %v\n", hookName, err)
+			os.Exit(1)
+		}
+		templateResources.Add(hook().SyncSetLabelSelector(), runtime.RawExtension{Raw: syncset.Encode(configuration)})
 	}
 
 	if *showHookNames {
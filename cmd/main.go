@@ -9,13 +9,24 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	klog "k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/audit"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/debug"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/dispatcher"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/selftest"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
 )
 
 var log = logf.Log.WithName("handler")
@@ -29,26 +40,56 @@ var (
 	tlsKey  = flag.String("tlskey", "", "TLS Key for TLS")
 	tlsCert = flag.String("tlscert", "", "TLS Certificate")
 	caCert  = flag.String("cacert", "", "CA Cert file")
+
+	maintenanceConfig = flag.String("maintenance-config", "", "Directory the maintenance window ConfigMap is mounted on, if any. It is re-read on SIGHUP")
+	decisionSinkURL   = flag.String("decision-sink-url", "", "URL to POST every decision to as JSON, for central audit")
+	disabledWebhooks  = flag.String("disable-webhooks", "", "Comma-separated names of registered webhooks not to serve")
+	disabledCaps      = flag.String("disabled-capabilities", "", "Comma-separated cluster capabilities which are disabled, the webhooks requiring them aren't served")
+	configFile        = flag.String("config-file", "", "YAML file holding the settings of the webhooks and the feature gates, if any. It is re-read on SIGHUP")
+	allowOnceSecret   = flag.String("allow-once-secret", "", "File holding the secret allow-once tokens are signed with, if any. It is re-read on SIGHUP")
+	liveReads         = flag.Bool("live-reads", false, "Read the live version of default SCCs to detect out-of-band changes?")
+	corpus            = flag.String("corpus", "", "Directory of AdmissionReview fixtures to decide on and quit, see -decisions")
+	decisionsFile     = flag.String("decisions", "decisions.jsonl", "File the decisions on the -corpus fixtures are written to")
 )
 
 func main() {
+	// Registers klog's flags, such as -v, which sets the verbosity of
+	// log.V(n) messages
+	klog.InitFlags(nil)
 	flag.Parse()
 	klog.SetOutput(os.Stdout)
 
 	logf.SetLogger(klogr.New())
 
-	if !*testHooks {
+	// The configuration file is validated on startup, so a bad one is caught
+	// before serving rather than silently ignored
+	if *configFile != "" {
+		if err := config.WatchFile(*configFile); err != nil {
+			log.Error(err, "Couldn't load the configuration file")
+			os.Exit(1)
+		}
+	}
+
+	serving := !*testHooks && *corpus == ""
+	if serving {
 		log.Info("HTTP server running at", "listen", net.JoinHostPort(*listenAddress, *listenPort))
 	}
-	dispatcher := dispatcher.NewDispatcher(webhooks.Webhooks)
+	hooks := webhooks.Webhooks
+	if *disabledWebhooks != "" {
+		hooks = hooks.Without(strings.Split(*disabledWebhooks, ","))
+	}
+	if *disabledCaps != "" {
+		hooks = hooks.WithoutDisabledCapabilities(strings.Split(*disabledCaps, ","))
+	}
+	dispatcher := dispatcher.NewDispatcher(hooks)
 	seen := make(map[string]bool)
-	for name, hook := range webhooks.Webhooks {
-		realHook := hook()
+	for _, name := range hooks.Names() {
+		realHook := hooks[name]()
 		if seen[realHook.GetURI()] {
 			panic(fmt.Errorf("Duplicate webhook trying to lisen on %s", realHook.GetURI()))
 		}
-		seen[name] = true
-		if !*testHooks {
+		seen[realHook.GetURI()] = true
+		if serving {
 			log.Info("Listening", "webhookName", name, "URI", realHook.GetURI())
 		}
 		http.HandleFunc(realHook.GetURI(), dispatcher.HandleRequest)
@@ -56,6 +97,43 @@ func main() {
 	if *testHooks {
 		os.Exit(0)
 	}
+	if *corpus != "" {
+		if err := writeCorpusDecisions(dispatcher); err != nil {
+			log.Error(err, "Couldn't decide on the corpus")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if *decisionSinkURL != "" {
+		exporter := audit.NewExporter(*decisionSinkURL, audit.DefaultBufferSize)
+		go exporter.Run(make(chan struct{}))
+		dispatcher.ExportDecisions(exporter)
+		log.Info("Exporting decisions", "url", *decisionSinkURL)
+	}
+
+	http.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	http.HandleFunc(selftest.URI, selftest.Handler())
+	http.HandleFunc(debug.URI, debug.Handler())
+
+	if *maintenanceConfig != "" {
+		if err := config.WatchMaintenanceWindow(*maintenanceConfig); err != nil {
+			log.Error(err, "Couldn't load the maintenance window, enforcing normally")
+		}
+	}
+	if *allowOnceSecret != "" {
+		if err := config.WatchAllowOnceSecret(*allowOnceSecret); err != nil {
+			log.Error(err, "Couldn't load the allow-once secret, rejecting allow-once tokens")
+		}
+	}
+	if *liveReads {
+		if err := enableLiveReads(); err != nil {
+			log.Error(err, "Couldn't enable live reads, evaluating against the requests alone")
+		}
+	}
+	config.RecordPolicyVersion()
+	// Re-read the configuration file, the maintenance window and the
+	// allow-once secret on SIGHUP without restarting the server
+	config.WatchReloadSignal(make(chan struct{}))
 
 	server := &http.Server{
 		Addr: net.JoinHostPort(*listenAddress, *listenPort),
@@ -77,3 +155,33 @@ func main() {
 		log.Error(server.ListenAndServe(), "Error serving non-TLS connection")
 	}
 }
+
+// writeCorpusDecisions writes the decisions on the -corpus fixtures to the
+// -decisions file, for them to be diffed across releases
+func writeCorpusDecisions(d *dispatcher.Dispatcher) error {
+	decisions, err := d.RunCorpus(*corpus)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*decisionsFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return dispatcher.WriteDecisions(f, decisions)
+}
+
+// enableLiveReads lets the scc webhook read the live version of default SCCs
+// with the in-cluster credentials of the webhook
+func enableLiveReads() error {
+	restConfig, err := ctrlconfig.GetConfig()
+	if err != nil {
+		return err
+	}
+	reader, err := client.New(restConfig, client.Options{Scheme: utils.Scheme})
+	if err != nil {
+		return err
+	}
+	scc.SetLiveReader(reader)
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/conformance"
+)
+
+var (
+	kubeconfig  = flag.String("kubeconfig", "", "Path to the kubeconfig of the cluster to check")
+	impersonate = flag.String("as", "", "Non-allowlisted user to impersonate while attempting the protected operations")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't load kubeconfig: %s\n", err)
+		os.Exit(1)
+	}
+	if *impersonate != "" {
+		cfg.Impersonate.UserName = *impersonate
+		cfg.Impersonate.Groups = []string{"system:authenticated"}
+	}
+
+	scheme := runtime.NewScheme()
+	securityv1.AddToScheme(scheme)
+	rbacv1.AddToScheme(scheme)
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't create client: %s\n", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, result := range conformance.Run(context.TODO(), c) {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+			failed = true
+		}
+		fmt.Printf("%s\t%s\t%s\t%v\n", status, result.Webhook, result.Description, result.Err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"sort"
 
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
@@ -27,11 +26,7 @@ type docuhook struct {
 
 // WriteDocs will write out all the docs.
 func WriteDocs() {
-	hookNames := make([]string, 0)
-	for name := range webhooks.Webhooks {
-		hookNames = append(hookNames, name)
-	}
-	sort.Strings(hookNames)
+	hookNames := webhooks.Webhooks.Names()
 	dochooks := make([]docuhook, len(hookNames))
 
 	for i, hookName := range hookNames {
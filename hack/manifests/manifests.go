@@ -0,0 +1,49 @@
+package main
+
+// Offer a way to render the webhook configurations of every registered
+// webhook as a single YAML stream, eg for a GitOps deployment
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+)
+
+var (
+	namespace    = flag.String("namespace", "openshift-validation-webhook", "Namespace of the Service fronting the webhooks")
+	serviceName  = flag.String("service", "validation-webhook", "Name of the Service fronting the webhooks")
+	servicePort  = flag.Int("service-port", 443, "Port of the Service fronting the webhooks")
+	excludes     = flag.String("exclude", "debug-hook", "Comma-separated list of webhook names to skip")
+	configFile   = flag.String("config-file", "", "YAML file holding the settings of the webhooks, if any")
+	disabledCaps = flag.String("disabled-capabilities", "", "Comma-separated cluster capabilities which are disabled, the webhooks requiring them are skipped")
+)
+
+func main() {
+	flag.Parse()
+
+	if *configFile != "" {
+		if err := config.LoadFile(*configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't load the configuration file: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	hooks := webhooks.Webhooks.Without(strings.Split(*excludes, ","))
+	if *disabledCaps != "" {
+		hooks = hooks.WithoutDisabledCapabilities(strings.Split(*disabledCaps, ","))
+	}
+	manifests, err := webhooks.Manifests(hooks, webhooks.ServiceConfig{
+		Namespace: *namespace,
+		Name:      *serviceName,
+		Port:      int32(*servicePort),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't render the manifests: %s\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(manifests)
+}
@@ -0,0 +1,143 @@
+// Package audit streams the decisions of the webhooks to a central audit
+// service, as an alternative to scraping them from the pod logs.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+)
+
+const (
+	// DefaultBufferSize is how many decisions wait for delivery before new
+	// ones are dropped
+	DefaultBufferSize int = 1000
+	// DefaultAttempts is how many times delivery of a decision is attempted
+	DefaultAttempts int = 3
+
+	// Reasons a decision is dropped, for metrics.IncrementDecisionExportDrops
+	dropReasonBufferFull    string = "buffer_full"
+	dropReasonUndeliverable string = "undeliverable"
+)
+
+var log = logf.Log.WithName("audit")
+
+// Decision is the outcome of an admission request, as sent to the sink
+type Decision struct {
+	Time      time.Time         `json:"time"`
+	Webhook   string            `json:"webhook"`
+	UID       string            `json:"uid"`
+	Operation string            `json:"operation"`
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	User      string            `json:"user"`
+	Allowed   bool              `json:"allowed"`
+	Message   string            `json:"message,omitempty"`
+	Audit     map[string]string `json:"audit,omitempty"`
+}
+
+// Exporter POSTs each Decision as JSON to a sink URL. Decisions are buffered
+// and delivered by Run, so Export never blocks the admission path: when the
+// buffer is full or the sink stays unavailable, decisions are dropped and
+// counted in webhook_decision_export_drops_total.
+type Exporter struct {
+	url      string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+	queue    chan Decision
+}
+
+// NewExporter creates an Exporter for the sink URL, buffering up to
+// bufferSize decisions
+func NewExporter(url string, bufferSize int) *Exporter {
+	return &Exporter{
+		url:      url,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		attempts: DefaultAttempts,
+		backoff:  500 * time.Millisecond,
+		queue:    make(chan Decision, bufferSize),
+	}
+}
+
+// Export queues the decision for delivery, or drops it when the buffer is
+// full
+func (e *Exporter) Export(decision Decision) {
+	select {
+	case e.queue <- decision:
+	default:
+		metrics.IncrementDecisionExportDrops(dropReasonBufferFull)
+	}
+}
+
+// Run delivers queued decisions until stop is closed. It is meant to be
+// called in its own goroutine.
+func (e *Exporter) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case decision := <-e.queue:
+			if err := e.deliver(decision); err != nil {
+				log.Error(err, "Couldn't export decision", "uid", decision.UID, "webhook", decision.Webhook)
+				metrics.IncrementDecisionExportDrops(dropReasonUndeliverable)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// deliver POSTs the decision, retrying with an exponential backoff on
+// transport errors and server errors. Client errors aren't retried, as the
+// sink would reject the decision again.
+func (e *Exporter) deliver(decision Decision) error {
+	body, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+
+	backoff := e.backoff
+	for attempt := 1; ; attempt++ {
+		err = e.post(body)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*clientError); ok || attempt >= e.attempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// clientError is a 4xx response from the sink
+type clientError struct {
+	status int
+}
+
+func (c *clientError) Error() string {
+	return fmt.Sprintf("sink rejected the decision with status %d", c.status)
+}
+
+func (e *Exporter) post(body []byte) error {
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &clientError{status: resp.StatusCode}
+	default:
+		return fmt.Errorf("sink answered with status %d", resp.StatusCode)
+	}
+}
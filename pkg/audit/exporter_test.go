@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+)
+
+// sink is an audit service recording the decisions it receives. It fails
+// the first failures requests.
+type sink struct {
+	mu        sync.Mutex
+	failures  int
+	decisions []Decision
+}
+
+func (s *sink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	decision := Decision{}
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.decisions = append(s.decisions, decision)
+}
+
+func (s *sink) received() []Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Decision{}, s.decisions...)
+}
+
+func waitForDecisions(t *testing.T, s *sink, count int) []Decision {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.received()) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d decisions to be delivered, got %d", count, len(s.received()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return s.received()
+}
+
+func TestExporterDeliversDecisions(t *testing.T) {
+	s := &sink{failures: 2}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	exporter := NewExporter(server.URL, 10)
+	exporter.backoff = time.Millisecond
+	stop := make(chan struct{})
+	defer close(stop)
+	go exporter.Run(stop)
+
+	exporter.Export(Decision{Webhook: "scc-validation", UID: "1", User: "user1", Allowed: false, Message: "denied"})
+	exporter.Export(Decision{Webhook: "scc-validation", UID: "2", User: "user2", Allowed: true})
+
+	decisions := waitForDecisions(t, s, 2)
+	if decisions[0].UID != "1" || decisions[0].Allowed || decisions[0].Message != "denied" {
+		t.Fatalf("Unexpected first decision %+v", decisions[0])
+	}
+	if decisions[1].UID != "2" || !decisions[1].Allowed {
+		t.Fatalf("Unexpected second decision %+v", decisions[1])
+	}
+}
+
+func TestExporterDropsWhenBufferIsFull(t *testing.T) {
+	before := promtestutil.ToFloat64(metrics.DecisionExportDrops.WithLabelValues(dropReasonBufferFull))
+
+	// Not running, so nothing drains the buffer
+	exporter := NewExporter("http://127.0.0.1:0", 1)
+	exporter.Export(Decision{UID: "1"})
+	exporter.Export(Decision{UID: "2"})
+
+	after := promtestutil.ToFloat64(metrics.DecisionExportDrops.WithLabelValues(dropReasonBufferFull))
+	if after-before != 1 {
+		t.Fatalf("Expected 1 decision to be dropped, got %v", after-before)
+	}
+}
+
+func TestExporterGivesUpOnUnavailableSink(t *testing.T) {
+	before := promtestutil.ToFloat64(metrics.DecisionExportDrops.WithLabelValues(dropReasonUndeliverable))
+	s := &sink{failures: DefaultAttempts}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	exporter := NewExporter(server.URL, 10)
+	exporter.backoff = time.Millisecond
+	stop := make(chan struct{})
+	defer close(stop)
+	go exporter.Run(stop)
+
+	exporter.Export(Decision{UID: "lost"})
+	exporter.Export(Decision{UID: "delivered"})
+
+	decisions := waitForDecisions(t, s, 1)
+	if decisions[0].UID != "delivered" {
+		t.Fatalf("Expected only the second decision to be delivered, got %+v", decisions)
+	}
+	after := promtestutil.ToFloat64(metrics.DecisionExportDrops.WithLabelValues(dropReasonUndeliverable))
+	if after-before != 1 {
+		t.Fatalf("Expected 1 decision to be dropped, got %v", after-before)
+	}
+}
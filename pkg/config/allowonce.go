@@ -0,0 +1,49 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+var (
+	allowOnceSecretMu sync.RWMutex
+	allowOnceSecret   []byte
+)
+
+// AllowOnceSecret returns the secret allow-once tokens are signed with, see
+// utils.AllowOnceVerifier. It is empty when none is configured, and tokens
+// are then rejected.
+func AllowOnceSecret() []byte {
+	allowOnceSecretMu.RLock()
+	defer allowOnceSecretMu.RUnlock()
+	return allowOnceSecret
+}
+
+// SetAllowOnceSecret replaces the secret allow-once tokens are signed with
+func SetAllowOnceSecret(secret []byte) {
+	allowOnceSecretMu.Lock()
+	defer allowOnceSecretMu.Unlock()
+	allowOnceSecret = secret
+}
+
+// WatchAllowOnceSecret loads the allow-once secret from the file a Secret
+// key is mounted on, and registers a Reloader so it is re-read on SIGHUP,
+// eg after a rotation. A missing file means no secret.
+func WatchAllowOnceSecret(path string) error {
+	reload := func() error {
+		b, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			SetAllowOnceSecret(nil)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		SetAllowOnceSecret(bytes.TrimSpace(b))
+		return nil
+	}
+	RegisterReloader("allow-once-secret", reload)
+	return reload()
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SafeMode is how a webhook answers requests while its circuit is open
+type SafeMode string
+
+const (
+	// SafeModeDeny denies every request, which is the default, so that a
+	// failing protection doesn't turn into a bypass
+	SafeModeDeny SafeMode = "Deny"
+	// SafeModeReportOnly allows every request, with a warning
+	SafeModeReportOnly SafeMode = "ReportOnly"
+)
+
+// CircuitSettings tune the circuit disabling a webhook which errors on too
+// many requests. The circuit opens when, within a window, the webhook
+// answered at least MinRequests requests and ErrorRate of them errored. The
+// webhook then answers in its SafeMode for CooldownSeconds, before being
+// tried again. Fields which are unset keep their default.
+type CircuitSettings struct {
+	// ErrorRate is the share of errored requests opening the circuit,
+	// greater than 0 and at most 1
+	ErrorRate       float64  `json:"errorRate,omitempty"`
+	MinRequests     int      `json:"minRequests,omitempty"`
+	WindowSeconds   int32    `json:"windowSeconds,omitempty"`
+	CooldownSeconds int32    `json:"cooldownSeconds,omitempty"`
+	SafeMode        SafeMode `json:"safeMode,omitempty"`
+	// SafeModes overrides SafeMode, by webhook name
+	SafeModes map[string]SafeMode `json:"safeModes,omitempty"`
+}
+
+// DefaultCircuitSettings are the settings of the circuit when the
+// configuration file doesn't set them
+var DefaultCircuitSettings = CircuitSettings{
+	ErrorRate:       0.5,
+	MinRequests:     20,
+	WindowSeconds:   60,
+	CooldownSeconds: 300,
+	SafeMode:        SafeModeDeny,
+}
+
+var (
+	circuitMu       sync.RWMutex
+	circuitSettings = DefaultCircuitSettings
+)
+
+func init() {
+	RegisterPolicySource("circuit", func() interface{} {
+		return Circuit()
+	})
+}
+
+// Circuit returns the settings of the circuit
+func Circuit() CircuitSettings {
+	circuitMu.RLock()
+	defer circuitMu.RUnlock()
+	return circuitSettings
+}
+
+// SetCircuit replaces the settings of the circuit, unset fields taking their
+// default
+func SetCircuit(settings CircuitSettings) {
+	defaults := DefaultCircuitSettings
+	if settings.ErrorRate == 0 {
+		settings.ErrorRate = defaults.ErrorRate
+	}
+	if settings.MinRequests == 0 {
+		settings.MinRequests = defaults.MinRequests
+	}
+	if settings.WindowSeconds == 0 {
+		settings.WindowSeconds = defaults.WindowSeconds
+	}
+	if settings.CooldownSeconds == 0 {
+		settings.CooldownSeconds = defaults.CooldownSeconds
+	}
+	if settings.SafeMode == "" {
+		settings.SafeMode = defaults.SafeMode
+	}
+	circuitMu.Lock()
+	defer circuitMu.Unlock()
+	circuitSettings = settings
+}
+
+// Window is how long errors are counted over
+func (c CircuitSettings) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+// Cooldown is how long the circuit stays open
+func (c CircuitSettings) Cooldown() time.Duration {
+	return time.Duration(c.CooldownSeconds) * time.Second
+}
+
+// SafeModeOf returns the SafeMode of the webhook
+func (c CircuitSettings) SafeModeOf(webhook string) SafeMode {
+	if mode, ok := c.SafeModes[webhook]; ok {
+		return mode
+	}
+	return c.SafeMode
+}
+
+func (c CircuitSettings) validate(path string) []string {
+	var problems []string
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		problems = append(problems, fmt.Sprintf("%s.errorRate: must be between 0 and 1, not %v", path, c.ErrorRate))
+	}
+	if c.MinRequests < 0 {
+		problems = append(problems, fmt.Sprintf("%s.minRequests: must not be negative, not %d", path, c.MinRequests))
+	}
+	if c.WindowSeconds < 0 {
+		problems = append(problems, fmt.Sprintf("%s.windowSeconds: must not be negative, not %d", path, c.WindowSeconds))
+	}
+	if c.CooldownSeconds < 0 {
+		problems = append(problems, fmt.Sprintf("%s.cooldownSeconds: must not be negative, not %d", path, c.CooldownSeconds))
+	}
+	if !validSafeMode(c.SafeMode) {
+		problems = append(problems, fmt.Sprintf("%s.safeMode: must be %s or %s, not %q", path, SafeModeDeny, SafeModeReportOnly, c.SafeMode))
+	}
+	for name, mode := range c.SafeModes {
+		if mode == "" || !validSafeMode(mode) {
+			problems = append(problems, fmt.Sprintf("%s.safeModes.%s: must be %s or %s, not %q", path, name, SafeModeDeny, SafeModeReportOnly, mode))
+		}
+	}
+	return problems
+}
+
+func validSafeMode(mode SafeMode) bool {
+	switch mode {
+	case "", SafeModeDeny, SafeModeReportOnly:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"sort"
+	"sync"
+)
+
+// ReloadInProgress is the degraded reason set while ReloadAll runs
+const ReloadInProgress string = "configuration reload in progress"
+
+var (
+	degradedMu      sync.RWMutex
+	degradedReasons = map[string]struct{}{}
+)
+
+// SetDegraded marks the webhooks as briefly unable to decide on requests, for
+// the given reason, until ClearDegraded is called with the same reason.
+// Meanwhile, requests are answered with a retriable error rather than a
+// decision based on a partial configuration.
+func SetDegraded(reason string) {
+	degradedMu.Lock()
+	defer degradedMu.Unlock()
+	degradedReasons[reason] = struct{}{}
+}
+
+// ClearDegraded removes a reason set by SetDegraded
+func ClearDegraded(reason string) {
+	degradedMu.Lock()
+	defer degradedMu.Unlock()
+	delete(degradedReasons, reason)
+}
+
+// Degraded returns the reasons the webhooks are currently degraded for, in
+// sorted order. It is empty when they are healthy.
+func Degraded() []string {
+	degradedMu.RLock()
+	defer degradedMu.RUnlock()
+	reasons := make([]string, 0, len(degradedReasons))
+	for reason := range degradedReasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
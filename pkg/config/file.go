@@ -0,0 +1,309 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+)
+
+// Mode is how a webhook acts on the requests it would deny
+type Mode string
+
+const (
+	// ModeEnforce denies the requests, which is the default
+	ModeEnforce Mode = "Enforce"
+	// ModeDryRun allows the requests, with a warning that they would be
+	// denied
+	ModeDryRun Mode = "DryRun"
+
+	// maxTimeoutSeconds is the longest timeout the API server accepts for a
+	// webhook
+	maxTimeoutSeconds int32 = 30
+)
+
+// File is the configuration file given with -config-file. It holds the
+// settings of every webhook, and the feature gates, in one place. Every field
+// is optional.
+type File struct {
+	// FeatureGates turns registered feature gates on or off, by name, see
+	// RegisterFeatureGate
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// Webhooks holds the settings of each webhook, by webhook name. Only
+	// webhooks which registered a Section can be configured.
+	Webhooks map[string]WebhookSettings `json:"webhooks,omitempty"`
+	// Circuit tunes the circuit disabling failing webhooks, see
+	// CircuitSettings
+	Circuit *CircuitSettings `json:"circuit,omitempty"`
+}
+
+// WebhookSettings is the section of a webhook in the configuration file. Lists
+// which are set replace the built-in ones of the webhook, lists which are
+// unset keep the built-in ones.
+type WebhookSettings struct {
+	Mode           Mode     `json:"mode,omitempty"`
+	TimeoutSeconds int32    `json:"timeoutSeconds,omitempty"`
+	Protected      []string `json:"protected,omitempty"`
+	AllowedUsers   []string `json:"allowedUsers,omitempty"`
+	AllowedGroups  []string `json:"allowedGroups,omitempty"`
+	// Parameters holds the settings specific to the webhook, by name. The
+	// Section of the webhook rejects the names it doesn't know.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// Section validates the settings of a webhook from the configuration file,
+// and returns how to apply them. It must not change anything itself, so that
+// a file is either applied entirely or not at all.
+type Section func(WebhookSettings) (func(), error)
+
+// registeredSection is a Section, with the package variables it sets
+type registeredSection struct {
+	apply Section
+	// settings are the variables the Section sets, and defaults their
+	// values when it was registered
+	settings []reflect.Value
+	defaults []reflect.Value
+}
+
+// reset sets the variables of the Section back to their defaults
+func (s registeredSection) reset() {
+	for i, setting := range s.settings {
+		setting.Set(s.defaults[i])
+	}
+}
+
+var (
+	sectionsMu sync.Mutex
+	sections   = map[string]registeredSection{}
+
+	// settingsMu guards the variables the Sections set. A file is applied
+	// under the write lock, so that readers see either all of it or none.
+	settingsMu sync.RWMutex
+
+	featureGatesMu       sync.RWMutex
+	featureGates         = map[string]bool{}
+	featureGatesDefaults = map[string]bool{}
+)
+
+func init() {
+	RegisterPolicySource("feature-gates", func() interface{} {
+		featureGatesMu.RLock()
+		defer featureGatesMu.RUnlock()
+		gates := make(map[string]bool, len(featureGates))
+		for name, enabled := range featureGates {
+			gates[name] = enabled
+		}
+		return gates
+	})
+}
+
+// RegisterSection registers how the named webhook applies its section of the
+// configuration file. settings are pointers to the package variables the
+// Section sets, whose current values are their defaults: every file is
+// applied on top of the defaults, so that a setting removed from the file
+// reverts to its default. The variables must only be replaced, never
+// modified in place. Registering the same name twice replaces the previous
+// Section.
+func RegisterSection(name string, section Section, settings ...interface{}) {
+	registered := registeredSection{apply: section}
+	for _, setting := range settings {
+		value := reflect.ValueOf(setting)
+		if value.Kind() != reflect.Ptr {
+			panic(fmt.Sprintf("setting %T of section %s is not a pointer", setting, name))
+		}
+		defaultValue := reflect.New(value.Elem().Type()).Elem()
+		defaultValue.Set(value.Elem())
+		registered.settings = append(registered.settings, value.Elem())
+		registered.defaults = append(registered.defaults, defaultValue)
+	}
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+	sections[name] = registered
+}
+
+// ReadSettings runs read with the variables the Sections set locked for
+// reading, so that no file is applied meanwhile. Everything reading them
+// while requests are served, eg Authorized, runs under it.
+func ReadSettings(read func()) {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	read()
+}
+
+// RegisterFeatureGate registers a feature gate and its default state, so the
+// configuration file may turn it on or off
+func RegisterFeatureGate(name string, enabled bool) {
+	featureGatesMu.Lock()
+	defer featureGatesMu.Unlock()
+	featureGates[name] = enabled
+	featureGatesDefaults[name] = enabled
+}
+
+// FeatureGateEnabled checks if the registered feature gate is on. Unregistered
+// gates are off.
+func FeatureGateEnabled(name string) bool {
+	featureGatesMu.RLock()
+	defer featureGatesMu.RUnlock()
+	return featureGates[name]
+}
+
+// LoadFile reads the configuration file at path, validates it, then applies
+// it to the feature gates and to the registered Sections. Nothing is applied
+// when the file is invalid, including when a Section rejects its settings.
+func LoadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	file, err := ParseFile(b)
+	if err != nil {
+		return fmt.Errorf("invalid configuration file %s: %v", path, err)
+	}
+	return apply(file)
+}
+
+// WatchFile loads the configuration file at path, and registers a Reloader so
+// it is re-read on SIGHUP. An invalid file is reported and leaves the
+// previous configuration in effect. Settings removed from the file revert to
+// their default.
+func WatchFile(path string) error {
+	RegisterReloader("config-file", func() error {
+		return LoadFile(path)
+	})
+	return LoadFile(path)
+}
+
+// ParseFile decodes and validates a configuration file. Unknown fields are
+// rejected, so that a typo doesn't silently leave a setting out.
+func ParseFile(b []byte) (*File, error) {
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	file := &File{}
+	decoder := json.NewDecoder(bytes.NewReader(j))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(file); err != nil {
+		return nil, err
+	}
+	if err := file.validate(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// validate checks the file against what is registered, and the settings
+// against their allowed values, including those only their Section knows
+func (f *File) validate() error {
+	var problems []string
+
+	featureGatesMu.RLock()
+	for name := range f.FeatureGates {
+		if _, ok := featureGates[name]; !ok {
+			problems = append(problems, fmt.Sprintf("featureGates: unknown feature gate %q", name))
+		}
+	}
+	featureGatesMu.RUnlock()
+
+	sectionsMu.Lock()
+	for name, settings := range f.Webhooks {
+		if _, ok := sections[name]; !ok {
+			problems = append(problems, fmt.Sprintf("webhooks: %q can't be configured", name))
+			continue
+		}
+		problems = append(problems, settings.validate("webhooks."+name)...)
+		if _, err := sections[name].apply(settings); err != nil {
+			problems = append(problems, fmt.Sprintf("webhooks.%s: %v", name, err))
+		}
+	}
+	sectionsMu.Unlock()
+
+	if f.Circuit != nil {
+		problems = append(problems, f.Circuit.validate("circuit")...)
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (s WebhookSettings) validate(path string) []string {
+	var problems []string
+	switch s.Mode {
+	case "", ModeEnforce, ModeDryRun:
+	default:
+		problems = append(problems, fmt.Sprintf("%s.mode: must be %s or %s, not %q", path, ModeEnforce, ModeDryRun, s.Mode))
+	}
+	if s.TimeoutSeconds < 0 || s.TimeoutSeconds > maxTimeoutSeconds {
+		problems = append(problems, fmt.Sprintf("%s.timeoutSeconds: must be between 1 and %d, not %d", path, maxTimeoutSeconds, s.TimeoutSeconds))
+	}
+	for field, list := range map[string][]string{
+		"protected":     s.Protected,
+		"allowedUsers":  s.AllowedUsers,
+		"allowedGroups": s.AllowedGroups,
+	} {
+		for i, entry := range list {
+			if strings.TrimSpace(entry) == "" {
+				problems = append(problems, fmt.Sprintf("%s.%s[%d]: must not be empty", path, field, i))
+			}
+		}
+	}
+	return problems
+}
+
+// apply applies a validated file on top of the defaults. Every Section is
+// staged before anything is changed, so that a Section rejecting its settings
+// leaves everything as is, then they are all committed at once under
+// settingsMu.
+func apply(file *File) error {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	staged := make([]func(), 0, len(names))
+	for _, name := range names {
+		commit, err := sections[name].apply(file.Webhooks[name])
+		if err != nil {
+			return fmt.Errorf("invalid configuration of %s: %v", name, err)
+		}
+		staged = append(staged, commit)
+	}
+
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	featureGatesMu.Lock()
+	for name, enabled := range featureGatesDefaults {
+		featureGates[name] = enabled
+	}
+	for name, enabled := range file.FeatureGates {
+		featureGates[name] = enabled
+	}
+	featureGatesMu.Unlock()
+
+	circuit := CircuitSettings{}
+	if file.Circuit != nil {
+		circuit = *file.Circuit
+	}
+	SetCircuit(circuit)
+
+	for i, commit := range staged {
+		sections[names[i]].reset()
+		commit()
+		if _, ok := file.Webhooks[names[i]]; ok {
+			log.Info("Configured webhook from the configuration file", "webhookName", names[i])
+		}
+	}
+	return nil
+}
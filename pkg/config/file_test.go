@@ -0,0 +1,291 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSectionName string = "test-validation"
+
+func writeConfigFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config-file")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// registerTestSection registers a Section recording the settings it is
+// applied with. It rejects the settings with a "reject" parameter.
+func registerTestSection(t *testing.T) (*[]WebhookSettings, func()) {
+	applied := &[]WebhookSettings{}
+	RegisterSection(testSectionName, func(settings WebhookSettings) (func(), error) {
+		if _, ok := settings.Parameters["reject"]; ok {
+			return nil, fmt.Errorf("rejected")
+		}
+		return func() { *applied = append(*applied, settings) }, nil
+	})
+	return applied, func() {
+		sectionsMu.Lock()
+		defer sectionsMu.Unlock()
+		delete(sections, testSectionName)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	applied, unregister := registerTestSection(t)
+	defer unregister()
+	defer RegisterFeatureGate(MaintenanceWindowsGate, true)
+
+	path, cleanup := writeConfigFile(t, `
+featureGates:
+  MaintenanceWindows: false
+webhooks:
+  test-validation:
+    mode: DryRun
+    timeoutSeconds: 5
+    protected:
+    - anyuid
+    - privileged
+    allowedUsers:
+    - system:serviceaccount:openshift-monitoring:cluster-monitoring-operator
+    allowedGroups:
+    - system:serviceaccounts:openshift-backplane-srep
+    parameters:
+      minTLSVersion: TLSv1.3
+`)
+	defer cleanup()
+
+	if err := LoadFile(path); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	expected := []WebhookSettings{
+		{
+			Mode:           ModeDryRun,
+			TimeoutSeconds: 5,
+			Protected:      []string{"anyuid", "privileged"},
+			AllowedUsers:   []string{"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator"},
+			AllowedGroups:  []string{"system:serviceaccounts:openshift-backplane-srep"},
+			Parameters:     map[string]string{"minTLSVersion": "TLSv1.3"},
+		},
+	}
+	if !reflect.DeepEqual(*applied, expected) {
+		t.Fatalf("Expected the section to be applied with %+v, got %+v", expected, *applied)
+	}
+	if FeatureGateEnabled(MaintenanceWindowsGate) {
+		t.Fatalf("Expected %s to be turned off", MaintenanceWindowsGate)
+	}
+}
+
+func TestLoadFileSchemaInvalid(t *testing.T) {
+	applied, unregister := registerTestSection(t)
+	defer unregister()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "unknown field",
+			content:  "webhooks:\n  test-validation:\n    allowedUser:\n    - user1\n",
+			expected: `unknown field "allowedUser"`,
+		},
+		{
+			name:     "wrong type",
+			content:  "webhooks:\n  test-validation:\n    timeoutSeconds: soon\n",
+			expected: "timeoutSeconds",
+		},
+		{
+			name:     "unknown mode",
+			content:  "webhooks:\n  test-validation:\n    mode: Audit\n",
+			expected: "webhooks.test-validation.mode",
+		},
+		{
+			name:     "timeout too long",
+			content:  "webhooks:\n  test-validation:\n    timeoutSeconds: 60\n",
+			expected: "webhooks.test-validation.timeoutSeconds",
+		},
+		{
+			name:     "empty list entry",
+			content:  "webhooks:\n  test-validation:\n    protected:\n    - \"\"\n",
+			expected: "webhooks.test-validation.protected[0]",
+		},
+		{
+			name:     "unconfigurable webhook",
+			content:  "webhooks:\n  unknown-validation:\n    mode: DryRun\n",
+			expected: `"unknown-validation" can't be configured`,
+		},
+		{
+			name:     "error rate above 1",
+			content:  "circuit:\n  errorRate: 2\n",
+			expected: "circuit.errorRate",
+		},
+		{
+			name:     "unknown safe mode",
+			content:  "circuit:\n  safeModes:\n    test-validation: Allow\n",
+			expected: "circuit.safeModes.test-validation",
+		},
+		{
+			name:     "section rejecting its settings",
+			content:  "webhooks:\n  test-validation:\n    parameters:\n      reject: \"true\"\n",
+			expected: "webhooks.test-validation: rejected",
+		},
+		{
+			name:     "unknown feature gate",
+			content:  "featureGates:\n  Unknown: true\n",
+			expected: `unknown feature gate "Unknown"`,
+		},
+	}
+
+	for _, test := range tests {
+		path, cleanup := writeConfigFile(t, test.content)
+		err := LoadFile(path)
+		cleanup()
+		if err == nil {
+			t.Fatalf("Expected an error loading the file with an %s", test.name)
+		}
+		if !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("Expected the error for the %s to mention %q, got %s", test.name, test.expected, err.Error())
+		}
+	}
+	if len(*applied) != 0 {
+		t.Fatalf("Expected an invalid file not to be applied, got %+v", *applied)
+	}
+}
+
+func TestLoadFileAtomic(t *testing.T) {
+	applied, unregister := registerTestSection(t)
+	defer unregister()
+	// Sorts after test-validation, so it is staged last
+	RegisterSection("zz-validation", func(settings WebhookSettings) (func(), error) {
+		return nil, fmt.Errorf("rejected")
+	})
+	defer func() {
+		sectionsMu.Lock()
+		defer sectionsMu.Unlock()
+		delete(sections, "zz-validation")
+	}()
+	defer SetCircuit(DefaultCircuitSettings)
+
+	path, cleanup := writeConfigFile(t, `
+featureGates:
+  MaintenanceWindows: false
+circuit:
+  minRequests: 1000
+webhooks:
+  test-validation:
+    timeoutSeconds: 5
+  zz-validation:
+    timeoutSeconds: 5
+`)
+	defer cleanup()
+
+	if err := LoadFile(path); err == nil {
+		t.Fatalf("Expected an error loading a file with a rejected section")
+	}
+	if len(*applied) != 0 {
+		t.Fatalf("Expected no section to be applied, got %+v", *applied)
+	}
+	if !FeatureGateEnabled(MaintenanceWindowsGate) {
+		t.Fatalf("Expected %s to be left on", MaintenanceWindowsGate)
+	}
+	if settings := Circuit(); settings.MinRequests != DefaultCircuitSettings.MinRequests {
+		t.Fatalf("Expected the circuit settings to be left as is, got %+v", settings)
+	}
+}
+
+func TestLoadFileResetsRemovedSettings(t *testing.T) {
+	timeoutSeconds := int32(2)
+	RegisterSection(testSectionName, func(settings WebhookSettings) (func(), error) {
+		return func() {
+			if settings.TimeoutSeconds != 0 {
+				timeoutSeconds = settings.TimeoutSeconds
+			}
+		}, nil
+	}, &timeoutSeconds)
+	defer func() {
+		sectionsMu.Lock()
+		defer sectionsMu.Unlock()
+		delete(sections, testSectionName)
+	}()
+	defer RegisterFeatureGate(MaintenanceWindowsGate, true)
+	defer SetCircuit(DefaultCircuitSettings)
+
+	path, cleanup := writeConfigFile(t, `
+featureGates:
+  MaintenanceWindows: false
+circuit:
+  minRequests: 1000
+webhooks:
+  test-validation:
+    timeoutSeconds: 5
+`)
+	defer cleanup()
+	if err := LoadFile(path); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if timeoutSeconds != 5 {
+		t.Fatalf("Expected the timeout of the file, got %d", timeoutSeconds)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("webhooks: {}\n"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if err := LoadFile(path); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if timeoutSeconds != 2 {
+		t.Fatalf("Expected the removed timeout to revert to its default, got %d", timeoutSeconds)
+	}
+	if !FeatureGateEnabled(MaintenanceWindowsGate) {
+		t.Fatalf("Expected the removed %s to revert to its default", MaintenanceWindowsGate)
+	}
+	if settings := Circuit(); settings.MinRequests != DefaultCircuitSettings.MinRequests {
+		t.Fatalf("Expected the removed circuit settings to revert to the defaults, got %+v", settings)
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	applied, unregister := registerTestSection(t)
+	defer unregister()
+	defer unregisterReloader("config-file")
+
+	path, cleanup := writeConfigFile(t, "webhooks:\n  test-validation:\n    timeoutSeconds: 5\n")
+	defer cleanup()
+	if err := WatchFile(path); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	if err := ioutil.WriteFile(path, []byte("webhooks:\n  test-validation:\n    timeoutSeconds: 7\n"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if failed := ReloadAll(); len(failed) != 0 {
+		t.Fatalf("Expected the reload to succeed, got %v", failed)
+	}
+	if len(*applied) != 2 || (*applied)[1].TimeoutSeconds != 7 {
+		t.Fatalf("Expected the reloaded file to be applied, got %+v", *applied)
+	}
+
+	// An invalid file leaves the previous configuration in effect
+	if err := ioutil.WriteFile(path, []byte("webhooks:\n  test-validation:\n    timeoutSeconds: 60\n"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if _, ok := ReloadAll()["config-file"]; !ok {
+		t.Fatalf("Expected the invalid file to fail reloading")
+	}
+	if len(*applied) != 2 {
+		t.Fatalf("Expected the invalid file not to be applied, got %+v", *applied)
+	}
+}
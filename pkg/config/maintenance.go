@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The keys of the maintenance window ConfigMap. The ConfigMap is mounted
+	// as a directory, so each key is a file.
+	maintenanceStartKey    string = "start"
+	maintenanceEndKey      string = "end"
+	maintenanceWebhooksKey string = "webhooks"
+
+	// MaintenanceWindowsGate is the feature gate turning maintenance windows
+	// on, which it is by default
+	MaintenanceWindowsGate string = "MaintenanceWindows"
+)
+
+var (
+	maintenanceMu     sync.RWMutex
+	maintenanceWindow *MaintenanceWindow
+)
+
+// MaintenanceWindow is a planned maintenance, during which the listed
+// webhooks relax some of their protections for the managed automation
+// identities
+type MaintenanceWindow struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Webhooks []string  `json:"webhooks"`
+}
+
+// Contains checks if the webhook is relaxed by the window at the given time.
+// The window includes its start and excludes its end.
+func (w *MaintenanceWindow) Contains(webhook string, now time.Time) bool {
+	if now.Before(w.Start) || !now.Before(w.End) {
+		return false
+	}
+	for _, name := range w.Webhooks {
+		if name == webhook {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	RegisterFeatureGate(MaintenanceWindowsGate, true)
+	RegisterPolicySource("maintenance-window", func() interface{} {
+		maintenanceMu.RLock()
+		defer maintenanceMu.RUnlock()
+		return maintenanceWindow
+	})
+}
+
+// InMaintenance checks if the webhook is within a configured maintenance
+// window at the given time. It never is when MaintenanceWindowsGate is off.
+func InMaintenance(webhook string, now time.Time) bool {
+	if !FeatureGateEnabled(MaintenanceWindowsGate) {
+		return false
+	}
+	maintenanceMu.RLock()
+	defer maintenanceMu.RUnlock()
+	return maintenanceWindow != nil && maintenanceWindow.Contains(webhook, now)
+}
+
+// SetMaintenanceWindow replaces the maintenance window in effect. A nil
+// window ends any maintenance.
+func SetMaintenanceWindow(window *MaintenanceWindow) {
+	maintenanceMu.Lock()
+	defer maintenanceMu.Unlock()
+	maintenanceWindow = window
+}
+
+// LoadMaintenanceWindow reads the maintenance window from the directory a
+// ConfigMap is mounted on. start and end are RFC 3339 timestamps, and
+// webhooks lists webhook names separated by commas or whitespace. A missing
+// directory means there is no maintenance planned.
+func LoadMaintenanceWindow(dir string) (*MaintenanceWindow, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	values := make(map[string]string, 3)
+	for _, key := range []string{maintenanceStartKey, maintenanceEndKey, maintenanceWebhooksKey} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, key))
+		if err != nil {
+			return nil, err
+		}
+		values[key] = strings.TrimSpace(string(b))
+	}
+
+	start, err := time.Parse(time.RFC3339, values[maintenanceStartKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window %s: %v", maintenanceStartKey, err)
+	}
+	end, err := time.Parse(time.RFC3339, values[maintenanceEndKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window %s: %v", maintenanceEndKey, err)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("maintenance window ends at %s, before it starts at %s", end, start)
+	}
+	webhooks := strings.FieldsFunc(values[maintenanceWebhooksKey], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(webhooks) == 0 {
+		return nil, fmt.Errorf("maintenance window lists no webhooks")
+	}
+
+	return &MaintenanceWindow{Start: start, End: end, Webhooks: webhooks}, nil
+}
+
+// WatchMaintenanceWindow loads the maintenance window from dir, and registers
+// a Reloader so it is re-read on SIGHUP. An invalid window is reported and
+// leaves the previous one in effect.
+func WatchMaintenanceWindow(dir string) error {
+	reload := func() error {
+		window, err := LoadMaintenanceWindow(dir)
+		if err != nil {
+			return err
+		}
+		SetMaintenanceWindow(window)
+		if window != nil {
+			log.Info("Maintenance window configured", "start", window.Start, "end", window.End, "webhooks", window.Webhooks)
+		}
+		return nil
+	}
+	RegisterReloader("maintenance-window", reload)
+	return reload()
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeMaintenanceConfigMap(t *testing.T, dir string, values map[string]string) {
+	for key, value := range values {
+		if err := ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0644); err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+	}
+}
+
+func TestLoadMaintenanceWindow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maintenance")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	writeMaintenanceConfigMap(t, dir, map[string]string{
+		"start":    "2021-06-01T02:00:00Z",
+		"end":      "2021-06-01T04:00:00Z\n",
+		"webhooks": "scc-validation, hpa-validation\n",
+	})
+	window, err := LoadMaintenanceWindow(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	tests := []struct {
+		webhook  string
+		now      string
+		expected bool
+	}{
+		{"scc-validation", "2021-06-01T01:59:59Z", false},
+		{"scc-validation", "2021-06-01T02:00:00Z", true},
+		{"hpa-validation", "2021-06-01T03:00:00Z", true},
+		{"route-validation", "2021-06-01T03:00:00Z", false},
+		{"scc-validation", "2021-06-01T04:00:00Z", false},
+	}
+	for _, test := range tests {
+		now, _ := time.Parse(time.RFC3339, test.now)
+		if got := window.Contains(test.webhook, now); got != test.expected {
+			t.Fatalf("Expected %s to be in maintenance at %s to be %t, got %t", test.webhook, test.now, test.expected, got)
+		}
+	}
+}
+
+func TestLoadMaintenanceWindowMissing(t *testing.T) {
+	window, err := LoadMaintenanceWindow(filepath.Join(os.TempDir(), "no-such-maintenance-window"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if window != nil {
+		t.Fatalf("Expected no maintenance window, got %v", window)
+	}
+}
+
+func TestLoadMaintenanceWindowInvalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unparseable-start": {"start": "yesterday", "end": "2021-06-01T04:00:00Z", "webhooks": "scc-validation"},
+		"end-before-start":  {"start": "2021-06-01T04:00:00Z", "end": "2021-06-01T02:00:00Z", "webhooks": "scc-validation"},
+		"no-webhooks":       {"start": "2021-06-01T02:00:00Z", "end": "2021-06-01T04:00:00Z", "webhooks": " "},
+		"missing-end":       {"start": "2021-06-01T02:00:00Z", "webhooks": "scc-validation"},
+	}
+	for name, values := range tests {
+		dir, err := ioutil.TempDir("", "maintenance")
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		defer os.RemoveAll(dir)
+		writeMaintenanceConfigMap(t, dir, values)
+
+		if _, err := LoadMaintenanceWindow(dir); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
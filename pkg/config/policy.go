@@ -0,0 +1,63 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+)
+
+var (
+	policySourcesMu sync.Mutex
+	policySources   = map[string]PolicySource{}
+)
+
+// PolicySource returns the effective policy of a webhook, eg its protected
+// lists, allowlists and modes. It must be JSON serializable.
+type PolicySource func() interface{}
+
+// RegisterPolicySource registers the PolicySource of a webhook under its name,
+// so its policy is part of PolicyVersion. Registering the same name twice
+// replaces the previous PolicySource.
+func RegisterPolicySource(name string, source PolicySource) {
+	policySourcesMu.Lock()
+	defer policySourcesMu.Unlock()
+	policySources[name] = source
+}
+
+// PolicyVersion identifies the policy bundle currently in effect. It is a
+// hash of every registered PolicySource, so it is stable across restarts and
+// changes whenever any effective policy does.
+func PolicyVersion() (string, error) {
+	policySourcesMu.Lock()
+	defer policySourcesMu.Unlock()
+
+	bundle := make(map[string]interface{}, len(policySources))
+	ReadSettings(func() {
+		for name, source := range policySources {
+			bundle[name] = source()
+		}
+	})
+	// Map keys are marshalled in sorted order, so the encoding is stable
+	b, err := json.Marshal(bundle)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RecordPolicyVersion exposes the current PolicyVersion through the
+// webhook_policy_version_info metric. The debug endpoint computes it afresh. It is called on every reload, and should
+// be called once on startup.
+func RecordPolicyVersion() {
+	version, err := PolicyVersion()
+	if err != nil {
+		log.Error(err, "Couldn't compute the policy version")
+		return
+	}
+	metrics.SetPolicyVersion(version)
+	log.Info("Policy version", "version", version)
+}
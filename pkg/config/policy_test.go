@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPolicyVersion(t *testing.T) {
+	protected := []string{"privileged", "restricted"}
+	RegisterPolicySource("test-policy", func() interface{} { return map[string]interface{}{"protected": protected} })
+	defer func() {
+		policySourcesMu.Lock()
+		delete(policySources, "test-policy")
+		policySourcesMu.Unlock()
+	}()
+
+	before, err := PolicyVersion()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	again, err := PolicyVersion()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if before != again {
+		t.Fatalf("Expected a stable policy version, got %s and %s", before, again)
+	}
+
+	protected = append(protected, "anyuid")
+	after, err := PolicyVersion()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if after == before {
+		t.Fatalf("Expected the policy version to change with the configuration, stayed %s", before)
+	}
+
+	RecordPolicyVersion()
+	if v := promtestutil.ToFloat64(metrics.PolicyVersion.WithLabelValues(after)); v != 1 {
+		t.Fatalf("Expected webhook_policy_version_info{version=%q} to be 1, got %v", after, v)
+	}
+	if n := promtestutil.CollectAndCount(metrics.PolicyVersion); n != 1 {
+		t.Fatalf("Expected a single webhook_policy_version_info series, got %d", n)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	log = logf.Log.WithName("config")
+
+	reloadersMu sync.Mutex
+	reloaders   = map[string]Reloader{}
+)
+
+// Reloader re-reads a piece of file, ConfigMap or Secret-backed
+// configuration. Implementations are responsible for swapping in the new
+// configuration safely, so that in-flight requests keep using a consistent
+// view.
+type Reloader func() error
+
+// RegisterReloader registers a Reloader under the given name, to be called by
+// ReloadAll. Registering the same name twice replaces the previous Reloader.
+func RegisterReloader(name string, reloader Reloader) {
+	reloadersMu.Lock()
+	defer reloadersMu.Unlock()
+	reloaders[name] = reloader
+}
+
+// ReloadAll calls every registered Reloader in name order and logs the
+// result, then records the resulting policy version. A failing Reloader does
+// not prevent the others from running. The returned map holds the error of
+// every Reloader which failed. The webhooks are degraded while it runs, as
+// the configuration may be partially reloaded.
+func ReloadAll() map[string]error {
+	reloadersMu.Lock()
+	defer reloadersMu.Unlock()
+	SetDegraded(ReloadInProgress)
+	defer ClearDegraded(ReloadInProgress)
+
+	names := make([]string, 0, len(reloaders))
+	for name := range reloaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failed := make(map[string]error)
+	for _, name := range names {
+		if err := reloaders[name](); err != nil {
+			log.Error(err, "Couldn't reload configuration", "reloader", name)
+			failed[name] = err
+			continue
+		}
+		log.Info("Reloaded configuration", "reloader", name)
+	}
+	RecordPolicyVersion()
+	return failed
+}
+
+// WatchReloadSignal calls ReloadAll every time the process receives a SIGHUP,
+// until stop is closed. Reloading happens in its own goroutine so the HTTP
+// server keeps serving requests meanwhile.
+func WatchReloadSignal(stop <-chan struct{}) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-sigs:
+				log.Info("Received SIGHUP, reloading configuration")
+				ReloadAll()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fileBackedConfig is a minimal file-backed configuration for testing reloads
+type fileBackedConfig struct {
+	mu    sync.Mutex
+	path  string
+	value string
+}
+
+func (c *fileBackedConfig) reload() error {
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = string(b)
+	return nil
+}
+
+func (c *fileBackedConfig) get() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// unregisterReloader removes the named Reloader, so that it doesn't run in
+// later tests
+func unregisterReloader(name string) {
+	reloadersMu.Lock()
+	defer reloadersMu.Unlock()
+	delete(reloaders, name)
+}
+
+// registerTestReloader registers a Reloader for the duration of the test
+func registerTestReloader(t *testing.T, name string, reloader Reloader) {
+	RegisterReloader(name, reloader)
+	t.Cleanup(func() { unregisterReloader(name) })
+}
+
+func TestReloadOnSIGHUP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &fileBackedConfig{path: filepath.Join(dir, "allowlist")}
+	if err := ioutil.WriteFile(cfg.path, []byte("before"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if err := cfg.reload(); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	registerTestReloader(t, "test-allowlist", cfg.reload)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	WatchReloadSignal(stop)
+
+	if err := ioutil.WriteFile(cfg.path, []byte("after"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cfg.get() != "after" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected configuration to be reloaded to %q, got %q", "after", cfg.get())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloadAllReportsFailures(t *testing.T) {
+	registerTestReloader(t, "test-broken", func() error { return fmt.Errorf("broken") })
+	ran := false
+	registerTestReloader(t, "test-working", func() error { ran = true; return nil })
+
+	failed := ReloadAll()
+	if _, ok := failed["test-broken"]; !ok {
+		t.Fatalf("Expected test-broken reloader to be reported as failed, got %v", failed)
+	}
+	if _, ok := failed["test-working"]; ok {
+		t.Fatalf("Expected test-working reloader to succeed, got %v", failed)
+	}
+	if !ran {
+		t.Fatalf("Expected test-working reloader to run despite another failure")
+	}
+}
+
+func TestDegradedDuringReload(t *testing.T) {
+	var during []string
+	registerTestReloader(t, "degraded-test", func() error {
+		during = Degraded()
+		return nil
+	})
+
+	ReloadAll()
+
+	if len(during) != 1 || during[0] != ReloadInProgress {
+		t.Fatalf("Expected to be degraded with %q during the reload, got %v", ReloadInProgress, during)
+	}
+	if after := Degraded(); len(after) != 0 {
+		t.Fatalf("Expected not to be degraded after the reload, got %v", after)
+	}
+}
@@ -0,0 +1,104 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/rolebinding"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+// webhookNameSuffix is appended to the name of each webhook in the generated
+// ValidatingWebhookConfigurations
+const webhookNameSuffix string = ".managed.openshift.io"
+
+// Client is the subset of client.Client the checks use
+type Client interface {
+	Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
+	Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error
+}
+
+// Check is a known-protected operation, which the named webhook must deny
+type Check struct {
+	// Webhook is the name of the webhook expected to deny the operation
+	Webhook string
+	// Description is a human readable summary of the operation
+	Description string
+	// Attempt tries the operation, with a dry-run where the API allows it
+	Attempt func(ctx context.Context, c Client) error
+}
+
+// Result is the outcome of a Check
+type Result struct {
+	Webhook     string
+	Description string
+	Passed      bool
+	// Err is the error returned by the attempt, if any
+	Err error
+}
+
+// Checks are the known-protected operations run by Run
+var Checks = []Check{
+	{
+		Webhook:     scc.WebhookName,
+		Description: "delete the privileged SCC",
+		Attempt: func(ctx context.Context, c Client) error {
+			obj := &securityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: "privileged"},
+			}
+			return c.Delete(ctx, obj, client.DryRunAll)
+		},
+	},
+	{
+		Webhook:     rolebinding.WebhookName,
+		Description: "bind cluster-admin to system:authenticated",
+		Attempt: func(ctx context.Context, c Client) error {
+			obj := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "conformance-cluster-admin-authenticated"},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "ClusterRole",
+					Name:     "cluster-admin",
+				},
+				Subjects: []rbacv1.Subject{
+					{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "system:authenticated"},
+				},
+			}
+			return c.Create(ctx, obj, client.DryRunAll)
+		},
+	},
+}
+
+// Run attempts every Check against the cluster c points to. A Check passes
+// only when its webhook denied the operation: a RBAC denial or a success both
+// mean the webhook didn't protect the cluster. The client should act as a
+// non-allowlisted identity, eg through impersonation.
+func Run(ctx context.Context, c Client) []Result {
+	results := make([]Result, 0, len(Checks))
+	for _, check := range Checks {
+		err := check.Attempt(ctx, c)
+		results = append(results, Result{
+			Webhook:     check.Webhook,
+			Description: check.Description,
+			Passed:      deniedByWebhook(err, check.Webhook),
+			Err:         err,
+		})
+	}
+	return results
+}
+
+// deniedByWebhook checks if err is the API server relaying a denial from the
+// named webhook
+func deniedByWebhook(err error, webhookName string) bool {
+	if err == nil || !apierrors.IsForbidden(err) {
+		return false
+	}
+	return strings.Contains(err.Error(), fmt.Sprintf("admission webhook %q denied the request", webhookName+webhookNameSuffix))
+}
@@ -0,0 +1,89 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/rolebinding"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+// fakeClient returns canned errors and records the dry-run option
+type fakeClient struct {
+	createErr error
+	deleteErr error
+	dryRun    bool
+}
+
+func (f *fakeClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	o := &client.CreateOptions{}
+	o.ApplyOptions(opts)
+	f.dryRun = len(o.DryRun) > 0
+	return f.createErr
+}
+
+func (f *fakeClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	o := &client.DeleteOptions{}
+	o.ApplyOptions(opts)
+	f.dryRun = len(o.DryRun) > 0
+	return f.deleteErr
+}
+
+func webhookDenial(webhookName string) error {
+	return apierrors.NewForbidden(schema.GroupResource{}, "test", fmt.Errorf("admission webhook %q denied the request: not allowed", webhookName+webhookNameSuffix))
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		testID    string
+		createErr error
+		deleteErr error
+		expected  map[string]bool
+	}{
+		{
+			testID:    "all-denied-by-webhooks",
+			deleteErr: webhookDenial(scc.WebhookName),
+			createErr: webhookDenial(rolebinding.WebhookName),
+			expected:  map[string]bool{scc.WebhookName: true, rolebinding.WebhookName: true},
+		},
+		{
+			testID:    "operations-succeed",
+			deleteErr: nil,
+			createErr: nil,
+			expected:  map[string]bool{scc.WebhookName: false, rolebinding.WebhookName: false},
+		},
+		{
+			testID:    "denied-by-rbac",
+			deleteErr: apierrors.NewForbidden(schema.GroupResource{}, "test", fmt.Errorf("User \"user1\" cannot delete resource")),
+			createErr: webhookDenial(rolebinding.WebhookName),
+			expected:  map[string]bool{scc.WebhookName: false, rolebinding.WebhookName: true},
+		},
+		{
+			testID:    "denied-by-another-webhook",
+			deleteErr: webhookDenial("some-other-validation"),
+			createErr: webhookDenial(scc.WebhookName),
+			expected:  map[string]bool{scc.WebhookName: false, rolebinding.WebhookName: false},
+		},
+	}
+
+	for _, test := range tests {
+		c := &fakeClient{createErr: test.createErr, deleteErr: test.deleteErr}
+		results := Run(context.TODO(), c)
+		if len(results) != len(Checks) {
+			t.Fatalf("%s: expected %d results, got %d", test.testID, len(Checks), len(results))
+		}
+		for _, result := range results {
+			if result.Passed != test.expected[result.Webhook] {
+				t.Fatalf("%s: expected %s check to pass=%t, got %t (error: %v)", test.testID, result.Webhook, test.expected[result.Webhook], result.Passed, result.Err)
+			}
+		}
+		if !c.dryRun {
+			t.Fatalf("%s: expected the attempts to be dry-runs", test.testID)
+		}
+	}
+}
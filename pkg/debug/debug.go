@@ -0,0 +1,59 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+)
+
+// URI is the path the debug information is served on
+const URI string = "/debug"
+
+var log = logf.Log.WithName("debug")
+
+// Info is the debug information, served as JSON
+type Info struct {
+	// PolicyVersion identifies the policy bundle in effect, see
+	// config.PolicyVersion
+	PolicyVersion string `json:"policyVersion"`
+	// RecentDenials holds the last denials, oldest first, by the name of the
+	// webhook keeping them
+	RecentDenials map[string][]utils.Denial `json:"recentDenials"`
+}
+
+// collect gathers the debug information
+func collect() (*Info, error) {
+	version, err := config.PolicyVersion()
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		PolicyVersion: version,
+		RecentDenials: map[string][]utils.Denial{
+			scc.WebhookName: scc.RecentDenials(),
+		},
+	}, nil
+}
+
+// Handler serves the debug information, for support to tell which policy a
+// cluster runs and what it recently denied without access to its metrics or
+// logs
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info, err := collect()
+		if err != nil {
+			log.Error(err, "Couldn't collect the debug information")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(info); err != nil {
+			log.Error(err, "Couldn't write the debug information")
+		}
+	}
+}
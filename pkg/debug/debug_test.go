@@ -0,0 +1,78 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+func getInfo(t *testing.T) Info {
+	recorder := httptest.NewRecorder()
+	Handler()(recorder, httptest.NewRequest("GET", URI, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the debug endpoint to report %d, got %d", http.StatusOK, recorder.Code)
+	}
+	info := Info{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	return info
+}
+
+func TestPolicyVersion(t *testing.T) {
+	before := getInfo(t)
+	expected, err := config.PolicyVersion()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if before.PolicyVersion != expected {
+		t.Fatalf("Expected policy version %s, got %s", expected, before.PolicyVersion)
+	}
+
+	config.SetCircuit(config.CircuitSettings{MinRequests: 1000})
+	defer config.SetCircuit(config.DefaultCircuitSettings)
+	if after := getInfo(t); after.PolicyVersion == before.PolicyVersion {
+		t.Fatalf("Expected the policy version to change with the configuration, stayed %s", before.PolicyVersion)
+	}
+}
+
+func TestRecentDenials(t *testing.T) {
+	obj := runtime.RawExtension{
+		Raw: []byte(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "privileged"}}`),
+	}
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "debug-test",
+			Kind:      metav1.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"},
+			Resource:  metav1.GroupVersionResource{Group: "security.openshift.io", Version: "v1", Resource: "securitycontextconstraints"},
+			Name:      "privileged",
+			Operation: admissionv1.Delete,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "debug-test-user",
+				Groups:   []string{"system:authenticated"},
+			},
+			OldObject: obj,
+		},
+	}
+	if response := scc.NewWebhook().Authorized(request); response.Allowed {
+		t.Fatalf("Expected the deletion of a default SCC to be denied")
+	}
+
+	denials := getInfo(t).RecentDenials[scc.WebhookName]
+	if len(denials) == 0 {
+		t.Fatalf("Expected the denial to be served")
+	}
+	if last := denials[len(denials)-1]; last.User != "debug-test-user" || last.Resource != "DELETE privileged" {
+		t.Fatalf("Expected the last denial to be the deletion of privileged by debug-test-user, got %+v", last)
+	}
+}
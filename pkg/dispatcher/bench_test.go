@@ -0,0 +1,144 @@
+package dispatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/rolebinding"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+const (
+	benchSCCRaw string = `{
+	"apiVersion": "security.openshift.io/v1",
+	"kind": "SecurityContextConstraints",
+	"metadata": {"name": "privileged", "uid": "1234"},
+	"allowPrivilegedContainer": true,
+	"allowHostNetwork": true,
+	"runAsUser": {"type": "RunAsAny"},
+	"seLinuxContext": {"type": "RunAsAny"},
+	"users": ["system:admin", "system:serviceaccount:openshift-infra:build-controller"],
+	"groups": ["system:cluster-admins", "system:nodes", "system:masters"],
+	"volumes": ["*"]
+}`
+	benchCRBRaw string = `{
+	"apiVersion": "rbac.authorization.k8s.io/v1",
+	"kind": "ClusterRoleBinding",
+	"metadata": {"name": "customer-admins", "uid": "1234"},
+	"roleRef": {"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "cluster-admin"},
+	"subjects": [
+		{"kind": "User", "name": "user1"},
+		{"kind": "Group", "name": "customer-admins"}
+	]
+}`
+)
+
+// admissionPathBenchmarks are representative requests, going through the
+// whole admission path of the dispatcher: decoding the AdmissionReview,
+// Validate, Authorized with the real decoders, and encoding the response
+var admissionPathBenchmarks = []struct {
+	name            string
+	webhook         string
+	uri             string
+	gvk             metav1.GroupVersionKind
+	gvr             metav1.GroupVersionResource
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	raw             string
+	shouldBeAllowed bool
+}{
+	{
+		name:            "SCCUpdateDenied",
+		webhook:         scc.WebhookName,
+		uri:             "/" + scc.WebhookName,
+		gvk:             metav1.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"},
+		gvr:             metav1.GroupVersionResource{Group: "security.openshift.io", Version: "v1", Resource: "securitycontextconstraints"},
+		operation:       admissionv1.Update,
+		username:        "user1",
+		userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+		raw:             benchSCCRaw,
+		shouldBeAllowed: false,
+	},
+	{
+		name:            "SCCDeleteAllowed",
+		webhook:         scc.WebhookName,
+		uri:             "/" + scc.WebhookName,
+		gvk:             metav1.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"},
+		gvr:             metav1.GroupVersionResource{Group: "security.openshift.io", Version: "v1", Resource: "securitycontextconstraints"},
+		operation:       admissionv1.Delete,
+		username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+		raw:             benchSCCRaw,
+		shouldBeAllowed: true,
+	},
+	{
+		name:            "CRBUpdateDenied",
+		webhook:         rolebinding.WebhookName,
+		uri:             "/" + rolebinding.WebhookName,
+		gvk:             metav1.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+		gvr:             metav1.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+		operation:       admissionv1.Update,
+		username:        "user1",
+		userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+		raw:             benchCRBRaw,
+		shouldBeAllowed: false,
+	},
+}
+
+func BenchmarkAdmissionPath(b *testing.B) {
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		scc.WebhookName:         func() webhooks.Webhook { return scc.NewWebhook() },
+		rolebinding.WebhookName: func() webhooks.Webhook { return rolebinding.NewWebhook() },
+	})
+
+	for _, bench := range admissionPathBenchmarks {
+		bench := bench
+		obj := runtime.RawExtension{Raw: []byte(bench.raw)}
+		body, err := testutils.CreateFakeRequestJSON(bench.name, bench.gvk, bench.gvr, bench.operation, bench.username, bench.userGroups, &obj, &obj)
+		if err != nil {
+			b.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		b.Run(bench.name, func(b *testing.B) {
+			// Check the request takes the intended path before timing it
+			if allowed := serveBenchRequest(b, d, bench.uri, body); allowed != bench.shouldBeAllowed {
+				b.Fatalf("Expected %s to allow the request: %t, got %t", bench.webhook, bench.shouldBeAllowed, allowed)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				httprequest := httptest.NewRequest("POST", bench.uri, bytes.NewReader(body))
+				httprequest.Header.Set("Content-Type", "application/json")
+				d.HandleRequest(httptest.NewRecorder(), httprequest)
+			}
+		})
+	}
+}
+
+// serveBenchRequest sends the body through the dispatcher once, and returns
+// whether the request was allowed
+func serveBenchRequest(b *testing.B, d *Dispatcher, uri string, body []byte) bool {
+	httprequest := httptest.NewRequest("POST", uri, bytes.NewReader(body))
+	httprequest.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	d.HandleRequest(recorder, httprequest)
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), review); err != nil {
+		b.Fatalf("Expected an AdmissionReview response, got %q: %s", recorder.Body.String(), err.Error())
+	}
+	if review.Response == nil {
+		b.Fatalf("Expected a response in the AdmissionReview, got none")
+	}
+	return review.Response.Allowed
+}
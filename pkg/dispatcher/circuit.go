@@ -0,0 +1,119 @@
+package dispatcher
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+)
+
+// circuit disables the webhooks which error on too many requests, eg after
+// an API upgrade their decoding can't handle, so that they answer in their
+// safe mode rather than keep erroring. See config.CircuitSettings. It is safe
+// for concurrent use.
+type circuit struct {
+	mu       sync.Mutex
+	settings func() config.CircuitSettings
+	clock    utils.Clock
+	states   map[string]*circuitState
+}
+
+// circuitState is the circuit of a single webhook
+type circuitState struct {
+	// windowStart is when the window requests and errors are counted over
+	// began
+	windowStart time.Time
+	requests    int
+	errors      int
+	// openUntil is when the open circuit closes again, zero when closed
+	openUntil time.Time
+}
+
+func newCircuit(settings func() config.CircuitSettings, clock utils.Clock) *circuit {
+	return &circuit{
+		settings: settings,
+		clock:    clock,
+		states:   map[string]*circuitState{},
+	}
+}
+
+// safeMode checks if the circuit of the webhook is open, and returns the safe
+// mode the webhook answers in. A circuit past its cooldown closes, so that the
+// webhook is tried again.
+func (c *circuit) safeMode(webhook string) (config.SafeMode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, ok := c.states[webhook]
+	if !ok || state.openUntil.IsZero() {
+		return "", false
+	}
+	now := c.clock.Now()
+	if now.Before(state.openUntil) {
+		return c.settings().SafeModeOf(webhook), true
+	}
+	*state = circuitState{windowStart: now}
+	log.Info("Closing the circuit, trying the webhook again", "webhookName", webhook)
+	metrics.CloseCircuit(webhook)
+	return "", false
+}
+
+// record counts a request the webhook decided on, and opens its circuit when
+// too many of the requests of the window errored
+func (c *circuit) record(webhook string, errored bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	settings := c.settings()
+	now := c.clock.Now()
+	state, ok := c.states[webhook]
+	if !ok {
+		state = &circuitState{windowStart: now}
+		c.states[webhook] = state
+	}
+	if !state.openUntil.IsZero() {
+		return
+	}
+	if now.Sub(state.windowStart) >= settings.Window() {
+		*state = circuitState{windowStart: now}
+	}
+
+	state.requests++
+	if errored {
+		state.errors++
+	}
+	if state.errors > 0 && state.requests >= settings.MinRequests && float64(state.errors) >= settings.ErrorRate*float64(state.requests) {
+		state.openUntil = now.Add(settings.Cooldown())
+		log.Error(fmt.Errorf("%d of %d requests errored", state.errors, state.requests),
+			"Opening the circuit of the webhook, which answers in its safe mode until the cooldown ends",
+			"webhookName", webhook, "safeMode", settings.SafeModeOf(webhook), "until", state.openUntil)
+		metrics.OpenCircuit(webhook)
+	}
+}
+
+// errored checks if the response reports an error, rather than a decision
+func errored(ret admissionctl.Response) bool {
+	if ret.Allowed || ret.Result == nil {
+		return false
+	}
+	return ret.Result.Code >= http.StatusBadRequest && ret.Result.Code != http.StatusForbidden
+}
+
+// safeModeResponse answers the request in the safe mode of the webhook
+func safeModeResponse(hook webhooks.Webhook, request admissionctl.Request, mode config.SafeMode) admissionctl.Response {
+	message := fmt.Sprintf("The %s webhook is disabled after erroring on too many requests", hook.Name())
+	var ret admissionctl.Response
+	if mode == config.SafeModeReportOnly {
+		ret = admissionctl.Allowed(message)
+		ret.Warnings = []string{message}
+	} else {
+		ret = admissionctl.Denied(message)
+	}
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
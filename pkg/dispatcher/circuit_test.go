@@ -0,0 +1,59 @@
+package dispatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+)
+
+func TestCircuit(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1600000000, 0))
+	settings := config.CircuitSettings{
+		ErrorRate:       0.5,
+		MinRequests:     4,
+		WindowSeconds:   60,
+		CooldownSeconds: 300,
+		SafeMode:        config.SafeModeDeny,
+	}
+	c := newCircuit(func() config.CircuitSettings { return settings }, clock)
+
+	// Errors below the rate, or below the minimum of requests, don't open it
+	for _, isError := range []bool{false, false, true, false, true} {
+		c.record("scc-validation", isError)
+	}
+	if _, open := c.safeMode("scc-validation"); open {
+		t.Fatalf("Expected the circuit to stay closed at 2 errors out of 5 requests")
+	}
+
+	// Counting starts over with each window
+	clock.Step(time.Minute)
+	for _, isError := range []bool{true, true, true} {
+		c.record("scc-validation", isError)
+	}
+	if _, open := c.safeMode("scc-validation"); open {
+		t.Fatalf("Expected the circuit to stay closed below %d requests", settings.MinRequests)
+	}
+	c.record("scc-validation", false)
+	mode, open := c.safeMode("scc-validation")
+	if !open || mode != config.SafeModeDeny {
+		t.Fatalf("Expected the circuit to open in %s mode at 3 errors out of 4 requests, got %v %s", config.SafeModeDeny, open, mode)
+	}
+	if _, open := c.safeMode("rolebinding-validation"); open {
+		t.Fatalf("Expected the circuits of other webhooks to stay closed")
+	}
+
+	clock.Step(299 * time.Second)
+	if _, open := c.safeMode("scc-validation"); !open {
+		t.Fatalf("Expected the circuit to stay open during the cooldown")
+	}
+	clock.Step(time.Second)
+	if _, open := c.safeMode("scc-validation"); open {
+		t.Fatalf("Expected the circuit to close after the cooldown")
+	}
+	c.record("scc-validation", true)
+	if _, open := c.safeMode("scc-validation"); open {
+		t.Fatalf("Expected the counts to start over once the circuit closed")
+	}
+}
@@ -0,0 +1,98 @@
+package dispatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// CorpusDecision is the decision on one AdmissionReview of a corpus. It
+// only carries what stays the same from one run to the next, so that the
+// decisions of two releases can be diffed.
+type CorpusDecision struct {
+	// Fixture is the path of the AdmissionReview, relative to the corpus
+	Fixture string `json:"fixture"`
+	Allowed bool   `json:"allowed"`
+	Code    int32  `json:"code"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// RunCorpus sends each AdmissionReview of the corpus through the dispatcher,
+// the way the API server would. The fixtures are the .json files under dir,
+// each sent to the path of the directory holding it, eg
+// scc-validation/delete-anyuid.json is sent to /scc-validation. Decisions
+// come in the order of the fixture paths.
+func (d *Dispatcher) RunCorpus(dir string) ([]CorpusDecision, error) {
+	var decisions []CorpusDecision
+	// Walk visits the files in lexical order, so the decisions are sorted
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(file) != ".json" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		review, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fixture := filepath.ToSlash(rel)
+		decision, err := d.decide("/"+path.Dir(fixture), review)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fixture, err)
+		}
+		decision.Fixture = fixture
+		decisions = append(decisions, decision)
+		return nil
+	})
+	return decisions, err
+}
+
+// decide sends the AdmissionReview to the handler of uri
+func (d *Dispatcher) decide(uri string, review []byte) (CorpusDecision, error) {
+	request := httptest.NewRequest(http.MethodPost, uri, bytes.NewReader(review))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	d.HandleRequest(recorder, request)
+
+	response := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		return CorpusDecision{}, err
+	}
+	if response.Response == nil {
+		return CorpusDecision{}, fmt.Errorf("no response in the AdmissionReview")
+	}
+	decision := CorpusDecision{Allowed: response.Response.Allowed}
+	if result := response.Response.Result; result != nil {
+		decision.Code = result.Code
+		decision.Reason = string(result.Reason)
+		decision.Message = result.Message
+	}
+	return decision, nil
+}
+
+// WriteDecisions writes the decisions one JSON object per line, which keeps
+// the diff of two runs down to the decisions which changed
+func WriteDecisions(w io.Writer, decisions []CorpusDecision) error {
+	encoder := json.NewEncoder(w)
+	for _, decision := range decisions {
+		if err := encoder.Encode(decision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package dispatcher
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+)
+
+func TestCorpusDecisions(t *testing.T) {
+	d := NewDispatcher(webhooks.Webhooks)
+	decisions, err := d.RunCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	var got bytes.Buffer
+	if err := WriteDecisions(&got, decisions); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	golden, err := ioutil.ReadFile("testdata/decisions.jsonl")
+	if err != nil {
+		t.Fatalf("Couldn't read the golden decisions: %s", err.Error())
+	}
+	if !bytes.Equal(got.Bytes(), golden) {
+		t.Fatalf("The decisions on the corpus don't match testdata/decisions.jsonl, got:\n%s", got.String())
+	}
+}
+
+func TestCorpusIsRoutedByDirectory(t *testing.T) {
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		allowingWebhookName: func() webhooks.Webhook { return &allowingWebhook{} },
+	})
+	decisions, err := d.RunCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	// None of the corpus is for the allowing webhook, so every fixture hits
+	// an unregistered path
+	for _, decision := range decisions {
+		if decision.Allowed {
+			t.Fatalf("Expected %s not to reach a webhook, got an allowed decision", decision.Fixture)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package dispatcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+)
+
+// DefaultDedupTTL is how long the UID of a request is remembered. The API
+// server retries a webhook call with the same UID within its timeout, so
+// this comfortably covers retries.
+const DefaultDedupTTL time.Duration = time.Minute
+
+// uidCache remembers the requests whose side effects, eg the decision export,
+// were already emitted, so that a retried AdmissionReview doesn't emit them
+// twice. Only side effects are deduplicated: a retried request is decided
+// again, the same way. It is safe for concurrent use.
+type uidCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	clock utils.Clock
+	// seen maps each remembered key to when it expires
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func newUIDCache(ttl time.Duration, clock utils.Clock) *uidCache {
+	return &uidCache{
+		ttl:   ttl,
+		clock: clock,
+		seen:  map[string]time.Time{},
+	}
+}
+
+// firstSeen remembers the UID of the request to the webhook, and checks if it
+// wasn't already remembered. Expired UIDs are pruned at most once per ttl, so
+// the cache stays bounded by the request rate.
+func (c *uidCache) firstSeen(webhook, uid string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	if now.Sub(c.lastPrune) >= c.ttl {
+		for key, expiry := range c.seen {
+			if !now.Before(expiry) {
+				delete(c.seen, key)
+			}
+		}
+		c.lastPrune = now
+	}
+
+	key := webhook + "/" + uid
+	if expiry, ok := c.seen[key]; ok && now.Before(expiry) {
+		return false
+	}
+	c.seen[key] = now.Add(c.ttl)
+	return true
+}
@@ -0,0 +1,31 @@
+package dispatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+)
+
+func TestUIDCache(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1600000000, 0))
+	cache := newUIDCache(time.Minute, clock)
+
+	if !cache.firstSeen("scc-validation", "1") {
+		t.Fatalf("Expected UID 1 to be seen for the first time")
+	}
+	if cache.firstSeen("scc-validation", "1") {
+		t.Fatalf("Expected UID 1 to be remembered")
+	}
+	if !cache.firstSeen("rolebinding-validation", "1") {
+		t.Fatalf("Expected UID 1 to be remembered per webhook")
+	}
+
+	clock.Step(time.Minute)
+	if !cache.firstSeen("scc-validation", "1") {
+		t.Fatalf("Expected UID 1 to be forgotten once expired")
+	}
+	if len(cache.seen) != 1 {
+		t.Fatalf("Expected expired UIDs to be pruned, %d remain", len(cache.seen))
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"time"
 
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/audit"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
 	responsehelper "github.com/openshift/managed-cluster-validating-webhooks/pkg/helpers"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
 )
@@ -18,8 +25,15 @@ var log = logf.Log.WithName("dispatcher")
 
 // Dispatcher struct
 type Dispatcher struct {
-	hooks *map[string]webhooks.WebhookFactory // uri -> hookfactory
-	mu    sync.Mutex
+	hooks    *map[string]webhooks.WebhookFactory // uri -> hookfactory
+	mu       sync.Mutex
+	exporter *audit.Exporter
+	// clock tells the time decisions are made at, and the caches and the
+	// circuit count their durations with
+	clock utils.Clock
+	// exported remembers the requests whose decision was exported
+	exported *uidCache
+	circuit  *circuit
 }
 
 // NewDispatcher new dispatcher
@@ -28,11 +42,22 @@ func NewDispatcher(hooks webhooks.RegisteredWebhooks) *Dispatcher {
 	for _, hook := range hooks {
 		hookMap[hook().GetURI()] = hook
 	}
+	clock := utils.RealClock{}
 	return &Dispatcher{
-		hooks: &hookMap,
+		hooks:    &hookMap,
+		clock:    clock,
+		exported: newUIDCache(DefaultDedupTTL, clock),
+		circuit:  newCircuit(config.Circuit, clock),
 	}
 }
 
+// ExportDecisions sends every decision to the exporter from now on
+func (d *Dispatcher) ExportDecisions(exporter *audit.Exporter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.exporter = exporter
+}
+
 // HandleRequest http request
 // HTTP status code usage: When the request body is correctly parsed into a
 // request (utils.ParseHTTPRequest) then we should always send 200 OK and use
@@ -64,16 +89,47 @@ func (d *Dispatcher) HandleRequest(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		// Valid AdmissionReview, but we can't do anything with it because we do not
-		// think the request inside is valid.
-		if !hook().Validate(request) {
+		// think the request inside is valid. Building the hook reads its
+		// settings too.
+		var realHook webhooks.Webhook
+		var valid bool
+		config.ReadSettings(func() {
+			realHook = hook()
+			valid = realHook.Validate(request)
+		})
+		if !valid {
 			responsehelper.SendResponse(w,
 				admissionctl.Errored(http.StatusBadRequest,
 					fmt.Errorf("Not a valid webhook request")))
 			return
 		}
 
-		// Dispatch
-		responsehelper.SendResponse(w, hook().Authorized(request))
+		// Dispatch, unless the webhooks can't decide on requests right now,
+		// or this one was disabled for erroring. The Degraded check is only
+		// advisory: a reload may start right after it, and it's
+		// config.ReadSettings which keeps the request from seeing half of it.
+		var ret admissionctl.Response
+		reasons := config.Degraded()
+		if len(reasons) > 0 {
+			log.Info("Webhooks are degraded, asking for the request to be retried", "webhookName", realHook.Name(), "uid", request.AdmissionRequest.UID, "reasons", reasons)
+			ret = transientError(request, reasons)
+		} else if mode, open := d.circuit.safeMode(realHook.Name()); open {
+			ret = safeModeResponse(realHook, request, mode)
+		} else {
+			ret = authorizeWithDeadline(realHook, request)
+			d.circuit.record(realHook.Name(), errored(ret))
+		}
+		responsehelper.SendResponse(w, ret)
+		if d.exporter != nil {
+			// A retried request is decided again, but its decision is only
+			// exported once. Transient errors aren't remembered, so that the
+			// retry which gets decided is exported.
+			if len(reasons) > 0 || d.exported.firstSeen(realHook.Name(), string(request.AdmissionRequest.UID)) {
+				d.exporter.Export(decision(realHook, request, ret, d.clock.Now()))
+			} else {
+				log.V(2).Info("Not exporting the decision on a retried request again", "webhookName", realHook.Name(), "uid", request.AdmissionRequest.UID)
+			}
+		}
 		return
 	}
 	log.Info("Request is not for a registered webhook.", "known_hooks", *d.hooks, "parsed_url", url, "lookup", (*d.hooks)[url.Path])
@@ -85,3 +141,82 @@ func (d *Dispatcher) HandleRequest(w http.ResponseWriter, r *http.Request) {
 		admissionctl.Errored(http.StatusBadRequest,
 			fmt.Errorf("Request is not for a registered webhook")))
 }
+
+// transientError answers the request with a retriable error while the
+// webhooks are degraded, eg during a configuration reload
+func transientError(request admissionctl.Request, reasons []string) admissionctl.Response {
+	ret := utils.TransientError(fmt.Sprintf("The webhook is temporarily unable to decide on the request (%s), please retry", strings.Join(reasons, ", ")))
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// authorizeWithDeadline runs the hook's Authorized within its internal
+// deadline. Past it, or when Authorized panics, the request gets the decision
+// the FailurePolicy of the hook would make of a failed call, unless the hook
+// decides otherwise through webhooks.TimeoutDecider, eg to deny requests under
+// an Ignore FailurePolicy.
+// Authorized runs under config.ReadSettings, so that it sees the settings of a
+// single configuration file even past the deadline. Until it returns, a
+// reload waits for it.
+func authorizeWithDeadline(hook webhooks.Webhook, request admissionctl.Request) admissionctl.Response {
+	var onTimeout, onPanic admissionctl.Response
+	var deadline time.Duration
+	config.ReadSettings(func() {
+		onTimeout = failurePolicyResponse(hook, "The webhook could not decide on the request in time")
+		onPanic = failurePolicyResponse(hook, "The webhook failed to decide on the request")
+		if decider, ok := hook.(webhooks.TimeoutDecider); ok {
+			onTimeout = decider.TimeoutResponse(request)
+			onPanic = onTimeout
+		}
+		deadline = utils.InternalDeadline(hook.TimeoutSeconds())
+	})
+	authorize := func(request admissionctl.Request) (ret admissionctl.Response) {
+		config.ReadSettings(func() { ret = authorizeRecovering(hook, request, onPanic) })
+		return ret
+	}
+	return utils.AuthorizeWithDeadline(request, deadline, authorize, onTimeout)
+}
+
+// failurePolicyResponse is the response matching the FailurePolicy of the
+// hook, for a request it failed to decide on: Ignore allows it, Fail denies
+// it
+func failurePolicyResponse(hook webhooks.Webhook, message string) admissionctl.Response {
+	if hook.FailurePolicy() == admissionregv1.Ignore {
+		return admissionctl.Allowed(message)
+	}
+	return admissionctl.Denied(message)
+}
+
+// authorizeRecovering calls the hook's Authorized, and returns onPanic should
+// it panic, rather than the panic dropping the connection.
+func authorizeRecovering(hook webhooks.Webhook, request admissionctl.Request, onPanic admissionctl.Response) (ret admissionctl.Response) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error(fmt.Errorf("%v", r), "Webhook panicked", "webhookName", hook.Name(), "uid", request.AdmissionRequest.UID, "stack", string(debug.Stack()))
+			metrics.IncrementPanics(hook.Name())
+			ret = onPanic
+			ret.UID = request.AdmissionRequest.UID
+		}
+	}()
+	return hook.Authorized(request)
+}
+
+// decision summarizes the response to the request for the audit sink
+func decision(hook webhooks.Webhook, request admissionctl.Request, ret admissionctl.Response, now time.Time) audit.Decision {
+	d := audit.Decision{
+		Time:      now,
+		Webhook:   hook.Name(),
+		UID:       string(request.AdmissionRequest.UID),
+		Operation: string(request.Operation),
+		Kind:      request.Kind.Kind,
+		Namespace: request.Namespace,
+		Name:      request.Name,
+		User:      request.UserInfo.Username,
+		Allowed:   ret.Allowed,
+		Audit:     ret.AuditAnnotations,
+	}
+	if ret.Result != nil {
+		d.Message = ret.Result.Message
+	}
+	return d
+}
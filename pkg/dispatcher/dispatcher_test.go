@@ -0,0 +1,389 @@
+package dispatcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/audit"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+const (
+	panickingWebhookName  string = "panicking-validation"
+	allowingWebhookName   string = "allowing-validation"
+	erroringWebhookName   string = "erroring-validation"
+	protectingWebhookName string = "protecting-validation"
+	slowWebhookName       string = "slow-validation"
+)
+
+// panickingWebhook is a webhook whose Authorized always panics
+type panickingWebhook struct{}
+
+func (p *panickingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	var m map[string]string
+	m["boom"] = "nil map"
+	return admissionctl.Allowed("unreachable")
+}
+func (p *panickingWebhook) GetURI() string                             { return "/" + panickingWebhookName }
+func (p *panickingWebhook) Validate(request admissionctl.Request) bool { return true }
+func (p *panickingWebhook) Name() string                               { return panickingWebhookName }
+func (p *panickingWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+func (p *panickingWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+func (p *panickingWebhook) Rules() []admissionregv1.RuleWithOperations { return nil }
+func (p *panickingWebhook) ObjectSelector() *metav1.LabelSelector      { return nil }
+func (p *panickingWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+func (p *panickingWebhook) TimeoutSeconds() int32 { return 2 }
+func (p *panickingWebhook) Doc() string           { return "" }
+func (p *panickingWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return metav1.LabelSelector{}
+}
+
+// allowingWebhook is a webhook which allows every request
+type allowingWebhook struct {
+	panickingWebhook
+}
+
+func (a *allowingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	ret := admissionctl.Allowed("allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+func (a *allowingWebhook) GetURI() string { return "/" + allowingWebhookName }
+func (a *allowingWebhook) Name() string   { return allowingWebhookName }
+
+// erroringWebhook is a webhook which errors on every request, as one with a
+// decoding bug would
+type erroringWebhook struct {
+	panickingWebhook
+}
+
+func (e *erroringWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return admissionctl.Errored(http.StatusBadRequest, fmt.Errorf("couldn't decode the object"))
+}
+func (e *erroringWebhook) GetURI() string { return "/" + erroringWebhookName }
+func (e *erroringWebhook) Name() string   { return erroringWebhookName }
+
+// protectingWebhook is a panicking webhook which denies the requests it
+// couldn't decide on, as protection webhooks do
+type protectingWebhook struct {
+	panickingWebhook
+}
+
+func (p *protectingWebhook) GetURI() string { return "/" + protectingWebhookName }
+func (p *protectingWebhook) Name() string   { return protectingWebhookName }
+func (p *protectingWebhook) TimeoutResponse(request admissionctl.Request) admissionctl.Response {
+	return admissionctl.Denied("undecided")
+}
+
+// slowWebhook is a webhook whose Authorized blocks until release is closed
+type slowWebhook struct {
+	panickingWebhook
+	release chan struct{}
+}
+
+func (s *slowWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	<-s.release
+	return admissionctl.Denied("too late")
+}
+func (s *slowWebhook) GetURI() string        { return "/" + slowWebhookName }
+func (s *slowWebhook) Name() string          { return slowWebhookName }
+func (s *slowWebhook) TimeoutSeconds() int32 { return 1 }
+
+// dispatch sends an UPDATE of a ConfigMap to the webhook through the
+// dispatcher, and returns the AdmissionResponse
+func dispatch(t *testing.T, d *Dispatcher, uri, uid string) *admissionv1.AdmissionResponse {
+	obj := runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "test"}}`)}
+	httprequest, err := testutils.CreateHTTPRequest(uri, uid,
+		metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		metav1.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		admissionv1.Update, "user1", []string{"system:authenticated"}, &obj, &obj)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	d.HandleRequest(recorder, httprequest)
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), review); err != nil {
+		t.Fatalf("Expected an AdmissionReview response, got %q: %s", recorder.Body.String(), err.Error())
+	}
+	if review.Response == nil {
+		t.Fatalf("Expected a response in the AdmissionReview, got none")
+	}
+	return review.Response
+}
+
+func TestTransientError(t *testing.T) {
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		allowingWebhookName: func() webhooks.Webhook { return &allowingWebhook{} },
+	})
+
+	config.SetDegraded("simulated outage")
+	response := dispatch(t, d, "/"+allowingWebhookName, "degraded")
+	config.ClearDegraded("simulated outage")
+
+	if response.Allowed {
+		t.Fatalf("Expected the request not to be allowed while degraded")
+	}
+	if response.UID != "degraded" {
+		t.Fatalf("Expected response UID %s, got %s", "degraded", response.UID)
+	}
+	if response.Result == nil || response.Result.Code != http.StatusServiceUnavailable || response.Result.Reason != metav1.StatusReasonServiceUnavailable {
+		t.Fatalf("Expected a %d %s status, got %v", http.StatusServiceUnavailable, metav1.StatusReasonServiceUnavailable, response.Result)
+	}
+	if response.Result.Details == nil || response.Result.Details.RetryAfterSeconds <= 0 {
+		t.Fatalf("Expected the status to ask for a retry, got %v", response.Result.Details)
+	}
+
+	response = dispatch(t, d, "/"+allowingWebhookName, "recovered")
+	if !response.Allowed {
+		t.Fatalf("Expected the request to be allowed once recovered, got %v", response.Result)
+	}
+}
+
+func TestCircuitOpensOnErrors(t *testing.T) {
+	defer config.SetCircuit(config.DefaultCircuitSettings)
+	config.SetCircuit(config.CircuitSettings{
+		ErrorRate:   0.5,
+		MinRequests: 3,
+		SafeModes:   map[string]config.SafeMode{erroringWebhookName: config.SafeModeReportOnly},
+	})
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		erroringWebhookName: func() webhooks.Webhook { return &erroringWebhook{} },
+	})
+	before := promtestutil.ToFloat64(metrics.CircuitTrips.WithLabelValues(erroringWebhookName))
+
+	for i := 0; i < 3; i++ {
+		response := dispatch(t, d, "/"+erroringWebhookName, fmt.Sprintf("error-%d", i))
+		if response.Allowed || response.Result == nil || response.Result.Code != http.StatusBadRequest {
+			t.Fatalf("Expected request %d to error, got %v", i, response.Result)
+		}
+	}
+
+	response := dispatch(t, d, "/"+erroringWebhookName, "safe-mode")
+	if !response.Allowed || len(response.Warnings) == 0 {
+		t.Fatalf("Expected the request to be allowed with a warning in %s mode, got %v", config.SafeModeReportOnly, response)
+	}
+	if response.UID != "safe-mode" {
+		t.Fatalf("Expected response UID %s, got %s", "safe-mode", response.UID)
+	}
+	if after := promtestutil.ToFloat64(metrics.CircuitTrips.WithLabelValues(erroringWebhookName)); after != before+1 {
+		t.Fatalf("Expected %s to be incremented once, went from %v to %v", "webhook_circuit_trips_total", before, after)
+	}
+	if open := promtestutil.ToFloat64(metrics.CircuitOpen.WithLabelValues(erroringWebhookName)); open != 1 {
+		t.Fatalf("Expected %s to be 1, got %v", "webhook_circuit_open", open)
+	}
+}
+
+// auditSink is an audit service recording the UIDs of the decisions it
+// receives
+type auditSink struct {
+	mu   sync.Mutex
+	uids []string
+}
+
+func (s *auditSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	decision := audit.Decision{}
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uids = append(s.uids, decision.UID)
+}
+
+func (s *auditSink) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.uids...)
+}
+
+func TestRetriedRequestExportedOnce(t *testing.T) {
+	s := &auditSink{}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	exporter := audit.NewExporter(server.URL, 10)
+	stop := make(chan struct{})
+	defer close(stop)
+	go exporter.Run(stop)
+
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		allowingWebhookName: func() webhooks.Webhook { return &allowingWebhook{} },
+	})
+	d.ExportDecisions(exporter)
+
+	first := dispatch(t, d, "/"+allowingWebhookName, "retried")
+	retry := dispatch(t, d, "/"+allowingWebhookName, "retried")
+	if !first.Allowed || !retry.Allowed || retry.UID != first.UID {
+		t.Fatalf("Expected the retried request to be decided the same way, got %v then %v", first, retry)
+	}
+	// The exporter delivers in order, so a duplicate would come before this
+	dispatch(t, d, "/"+allowingWebhookName, "next")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.received()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 2 decisions to be exported, got %v", s.received())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if uids := s.received(); len(uids) != 2 || uids[0] != "retried" || uids[1] != "next" {
+		t.Fatalf("Expected the decisions on %v to be exported once each, got %v", []string{"retried", "next"}, uids)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		protectingWebhookName: func() webhooks.Webhook { return &protectingWebhook{} },
+	})
+	before := promtestutil.ToFloat64(metrics.Panics.WithLabelValues(protectingWebhookName))
+
+	response := dispatch(t, d, "/"+protectingWebhookName, "panic")
+	if response.Allowed {
+		t.Fatalf("Expected the request to be denied when the webhook panics, got %v", response)
+	}
+	if response.UID != "panic" {
+		t.Fatalf("Expected response UID %s, got %s", "panic", response.UID)
+	}
+	if after := promtestutil.ToFloat64(metrics.Panics.WithLabelValues(protectingWebhookName)); after != before+1 {
+		t.Fatalf("Expected %s to be incremented once, went from %v to %v", "webhook_panics_total", before, after)
+	}
+}
+
+func TestPanicFollowsFailurePolicy(t *testing.T) {
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		panickingWebhookName: func() webhooks.Webhook { return &panickingWebhook{} },
+	})
+
+	response := dispatch(t, d, "/"+panickingWebhookName, "panic")
+	if !response.Allowed {
+		t.Fatalf("Expected the request to be allowed as the Ignore FailurePolicy would when the webhook panics, got %v", response.Result)
+	}
+	if response.UID != "panic" {
+		t.Fatalf("Expected response UID %s, got %s", "panic", response.UID)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	hook := &slowWebhook{release: make(chan struct{})}
+	defer close(hook.release)
+	d := NewDispatcher(webhooks.RegisteredWebhooks{
+		slowWebhookName: func() webhooks.Webhook { return hook },
+	})
+
+	response := dispatch(t, d, "/"+slowWebhookName, "slow")
+	if !response.Allowed {
+		t.Fatalf("Expected the request to be allowed as the Ignore FailurePolicy would when the webhook times out, got %v", response.Result)
+	}
+	if response.UID != "slow" {
+		t.Fatalf("Expected response UID %s, got %s", "slow", response.UID)
+	}
+}
+
+// TestReloadWhileDispatching reloads the configuration of the scc-validation
+// webhook while it decides on requests, for the race detector
+func TestReloadWhileDispatching(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-file")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	configured := filepath.Join(dir, "configured.yaml")
+	if err := ioutil.WriteFile(configured, []byte(`
+webhooks:
+  scc-validation:
+    mode: DryRun
+    timeoutSeconds: 3
+    protected:
+    - anyuid
+    - privileged
+    allowedUsers:
+    - user1
+    allowedGroups:
+    - system:serviceaccounts:openshift-backplane-srep
+    parameters:
+      forbiddenRequesterGroups: system:serviceaccounts:openshift-infra
+`), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	empty := filepath.Join(dir, "empty.yaml")
+	if err := ioutil.WriteFile(empty, []byte("webhooks: {}\n"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	defer func() {
+		if err := config.LoadFile(empty); err != nil {
+			t.Errorf("Expected no error restoring the defaults, got %s", err.Error())
+		}
+	}()
+
+	hook := webhooks.Webhooks[scc.WebhookName]
+	uri := hook().GetURI()
+	d := NewDispatcher(webhooks.RegisteredWebhooks{uri: hook})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			path := configured
+			if i%2 == 1 {
+				path = empty
+			}
+			if err := config.LoadFile(path); err != nil {
+				t.Errorf("Expected no error, got %s", err.Error())
+				return
+			}
+		}
+	}()
+
+	obj := runtime.RawExtension{Raw: []byte(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "privileged"}}`)}
+	for i := 0; i < 200; i++ {
+		httprequest, err := testutils.CreateHTTPRequest(uri, fmt.Sprintf("reload-%d", i),
+			metav1.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"},
+			metav1.GroupVersionResource{Group: "security.openshift.io", Version: "v1", Resource: "securitycontextconstraints"},
+			admissionv1.Update, "user1", []string{"system:authenticated"}, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		recorder := httptest.NewRecorder()
+		d.HandleRequest(recorder, httprequest)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -16,10 +16,12 @@ var log = logf.Log.WithName("response_helper")
 func SendResponse(w io.Writer, resp admissionctl.Response) {
 
 	// Apply ownership annotation to allow for granular alerts for
-	// manipulation of SREP owned webhooks.
-	resp.AuditAnnotations = map[string]string{
-		"owner": "srep-managed-webhook",
+	// manipulation of SREP owned webhooks. Any audit annotations set by the
+	// webhook itself are preserved.
+	if resp.AuditAnnotations == nil {
+		resp.AuditAnnotations = map[string]string{}
 	}
+	resp.AuditAnnotations["owner"] = "srep-managed-webhook"
 
 	encoder := json.NewEncoder(w)
 	responseAdmissionReview := admissionapi.AdmissionReview{
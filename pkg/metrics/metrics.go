@@ -0,0 +1,105 @@
+// Package metrics holds the Prometheus metrics shared by all webhooks. They are
+// registered with Registry, which the server serves on /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Registry is the registry of the webhook metrics. It is a plain
+	// Prometheus registry rather than the controller-runtime one, whose
+	// client-go adapter doesn't build against the pinned client-go.
+	Registry = prometheus.NewRegistry()
+	// DecodeErrors counts objects which a webhook could not decode, by webhook
+	// and object kind. A rising count is an early warning that the vendored API
+	// types are out of date with the cluster.
+	DecodeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_decode_errors_total",
+		Help: "Number of objects from admission requests which could not be decoded",
+	}, []string{"webhook", "kind"})
+	// Panics counts the calls to a webhook's Authorized which panicked, by
+	// webhook
+	Panics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_panics_total",
+		Help: "Number of admission requests during which a webhook panicked",
+	}, []string{"webhook"})
+	// PolicyVersion always has a single series, set to 1, whose version label
+	// identifies the policy bundle in effect
+	PolicyVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_policy_version_info",
+		Help: "Version of the policy bundle the webhooks enforce",
+	}, []string{"version"})
+	// DecisionExportDrops counts the decisions which weren't exported to the
+	// audit sink, by reason
+	DecisionExportDrops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_decision_export_drops_total",
+		Help: "Number of decisions which could not be exported to the audit sink",
+	}, []string{"reason"})
+	// AllowOnceTokens counts the allow-once tokens presented to a webhook, by
+	// webhook and by whether they were accepted
+	AllowOnceTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_allow_once_tokens_total",
+		Help: "Number of allow-once tokens presented to the webhooks",
+	}, []string{"webhook", "result"})
+	// CircuitOpen is 1 for each webhook whose circuit is open, which answers
+	// requests in its safe mode rather than deciding on them, and 0 once it
+	// closed again
+	CircuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_circuit_open",
+		Help: "Whether the circuit of a webhook is open, after it errored on too many requests",
+	}, []string{"webhook"})
+	// CircuitTrips counts the times the circuit of a webhook opened
+	CircuitTrips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_circuit_trips_total",
+		Help: "Number of times the circuit of a webhook opened",
+	}, []string{"webhook"})
+)
+
+// IncrementDecodeErrors records a decode failure for the given webhook and kind
+func IncrementDecodeErrors(webhook, kind string) {
+	DecodeErrors.WithLabelValues(webhook, kind).Inc()
+}
+
+// IncrementPanics records a panic of the given webhook
+func IncrementPanics(webhook string) {
+	Panics.WithLabelValues(webhook).Inc()
+}
+
+// SetPolicyVersion replaces the version exposed by PolicyVersion
+func SetPolicyVersion(version string) {
+	PolicyVersion.Reset()
+	PolicyVersion.WithLabelValues(version).Set(1)
+}
+
+// IncrementDecisionExportDrops records a decision which wasn't exported
+func IncrementDecisionExportDrops(reason string) {
+	DecisionExportDrops.WithLabelValues(reason).Inc()
+}
+
+// IncrementAllowOnceTokens records an allow-once token presented to the
+// webhook, with result either "accepted" or "rejected"
+func IncrementAllowOnceTokens(webhook, result string) {
+	AllowOnceTokens.WithLabelValues(webhook, result).Inc()
+}
+
+// OpenCircuit records the circuit of the webhook opening
+func OpenCircuit(webhook string) {
+	CircuitOpen.WithLabelValues(webhook).Set(1)
+	CircuitTrips.WithLabelValues(webhook).Inc()
+}
+
+// CloseCircuit records the circuit of the webhook closing
+func CloseCircuit(webhook string) {
+	CircuitOpen.WithLabelValues(webhook).Set(0)
+}
+
+func init() {
+	Registry.MustRegister(DecodeErrors)
+	Registry.MustRegister(Panics)
+	Registry.MustRegister(PolicyVersion)
+	Registry.MustRegister(DecisionExportDrops)
+	Registry.MustRegister(AllowOnceTokens)
+	Registry.MustRegister(CircuitOpen)
+	Registry.MustRegister(CircuitTrips)
+}
@@ -0,0 +1,103 @@
+package selftest
+
+import (
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+// URI is the path the self-test is served on
+const URI string = "/selftest"
+
+var log = logf.Log.WithName("selftest")
+
+// webhook is the subset of webhooks.Webhook the self-test exercises
+type webhook interface {
+	Validate(request admissionctl.Request) bool
+	Authorized(request admissionctl.Request) admissionctl.Response
+}
+
+// sccRequest is a synthetic, non-privileged UPDATE of a default SCC, which the
+// scc-validation webhook must deny
+func sccRequest() admissionctl.Request {
+	obj := runtime.RawExtension{
+		Raw: []byte(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted"}}`),
+	}
+	return admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID: "selftest",
+			Kind: metav1.GroupVersionKind{
+				Group:   "security.openshift.io",
+				Version: "v1",
+				Kind:    "SecurityContextConstraints",
+			},
+			Resource: metav1.GroupVersionResource{
+				Group:    "security.openshift.io",
+				Version:  "v1",
+				Resource: "securitycontextconstraints",
+			},
+			Name:      "restricted",
+			Operation: admissionv1.Update,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "managed-webhook-selftest",
+				Groups:   []string{"system:authenticated"},
+			},
+			Object:    obj,
+			OldObject: obj,
+		},
+	}
+}
+
+// check runs the request through Validate and Authorized and returns an error
+// unless the request is denied. A request allowed only because the webhook
+// is in DryRun mode counts as denied, as the protection still works.
+func check(hook webhook, request admissionctl.Request) error {
+	if !hook.Validate(request) {
+		return fmt.Errorf("synthetic request was rejected as invalid")
+	}
+	resp := hook.Authorized(request)
+	if scc.DeniedInDryRun(resp) {
+		return nil
+	}
+	if resp.Allowed {
+		return fmt.Errorf("synthetic request was allowed")
+	}
+	if resp.Result != nil && resp.Result.Code != http.StatusForbidden {
+		return fmt.Errorf("synthetic request failed with code %d: %s", resp.Result.Code, resp.Result.Message)
+	}
+	return nil
+}
+
+// newHandler serves 200 when newHook denies the request, and 500 otherwise.
+// The check runs under config.ReadSettings, as the webhook reads its settings.
+func newHandler(newHook func() webhook, request func() admissionctl.Request) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		config.ReadSettings(func() { err = check(newHook(), request()) })
+		if err != nil {
+			log.Error(err, "Self-test failed")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// Handler serves the self-test, which sends a synthetic protected SCC UPDATE
+// through the scc-validation webhook. A probe can poll it to detect that the
+// webhook logic silently stopped protecting default SCCs, eg because of bad
+// dynamic configuration. The webhook is a synthetic one, so the probes don't
+// show up as recent denials nor read the live SCCs.
+func Handler() http.HandlerFunc {
+	return newHandler(func() webhook { return scc.NewSyntheticWebhook() }, sccRequest)
+}
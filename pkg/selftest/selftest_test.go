@@ -0,0 +1,81 @@
+package selftest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+// allowingWebhook stands in for a scc-validation webhook whose protection has
+// been disabled
+type allowingWebhook struct{}
+
+func (a *allowingWebhook) Validate(request admissionctl.Request) bool { return true }
+
+func (a *allowingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return admissionctl.Allowed("Request is allowed")
+}
+
+func runSelfTest(handler http.HandlerFunc) int {
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", URI, nil))
+	return recorder.Code
+}
+
+func TestHealthy(t *testing.T) {
+	if code := runSelfTest(Handler()); code != http.StatusOK {
+		t.Fatalf("Expected self-test to report %d, got %d", http.StatusOK, code)
+	}
+}
+
+func TestUnhealthy(t *testing.T) {
+	handler := newHandler(func() webhook { return &allowingWebhook{} }, sccRequest)
+	if code := runSelfTest(handler); code != http.StatusInternalServerError {
+		t.Fatalf("Expected self-test to report %d, got %d", http.StatusInternalServerError, code)
+	}
+}
+
+// setSCCMode configures the mode of the scc-validation webhook through a
+// configuration file, as its settings aren't reachable from this package
+func setSCCMode(t *testing.T, mode config.Mode) {
+	dir, err := ioutil.TempDir("", "selftest")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	content := "webhooks:\n  " + scc.WebhookName + ":\n    mode: " + string(mode) + "\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if err := config.LoadFile(path); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+}
+
+func TestHealthyInDryRun(t *testing.T) {
+	setSCCMode(t, config.ModeDryRun)
+	defer setSCCMode(t, config.ModeEnforce)
+
+	if code := runSelfTest(Handler()); code != http.StatusOK {
+		t.Fatalf("Expected self-test to report %d in %s mode, got %d", http.StatusOK, config.ModeDryRun, code)
+	}
+}
+
+func TestNoRecentDenials(t *testing.T) {
+	before := len(scc.RecentDenials())
+	if code := runSelfTest(Handler()); code != http.StatusOK {
+		t.Fatalf("Expected self-test to report %d, got %d", http.StatusOK, code)
+	}
+	if after := len(scc.RecentDenials()); after != before {
+		t.Fatalf("Expected the self-test not to be recorded as a recent denial, got %d denials instead of %d", after, before)
+	}
+}
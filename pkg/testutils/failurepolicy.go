@@ -0,0 +1,53 @@
+package testutils
+
+import (
+	"errors"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// FailurePolicyWebhook is a Webhook which declares its FailurePolicy
+type FailurePolicyWebhook interface {
+	Webhook
+	FailurePolicy() admissionregv1.FailurePolicyType
+}
+
+var (
+	// ErrCallTimeout stands in for the webhook not answering within its
+	// TimeoutSeconds
+	ErrCallTimeout = errors.New("webhook call timed out")
+	// ErrUnreachable stands in for the connection to the webhook failing, eg
+	// its pod crashed or has no endpoint
+	ErrUnreachable = errors.New("webhook is unreachable")
+)
+
+// SimulateAdmission returns whether the API server would let the request
+// through, given the webhook's declared FailurePolicy. callErr injects a
+// failure of the call itself, eg ErrCallTimeout or ErrUnreachable, in which
+// case the webhook isn't called; pass nil to call it. Authorized panicking
+// fails the call too, as the server then answers with an HTTP error. For a
+// failed call the API server applies the FailurePolicy: with Ignore the change
+// goes through unchecked, with Fail it is rejected. A response the webhook
+// returns reaches the API server over HTTP 200, so it is taken at face value,
+// whatever its Result.Code: an Errored response is a denial.
+func SimulateAdmission(hook FailurePolicyWebhook, request admissionctl.Request, callErr error) bool {
+	if callErr == nil {
+		var resp admissionctl.Response
+		resp, callErr = call(hook, request)
+		if callErr == nil {
+			return resp.Allowed
+		}
+	}
+	return hook.FailurePolicy() == admissionregv1.Ignore
+}
+
+// call calls the webhook, turning a panic into a failed call
+func call(hook Webhook, request admissionctl.Request) (resp admissionctl.Response, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("webhook panicked")
+		}
+	}()
+	return hook.Authorized(request), nil
+}
@@ -0,0 +1,104 @@
+package testutils
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// fakeWebhook always answers with the same response, or panics
+type fakeWebhook struct {
+	response      admissionctl.Response
+	panics        bool
+	failurePolicy admissionregv1.FailurePolicyType
+}
+
+func (f *fakeWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	if f.panics {
+		panic("webhook bug")
+	}
+	return f.response
+}
+
+func (f *fakeWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return f.failurePolicy
+}
+
+func TestSimulateAdmission(t *testing.T) {
+	tests := []struct {
+		testID          string
+		response        admissionctl.Response
+		panics          bool
+		callErr         error
+		failurePolicy   admissionregv1.FailurePolicyType
+		shouldBeAllowed bool
+	}{
+		{
+			testID:          "timeout-with-ignore-is-allowed",
+			response:        admissionctl.Denied("not allowed"),
+			callErr:         ErrCallTimeout,
+			failurePolicy:   admissionregv1.Ignore,
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "unreachable-with-ignore-is-allowed",
+			response:        admissionctl.Denied("not allowed"),
+			callErr:         ErrUnreachable,
+			failurePolicy:   admissionregv1.Ignore,
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "unreachable-with-fail-is-denied",
+			response:        admissionctl.Allowed("allowed"),
+			callErr:         ErrUnreachable,
+			failurePolicy:   admissionregv1.Fail,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "panic-with-ignore-is-allowed",
+			panics:          true,
+			failurePolicy:   admissionregv1.Ignore,
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "panic-with-fail-is-denied",
+			panics:          true,
+			failurePolicy:   admissionregv1.Fail,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "errored-with-ignore-is-denied",
+			response:        admissionctl.Errored(http.StatusInternalServerError, fmt.Errorf("webhook is broken")),
+			failurePolicy:   admissionregv1.Ignore,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "bad-request-with-ignore-is-denied",
+			response:        admissionctl.Errored(http.StatusBadRequest, fmt.Errorf("couldn't decode object")),
+			failurePolicy:   admissionregv1.Ignore,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "denied-with-ignore-is-denied",
+			response:        admissionctl.Denied("not allowed"),
+			failurePolicy:   admissionregv1.Ignore,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "allowed-with-fail-is-allowed",
+			response:        admissionctl.Allowed("allowed"),
+			failurePolicy:   admissionregv1.Fail,
+			shouldBeAllowed: true,
+		},
+	}
+	for _, test := range tests {
+		hook := &fakeWebhook{response: test.response, panics: test.panics, failurePolicy: test.failurePolicy}
+		allowed := SimulateAdmission(hook, admissionctl.Request{}, test.callErr)
+		if allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: simulated admission is %t, expected %t", test.testID, allowed, test.shouldBeAllowed)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/admissionpolicy"
+)
+
+func init() {
+	Register(admissionpolicy.WebhookName, func() Webhook { return admissionpolicy.NewWebhook() })
+}
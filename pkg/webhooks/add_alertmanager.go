@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/alertmanager"
+)
+
+func init() {
+	Register(alertmanager.WebhookName, func() Webhook { return alertmanager.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/apiserverconfig"
+)
+
+func init() {
+	Register(apiserverconfig.WebhookName, func() Webhook { return apiserverconfig.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/apiservice"
+)
+
+func init() {
+	Register(apiservice.WebhookName, func() Webhook { return apiservice.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/clusterresourcequota"
+)
+
+func init() {
+	Register(clusterresourcequota.WebhookName, func() Webhook { return clusterresourcequota.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/configmap"
+)
+
+func init() {
+	Register(configmap.WebhookName, func() Webhook { return configmap.NewWebhook() })
+}
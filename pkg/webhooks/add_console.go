@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/console"
+)
+
+func init() {
+	Register(console.WebhookName, func() Webhook { return console.NewWebhook() })
+}
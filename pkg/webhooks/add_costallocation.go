@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/costallocation"
+)
+
+func init() {
+	Register(costallocation.WebhookName, func() Webhook { return costallocation.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/cronjob"
+)
+
+func init() {
+	Register(cronjob.WebhookName, func() Webhook { return cronjob.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/defaultstorageclass"
+)
+
+func init() {
+	Register(defaultstorageclass.WebhookName, func() Webhook { return defaultstorageclass.NewWebhook() })
+}
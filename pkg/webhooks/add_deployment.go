@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/deployment"
+)
+
+func init() {
+	Register(deployment.WebhookName, func() Webhook { return deployment.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/deploymentimage"
+)
+
+func init() {
+	Register(deploymentimage.WebhookName, func() Webhook { return deploymentimage.NewWebhook() })
+}
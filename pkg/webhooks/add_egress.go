@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/egress"
+)
+
+func init() {
+	Register(egress.WebhookName, func() Webhook { return egress.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/etcdbackup"
+)
+
+func init() {
+	Register(etcdbackup.WebhookName, func() Webhook { return etcdbackup.NewWebhook() })
+}
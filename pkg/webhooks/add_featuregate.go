@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/featuregate"
+)
+
+func init() {
+	Register(featuregate.WebhookName, func() Webhook { return featuregate.NewWebhook() })
+}
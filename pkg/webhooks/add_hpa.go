@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/hpa"
+)
+
+func init() {
+	Register(hpa.WebhookName, func() Webhook { return hpa.NewWebhook() })
+}
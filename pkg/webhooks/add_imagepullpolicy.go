@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/imagepullpolicy"
+)
+
+func init() {
+	Register(imagepullpolicy.WebhookName, func() Webhook { return imagepullpolicy.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/imageregistry"
+)
+
+func init() {
+	Register(imageregistry.WebhookName, func() Webhook { return imageregistry.NewWebhook() })
+}
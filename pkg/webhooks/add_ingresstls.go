@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/ingresstls"
+)
+
+func init() {
+	Register(ingresstls.WebhookName, func() Webhook { return ingresstls.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/kubeadmin"
+)
+
+func init() {
+	Register(kubeadmin.WebhookName, func() Webhook { return kubeadmin.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/labelprotection"
+)
+
+func init() {
+	Register(labelprotection.WebhookName, func() Webhook { return labelprotection.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/limitrange"
+)
+
+func init() {
+	Register(limitrange.WebhookName, func() Webhook { return limitrange.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/namespacefinalizer"
+)
+
+func init() {
+	Register(namespacefinalizer.WebhookName, func() Webhook { return namespacefinalizer.NewWebhook() })
+}
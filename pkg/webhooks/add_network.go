@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/network"
+)
+
+func init() {
+	Register(network.WebhookName, func() Webhook { return network.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/networkpolicy"
+)
+
+func init() {
+	Register(networkpolicy.WebhookName, func() Webhook { return networkpolicy.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/operatorgroup"
+)
+
+func init() {
+	Register(operatorgroup.WebhookName, func() Webhook { return operatorgroup.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/ownershiplabel"
+)
+
+func init() {
+	Register(ownershiplabel.WebhookName, func() Webhook { return ownershiplabel.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/poddisruptionbudget"
+)
+
+func init() {
+	Register(poddisruptionbudget.WebhookName, func() Webhook { return poddisruptionbudget.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/rolebinding"
+)
+
+func init() {
+	Register(rolebinding.WebhookName, func() Webhook { return rolebinding.NewWebhook() })
+}
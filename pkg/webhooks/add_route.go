@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/route"
+)
+
+func init() {
+	Register(route.WebhookName, func() Webhook { return route.NewWebhook() })
+}
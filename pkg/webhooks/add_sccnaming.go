@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/sccnaming"
+)
+
+func init() {
+	Register(sccnaming.WebhookName, func() Webhook { return sccnaming.NewWebhook() })
+}
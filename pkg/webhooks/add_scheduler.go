@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scheduler"
+)
+
+func init() {
+	Register(scheduler.WebhookName, func() Webhook { return scheduler.NewWebhook() })
+}
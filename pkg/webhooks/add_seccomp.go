@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/seccomp"
+)
+
+func init() {
+	Register(seccomp.WebhookName, func() Webhook { return seccomp.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/secret"
+)
+
+func init() {
+	Register(secret.WebhookName, func() Webhook { return secret.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/servicemonitor"
+)
+
+func init() {
+	Register(servicemonitor.WebhookName, func() Webhook { return servicemonitor.NewWebhook() })
+}
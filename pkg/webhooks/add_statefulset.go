@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/statefulset"
+)
+
+func init() {
+	Register(statefulset.WebhookName, func() Webhook { return statefulset.NewWebhook() })
+}
@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/storageclass"
+)
+
+func init() {
+	Register(storageclass.WebhookName, func() Webhook { return storageclass.NewWebhook() })
+}
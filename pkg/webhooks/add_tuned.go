@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/tuned"
+)
+
+func init() {
+	Register(tuned.WebhookName, func() Webhook { return tuned.NewWebhook() })
+}
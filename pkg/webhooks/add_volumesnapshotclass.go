@@ -0,0 +1,9 @@
+package webhooks
+
+import (
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/volumesnapshotclass"
+)
+
+func init() {
+	Register(volumesnapshotclass.WebhookName, func() Webhook { return volumesnapshotclass.NewWebhook() })
+}
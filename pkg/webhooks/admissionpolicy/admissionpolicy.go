@@ -0,0 +1,265 @@
+package admissionpolicy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName       string = "admissionpolicy-validation"
+	docString         string = `Managed OpenShift Customers may not modify or delete the following managed ValidatingAdmissionPolicies %s or ValidatingAdmissionPolicyBindings %s.`
+	policyGroup       string = "admissionregistration.k8s.io"
+	policyKind        string = "ValidatingAdmissionPolicy"
+	policyBindingKind string = "ValidatingAdmissionPolicyBinding"
+	// managedPolicyBindingsParameter is the parameter of the configuration
+	// file setting the comma-separated managedPolicyBindings
+	managedPolicyBindingsParameter string = "managedPolicyBindings"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{policyGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"validatingadmissionpolicies", "validatingadmissionpolicybindings"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedPolicies is the inventory of managed ValidatingAdmissionPolicies
+	managedPolicies = []string{
+		"managed-policy",
+	}
+	// managedPolicyBindings is the inventory of managed
+	// ValidatingAdmissionPolicyBindings
+	managedPolicyBindings = []string{
+		"managed-policy-binding",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedPolicies, &managedPolicyBindings)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it. The protected list sets the
+// managedPolicies.
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var bindings []string
+	for name, value := range settings.Parameters {
+		if name != managedPolicyBindingsParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		for _, binding := range strings.Split(value, ",") {
+			binding = strings.TrimSpace(binding)
+			if binding == "" {
+				return nil, fmt.Errorf("%s %q has an empty name", managedPolicyBindingsParameter, value)
+			}
+			bindings = append(bindings, binding)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedPolicies = settings.Protected
+		}
+		if bindings != nil {
+			managedPolicyBindings = bindings
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedPolicies":       managedPolicies,
+		"managedPolicyBindings": managedPolicyBindings,
+		"allowedUsers":          allowedUsers,
+		"allowedGroups":         allowedGroups,
+	}
+}
+
+// AdmissionPolicyWebhook protects managed ValidatingAdmissionPolicy and
+// ValidatingAdmissionPolicyBinding objects
+type AdmissionPolicyWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *AdmissionPolicyWebhook {
+	return &AdmissionPolicyWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *AdmissionPolicyWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *AdmissionPolicyWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	obj, err := s.renderPolicyObject(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an admission policy object from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isManaged(request.Kind.Kind, obj) && !isAllowedUserGroup(request) {
+		log.Info(fmt.Sprintf("%s operation detected on managed %s: %s", request.Operation, request.Kind.Kind, obj.GetName()))
+		ret = admissionctl.Denied(fmt.Sprintf("Modifying or deleting managed %s %s is not allowed", request.Kind.Kind, obj.GetName()))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderPolicyObject renders the ValidatingAdmissionPolicy or
+// ValidatingAdmissionPolicyBinding from the request. These types are newer
+// than the vendored k8s.io/api, so the object is decoded generically.
+func (s *AdmissionPolicyWebhook) renderPolicyObject(request admissionctl.Request) (*unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+
+	if len(request.OldObject.Raw) > 0 {
+		err = decoder.DecodeRaw(request.OldObject, obj)
+	}
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// isManaged checks if the object is in the relevant managed inventory
+func isManaged(kind string, obj *unstructured.Unstructured) bool {
+	switch kind {
+	case policyKind:
+		return utils.SliceContains(obj.GetName(), managedPolicies)
+	case policyBindingKind:
+		return utils.SliceContains(obj.GetName(), managedPolicyBindings)
+	}
+	return false
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *AdmissionPolicyWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *AdmissionPolicyWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Group == policyGroup)
+	valid = valid && (request.Kind.Kind == policyKind || request.Kind.Kind == policyBindingKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *AdmissionPolicyWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *AdmissionPolicyWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *AdmissionPolicyWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *AdmissionPolicyWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *AdmissionPolicyWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *AdmissionPolicyWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *AdmissionPolicyWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *AdmissionPolicyWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedPolicies, managedPolicyBindings)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *AdmissionPolicyWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,203 @@
+package admissionpolicy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type admissionPolicyTestSuites struct {
+	testID          string
+	kind            string
+	resource        string
+	targetName      string
+	username        string
+	operation       admissionv1.Operation
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "admissionregistration.k8s.io/v1",
+	"kind": "%s",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	}
+}`
+
+func runAdmissionPolicyTests(t *testing.T, tests []admissionPolicyTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "admissionregistration.k8s.io",
+			Version: "v1",
+			Kind:    test.kind,
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    "admissionregistration.k8s.io",
+			Version:  "v1",
+			Resource: test.resource,
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.kind, test.targetName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.kind, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []admissionPolicyTestSuites{
+		{
+			testID:          "user-cant-delete-managed-policy",
+			kind:            "ValidatingAdmissionPolicy",
+			resource:        "validatingadmissionpolicies",
+			targetName:      "managed-policy",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-managed-policy-binding",
+			kind:            "ValidatingAdmissionPolicyBinding",
+			resource:        "validatingadmissionpolicybindings",
+			targetName:      "managed-policy-binding",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-policy",
+			kind:            "ValidatingAdmissionPolicy",
+			resource:        "validatingadmissionpolicies",
+			targetName:      "managed-policy",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runAdmissionPolicyTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []admissionPolicyTestSuites{
+		{
+			testID:          "user-can-delete-customer-policy",
+			kind:            "ValidatingAdmissionPolicy",
+			resource:        "validatingadmissionpolicies",
+			targetName:      "customer-policy",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-binding-named-like-managed-policy",
+			kind:            "ValidatingAdmissionPolicyBinding",
+			resource:        "validatingadmissionpolicybindings",
+			targetName:      "managed-policy",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "backplane-can-delete-managed-policy",
+			kind:            "ValidatingAdmissionPolicy",
+			resource:        "validatingadmissionpolicies",
+			targetName:      "managed-policy",
+			username:        "backplane-cluster-admin",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: true,
+		},
+	}
+	runAdmissionPolicyTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldPolicies, oldBindings := managedPolicies, managedPolicyBindings
+	defer func() { managedPolicies, managedPolicyBindings = oldPolicies, oldBindings }()
+	apply, err := applySettings(config.WebhookSettings{
+		Protected:  []string{"deny-privileged-pods"},
+		Parameters: map[string]string{managedPolicyBindingsParameter: "deny-privileged-pods-binding"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []admissionPolicyTestSuites{
+		{
+			testID:          "user-cant-delete-configured-policy",
+			kind:            "ValidatingAdmissionPolicy",
+			resource:        "validatingadmissionpolicies",
+			targetName:      "deny-privileged-pods",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-policy",
+			kind:            "ValidatingAdmissionPolicy",
+			resource:        "validatingadmissionpolicies",
+			targetName:      "managed-policy",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-cant-delete-configured-binding",
+			kind:            "ValidatingAdmissionPolicyBinding",
+			resource:        "validatingadmissionpolicybindings",
+			targetName:      "deny-privileged-pods-binding",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-binding",
+			kind:            "ValidatingAdmissionPolicyBinding",
+			resource:        "validatingadmissionpolicybindings",
+			targetName:      "managed-policy-binding",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runAdmissionPolicyTests(t, tests)
+
+	if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{"managedPolicyBinding": "deny-privileged-pods-binding"}}); err == nil {
+		t.Fatalf("Expected an unknown parameter to be rejected")
+	}
+}
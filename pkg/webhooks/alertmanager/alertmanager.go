@@ -0,0 +1,311 @@
+package alertmanager
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ghodss/yaml"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "alertmanager-config-validation"
+	docString   string = `Managed OpenShift Customers may not remove the managed receivers %s, or the routes to them, from the %s Alertmanager configuration.`
+	secretKind  string = "Secret"
+	// configNamespace is the namespace of the platform Alertmanager
+	configNamespace string = "openshift-monitoring"
+	// configSecret is the namespace/name of the platform Alertmanager
+	// configuration Secret
+	configSecret string = configNamespace + "/alertmanager-main"
+	// configKey is the key of the configuration within configSecret
+	configKey string = "alertmanager.yaml"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"secrets"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-monitoring:configure-alertmanager-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedReceivers are the receivers SRE relies on to be notified of
+	// platform alerts
+	managedReceivers = []string{
+		"dms",
+		"pagerduty",
+	}
+)
+
+// alertmanagerConfig is the subset of the Alertmanager configuration the
+// webhook needs to inspect
+type alertmanagerConfig struct {
+	Route     *route     `json:"route,omitempty"`
+	Receivers []receiver `json:"receivers,omitempty"`
+}
+
+type route struct {
+	Receiver string   `json:"receiver,omitempty"`
+	Routes   []*route `json:"routes,omitempty"`
+}
+
+type receiver struct {
+	Name string `json:"name"`
+}
+
+// AlertmanagerWebhook protects the managed parts of the platform Alertmanager
+// configuration
+type AlertmanagerWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *AlertmanagerWebhook {
+	return &AlertmanagerWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *AlertmanagerWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *AlertmanagerWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newSecret, oldSecret, err := s.renderOldAndNewSecrets(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Secret from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if oldSecret.Namespace+"/"+oldSecret.Name != configSecret {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	oldConfig, err := parseConfig(oldSecret)
+	if err != nil {
+		// Nothing managed can be recognised in the existing configuration, so
+		// there is nothing to protect
+		log.Info(fmt.Sprintf("Couldn't parse the existing Alertmanager configuration: %s", err.Error()))
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+	newConfig, err := parseConfig(newSecret)
+	if err != nil {
+		ret = admissionctl.Denied(fmt.Sprintf("The new Alertmanager configuration can't be parsed: %s", err.Error()))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if missing := missingManaged(oldConfig.receiverNames(), newConfig.receiverNames()); len(missing) > 0 {
+		log.Info(fmt.Sprintf("Removal of managed Alertmanager receivers detected: %v", missing))
+		ret = admissionctl.Denied(fmt.Sprintf("Removing the managed Alertmanager receivers %v is not allowed", missing))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+	if missing := missingManaged(oldConfig.routedReceivers(), newConfig.routedReceivers()); len(missing) > 0 {
+		log.Info(fmt.Sprintf("Removal of routes to managed Alertmanager receivers detected: %v", missing))
+		ret = admissionctl.Denied(fmt.Sprintf("Removing the routes to the managed Alertmanager receivers %v is not allowed", missing))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewSecrets decodes both the Object and OldObject of the request.
+// Return order is: new, old, error.
+func (s *AlertmanagerWebhook) renderOldAndNewSecrets(request admissionctl.Request) (*corev1.Secret, *corev1.Secret, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	newSecret := &corev1.Secret{}
+	oldSecret := &corev1.Secret{}
+
+	err = decoder.DecodeRaw(request.Object, newSecret)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	err = decoder.DecodeRaw(request.OldObject, oldSecret)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+
+	return newSecret, oldSecret, nil
+}
+
+// parseConfig parses the Alertmanager configuration held by the Secret
+func parseConfig(secret *corev1.Secret) (*alertmanagerConfig, error) {
+	config := &alertmanagerConfig{}
+	if err := yaml.Unmarshal(secret.Data[configKey], config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// receiverNames returns the names of all the receivers defined
+func (c *alertmanagerConfig) receiverNames() []string {
+	names := []string{}
+	for _, r := range c.Receivers {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// routedReceivers returns the receivers referenced anywhere in the routing
+// tree
+func (c *alertmanagerConfig) routedReceivers() []string {
+	names := []string{}
+	var walk func(r *route)
+	walk = func(r *route) {
+		if r == nil {
+			return
+		}
+		if r.Receiver != "" {
+			names = append(names, r.Receiver)
+		}
+		for _, child := range r.Routes {
+			walk(child)
+		}
+	}
+	walk(c.Route)
+	return names
+}
+
+// missingManaged returns the managed receivers present in before but not in
+// after
+func missingManaged(before, after []string) []string {
+	missing := []string{}
+	for _, name := range managedReceivers {
+		if utils.SliceContains(name, before) && !utils.SliceContains(name, after) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *AlertmanagerWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *AlertmanagerWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == secretKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *AlertmanagerWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *AlertmanagerWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *AlertmanagerWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *AlertmanagerWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *AlertmanagerWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// NamespaceSelector implements NamespaceSelective interface, so that the
+// updates of the Secrets outside of the namespace of configSecret don't wait
+// on the webhook
+func (s *AlertmanagerWebhook) NamespaceSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"kubernetes.io/metadata.name": configNamespace,
+		},
+	}
+}
+
+// SideEffects implements Webhook interface
+func (s *AlertmanagerWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *AlertmanagerWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *AlertmanagerWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedReceivers, configSecret)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *AlertmanagerWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,218 @@
+package alertmanager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type alertmanagerTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldConfig       string
+	newConfig       string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Secret",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"data": {
+		"alertmanager.yaml": "%s"
+	}
+}`
+
+const managedConfig string = `
+route:
+  receiver: "null"
+  routes:
+  - receiver: dms
+    match:
+      alertname: Watchdog
+  - receiver: pagerduty
+    match:
+      namespace: openshift-monitoring
+receivers:
+- name: "null"
+- name: dms
+- name: pagerduty
+`
+
+const withoutPagerDutyRoute string = `
+route:
+  receiver: "null"
+  routes:
+  - receiver: dms
+    match:
+      alertname: Watchdog
+receivers:
+- name: "null"
+- name: dms
+- name: pagerduty
+`
+
+const withoutPagerDutyReceiver string = `
+route:
+  receiver: "null"
+  routes:
+  - receiver: dms
+    match:
+      alertname: Watchdog
+  - receiver: pagerduty
+    match:
+      namespace: openshift-monitoring
+receivers:
+- name: "null"
+- name: dms
+`
+
+const withCustomerRoute string = `
+route:
+  receiver: "null"
+  routes:
+  - receiver: dms
+    match:
+      alertname: Watchdog
+  - receiver: pagerduty
+    match:
+      namespace: openshift-monitoring
+  - receiver: customer-slack
+    match:
+      namespace: my-project
+receivers:
+- name: "null"
+- name: dms
+- name: pagerduty
+- name: customer-slack
+`
+
+func createRawJSONString(name, namespace, config string) string {
+	return fmt.Sprintf(testObjectRaw, name, namespace, base64.StdEncoding.EncodeToString([]byte(config)))
+}
+
+func runAlertmanagerTests(t *testing.T, tests []alertmanagerTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Secret",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "secrets",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(createRawJSONString(test.targetName, test.targetNamespace, test.newConfig)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(createRawJSONString(test.targetName, test.targetNamespace, test.oldConfig)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: %s (groups=%s) %s update the Secret %s/%s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []alertmanagerTestSuites{
+		{
+			testID:          "user-cant-remove-managed-route",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "alertmanager-main",
+			oldConfig:       managedConfig,
+			newConfig:       withoutPagerDutyRoute,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-remove-managed-receiver",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "alertmanager-main",
+			oldConfig:       managedConfig,
+			newConfig:       withoutPagerDutyReceiver,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-write-unparsable-config",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "alertmanager-main",
+			oldConfig:       managedConfig,
+			newConfig:       "route: [",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runAlertmanagerTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []alertmanagerTestSuites{
+		{
+			testID:          "user-can-add-customer-route",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "alertmanager-main",
+			oldConfig:       managedConfig,
+			newConfig:       withCustomerRoute,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "operator-can-remove-managed-route",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "alertmanager-main",
+			oldConfig:       managedConfig,
+			newConfig:       withoutPagerDutyRoute,
+			username:        "system:serviceaccount:openshift-monitoring:configure-alertmanager-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-edit-other-secret",
+			targetNamespace: "my-project",
+			targetName:      "alertmanager-main",
+			oldConfig:       managedConfig,
+			newConfig:       withoutPagerDutyRoute,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runAlertmanagerTests(t, tests)
+}
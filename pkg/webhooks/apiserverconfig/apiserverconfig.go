@@ -0,0 +1,291 @@
+package apiserverconfig
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName        string = "apiserverconfig-validation"
+	docString          string = `Managed OpenShift Customers may not change the authentication and authorization webhooks, modes and admission plugins of the API server, set through the following managed Authentication and KubeAPIServer objects: %s`
+	authenticationKind string = "Authentication"
+	kubeAPIServerKind  string = "KubeAPIServer"
+	configGroup        string = "config.openshift.io"
+	operatorGroup      string = "operator.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{configGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"authentications"},
+				Scope:       &scope,
+			},
+		},
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{operatorGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"kubeapiservers"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-kube-apiserver-operator:kube-apiserver-operator",
+		"system:serviceaccount:openshift-authentication-operator:authentication-operator",
+		"system:serviceaccount:openshift-cluster-version:default",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedConfigs is the inventory of managed API server configuration
+	// objects, which are singletons named after the cluster
+	managedConfigs = []string{
+		"cluster",
+	}
+	// protectedFields maps each kind of API server configuration object to
+	// the fields setting how the API server authenticates and authorizes
+	// requests, and which admission plugins and webhooks it runs
+	protectedFields = map[string][][]string{
+		authenticationKind: {
+			{"spec", "type"},
+			{"spec", "webhookTokenAuthenticator"},
+			{"spec", "webhookTokenAuthenticators"},
+		},
+		kubeAPIServerKind: {
+			{"spec", "unsupportedConfigOverrides", "apiServerArguments", "authorization-mode"},
+			{"spec", "unsupportedConfigOverrides", "apiServerArguments", "authorization-webhook-config-file"},
+			{"spec", "unsupportedConfigOverrides", "apiServerArguments", "authentication-token-webhook-config-file"},
+			{"spec", "unsupportedConfigOverrides", "apiServerArguments", "enable-admission-plugins"},
+			{"spec", "unsupportedConfigOverrides", "apiServerArguments", "disable-admission-plugins"},
+			{"spec", "unsupportedConfigOverrides", "apiServerArguments", "admission-control-config-file"},
+			{"spec", "unsupportedConfigOverrides", "admission"},
+		},
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedConfigs)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedConfigs = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedConfigs": managedConfigs,
+		"allowedUsers":   allowedUsers,
+		"allowedGroups":  allowedGroups,
+	}
+}
+
+// APIServerConfigWebhook keeps customers from changing how the API server
+// authenticates, authorizes and admits requests, which the other webhooks
+// rely on
+type APIServerConfigWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *APIServerConfigWebhook {
+	return &APIServerConfigWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *APIServerConfigWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *APIServerConfigWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newConfig, oldConfig, err := s.renderOldAndNewConfigs(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an API server configuration from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if utils.SliceContains(newConfig.GetName(), managedConfigs) {
+		if changed := changedProtectedFields(request.Kind.Kind, newConfig, oldConfig); len(changed) > 0 {
+			log.Info(fmt.Sprintf("Change of protected fields %v detected on managed %s %s", changed, request.Kind.Kind, newConfig.GetName()))
+			ret = admissionctl.Denied(fmt.Sprintf("Changing %v of managed %s %s is not allowed", changed, request.Kind.Kind, newConfig.GetName()))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewConfigs decodes both the Object and OldObject of the
+// request. The operator types are not vendored, so the objects are decoded
+// generically. Return order is: new, old, error.
+func (s *APIServerConfigWebhook) renderOldAndNewConfigs(request admissionctl.Request) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(request.Object.Raw) == 0 || len(request.OldObject.Raw) == 0 {
+		return nil, nil, fmt.Errorf("%s UPDATE request is missing an object", request.Kind.Kind)
+	}
+
+	newConfig := &unstructured.Unstructured{}
+	oldConfig := &unstructured.Unstructured{}
+	if err := decoder.DecodeRaw(request.Object, newConfig); err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if err := decoder.DecodeRaw(request.OldObject, oldConfig); err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	return newConfig, oldConfig, nil
+}
+
+// changedProtectedFields returns the dot separated paths of the protected
+// fields of the kind which differ between the old and new objects. A field
+// absent from both is unchanged.
+func changedProtectedFields(kind string, newConfig, oldConfig *unstructured.Unstructured) []string {
+	var changed []string
+	for _, path := range protectedFields[kind] {
+		newValue, newFound, _ := unstructured.NestedFieldNoCopy(newConfig.Object, path...)
+		oldValue, oldFound, _ := unstructured.NestedFieldNoCopy(oldConfig.Object, path...)
+		if newFound != oldFound || !reflect.DeepEqual(newValue, oldValue) {
+			changed = append(changed, strings.Join(path, "."))
+		}
+	}
+	return changed
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *APIServerConfigWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *APIServerConfigWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && ((request.Kind.Kind == authenticationKind && request.Kind.Group == configGroup) ||
+		(request.Kind.Kind == kubeAPIServerKind && request.Kind.Group == operatorGroup))
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *APIServerConfigWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *APIServerConfigWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *APIServerConfigWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *APIServerConfigWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *APIServerConfigWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *APIServerConfigWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *APIServerConfigWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *APIServerConfigWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedConfigs)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *APIServerConfigWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,155 @@
+package apiserverconfig
+
+import (
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type apiServerConfigTestSuites struct {
+	testID          string
+	kind            string
+	oldObject       string
+	newObject       string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const (
+	defaultKubeAPIServerRaw     string = `{"apiVersion": "operator.openshift.io/v1", "kind": "KubeAPIServer", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"logLevel": "Normal", "unsupportedConfigOverrides": null}}`
+	debugKubeAPIServerRaw       string = `{"apiVersion": "operator.openshift.io/v1", "kind": "KubeAPIServer", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"logLevel": "Debug", "unsupportedConfigOverrides": null}}`
+	alwaysAllowKubeAPIServerRaw string = `{"apiVersion": "operator.openshift.io/v1", "kind": "KubeAPIServer", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"logLevel": "Normal", "unsupportedConfigOverrides": {"apiServerArguments": {"authorization-mode": ["AlwaysAllow"]}}}}`
+	noPluginsKubeAPIServerRaw   string = `{"apiVersion": "operator.openshift.io/v1", "kind": "KubeAPIServer", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"logLevel": "Normal", "unsupportedConfigOverrides": {"apiServerArguments": {"disable-admission-plugins": ["ValidatingAdmissionWebhook"]}}}}`
+	defaultAuthenticationRaw    string = `{"apiVersion": "config.openshift.io/v1", "kind": "Authentication", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"type": "IntegratedOAuth"}}`
+	webhookAuthenticationRaw    string = `{"apiVersion": "config.openshift.io/v1", "kind": "Authentication", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"type": "IntegratedOAuth", "webhookTokenAuthenticator": {"kubeConfig": {"name": "my-authenticator"}}}}`
+	labelledAuthenticationRaw   string = `{"apiVersion": "config.openshift.io/v1", "kind": "Authentication", "metadata": {"name": "cluster", "uid": "1234", "labels": {"team": "platform"}}, "spec": {"type": "IntegratedOAuth"}}`
+)
+
+func runAPIServerConfigTests(t *testing.T, tests []apiServerConfigTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   operatorGroup,
+			Version: "v1",
+			Kind:    test.kind,
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    operatorGroup,
+			Version:  "v1",
+			Resource: "kubeapiservers",
+		}
+		if test.kind == authenticationKind {
+			gvk.Group = configGroup
+			gvr.Group = configGroup
+			gvr.Resource = "authentications"
+		}
+		obj := runtime.RawExtension{Raw: []byte(test.newObject)}
+		oldObj := runtime.RawExtension{Raw: []byte(test.oldObject)}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s update the %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.kind, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []apiServerConfigTestSuites{
+		{
+			testID:          "user-cant-alter-authorization-mode",
+			kind:            kubeAPIServerKind,
+			oldObject:       defaultKubeAPIServerRaw,
+			newObject:       alwaysAllowKubeAPIServerRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-disable-admission-webhooks",
+			kind:            kubeAPIServerKind,
+			oldObject:       defaultKubeAPIServerRaw,
+			newObject:       noPluginsKubeAPIServerRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-set-webhook-token-authenticator",
+			kind:            authenticationKind,
+			oldObject:       defaultAuthenticationRaw,
+			newObject:       webhookAuthenticationRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runAPIServerConfigTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []apiServerConfigTestSuites{
+		{
+			testID:          "user-can-change-log-level",
+			kind:            kubeAPIServerKind,
+			oldObject:       defaultKubeAPIServerRaw,
+			newObject:       debugKubeAPIServerRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-label-authentication",
+			kind:            authenticationKind,
+			oldObject:       defaultAuthenticationRaw,
+			newObject:       labelledAuthenticationRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-leave-existing-authorization-mode",
+			kind:            kubeAPIServerKind,
+			oldObject:       alwaysAllowKubeAPIServerRaw,
+			newObject:       alwaysAllowKubeAPIServerRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "kube-apiserver-operator-can-alter-authorization-mode",
+			kind:            kubeAPIServerKind,
+			oldObject:       defaultKubeAPIServerRaw,
+			newObject:       alwaysAllowKubeAPIServerRaw,
+			username:        "system:serviceaccount:openshift-kube-apiserver-operator:kube-apiserver-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-kube-apiserver-operator"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-set-webhook-token-authenticator",
+			kind:            authenticationKind,
+			oldObject:       defaultAuthenticationRaw,
+			newObject:       webhookAuthenticationRaw,
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runAPIServerConfigTests(t, tests)
+}
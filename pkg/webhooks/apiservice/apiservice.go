@@ -0,0 +1,244 @@
+package apiservice
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName     string = "apiservice-validation"
+	docString       string = `Managed OpenShift Customers may not update or delete the following managed APIServices, which serve aggregated APIs of the platform: %s`
+	apiServiceKind  string = "APIService"
+	apiRegistration string = "apiregistration.k8s.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{apiRegistration},
+				APIVersions: []string{"*"},
+				Resources:   []string{"apiservices"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		// The operators owning the managed APIServices
+		"system:serviceaccount:openshift-apiserver-operator:openshift-apiserver-operator",
+		"system:serviceaccount:openshift-authentication-operator:authentication-operator",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-operator-lifecycle-manager:olm-operator-serviceaccount",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedAPIServices is the inventory of managed APIServices. Each
+	// registers an aggregated API, which breaks when it is edited or deleted.
+	managedAPIServices = []string{
+		"v1.apps.openshift.io",
+		"v1.authorization.openshift.io",
+		"v1.build.openshift.io",
+		"v1.image.openshift.io",
+		"v1.oauth.openshift.io",
+		"v1.packages.operators.coreos.com",
+		"v1.project.openshift.io",
+		"v1.quota.openshift.io",
+		"v1.route.openshift.io",
+		"v1.security.openshift.io",
+		"v1.template.openshift.io",
+		"v1.user.openshift.io",
+		"v1beta1.metrics.k8s.io",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedAPIServices)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedAPIServices = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedAPIServices": managedAPIServices,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// APIServiceWebhook protects the APIServices registering the aggregated APIs
+// of the platform
+type APIServiceWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *APIServiceWebhook {
+	return &APIServiceWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *APIServiceWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *APIServiceWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	apiService, err := s.renderAPIService(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an APIService from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if utils.SliceContains(apiService.GetName(), managedAPIServices) {
+		log.Info(fmt.Sprintf("%s operation detected on managed APIService: %v", request.Operation, apiService.GetName()))
+		ret = admissionctl.Denied(fmt.Sprintf("Updating or deleting managed APIService %v is not allowed, as it would break the API it registers", apiService.GetName()))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderAPIService decodes the existing APIService, which both UPDATE and
+// DELETE carry as the OldObject. The apiregistration API isn't vendored, so
+// it is decoded generically.
+func (s *APIServiceWebhook) renderAPIService(request admissionctl.Request) (*unstructured.Unstructured, error) {
+	_, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &unstructured.Unstructured{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if oldObj == nil {
+		return nil, fmt.Errorf("APIService %s request is missing the existing object", request.Operation)
+	}
+	return oldObj.(*unstructured.Unstructured), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *APIServiceWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *APIServiceWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && utils.KindMatches(request, apiRegistration, apiServiceKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *APIServiceWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *APIServiceWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *APIServiceWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *APIServiceWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *APIServiceWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *APIServiceWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *APIServiceWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *APIServiceWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedAPIServices)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *APIServiceWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
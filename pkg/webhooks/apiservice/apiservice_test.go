@@ -0,0 +1,163 @@
+package apiservice
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type apiServiceTestSuites struct {
+	testID          string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "apiregistration.k8s.io/v1",
+	"kind": "APIService",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"service": {"namespace": "openshift-apiserver", "name": "api"},
+		"groupPriorityMinimum": 9900,
+		"versionPriority": %d
+	}
+}`
+
+func runAPIServiceTests(t *testing.T, tests []apiServiceTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "apiregistration.k8s.io",
+		Version: "v1",
+		Kind:    "APIService",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "apiregistration.k8s.io",
+		Version:  "v1",
+		Resource: "apiservices",
+	}
+
+	for _, test := range tests {
+		// The object of a DELETE is sent as the OldObject
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, 20)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, 15)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the APIService %s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []apiServiceTestSuites{
+		{
+			testID:          "user-cant-delete-managed-apiservice",
+			targetName:      "v1.route.openshift.io",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-update-managed-apiservice",
+			targetName:      "v1beta1.metrics.k8s.io",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runAPIServiceTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []apiServiceTestSuites{
+		{
+			testID:          "user-can-delete-customer-apiservice",
+			targetName:      "v1alpha1.custom-metrics.example.com",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-update-customer-apiservice",
+			targetName:      "v1alpha1.custom-metrics.example.com",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "apiserver-operator-can-update-managed-apiservice",
+			targetName:      "v1.route.openshift.io",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-apiserver-operator:openshift-apiserver-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-apiserver-operator"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-delete-managed-apiservice",
+			targetName:      "v1.route.openshift.io",
+			operation:       admissionv1.Delete,
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runAPIServiceTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedAPIServices
+	defer func() { managedAPIServices = oldInventory }()
+	managedAPIServices = []string{"v1beta1.custom.metrics.k8s.io"}
+
+	tests := []apiServiceTestSuites{
+		{
+			testID:          "user-cant-delete-configured-apiservice",
+			targetName:      "v1beta1.custom.metrics.k8s.io",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-apiservice",
+			targetName:      "v1.route.openshift.io",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runAPIServiceTests(t, tests)
+}
@@ -0,0 +1,29 @@
+package webhooks
+
+import (
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+)
+
+// ServiceConfig locates the Service fronting the webhook server
+type ServiceConfig struct {
+	Namespace string
+	Name      string
+	Port      int32
+}
+
+// ClientConfig returns the ClientConfig under which the API server reaches
+// the hook. The path is always derived from GetURI, which is also what the
+// server registers the hook's handler under, so the generated configuration
+// can't point at a path the server doesn't serve.
+func ClientConfig(hook Webhook, service ServiceConfig) admissionregv1.WebhookClientConfig {
+	path := hook.GetURI()
+	port := service.Port
+	return admissionregv1.WebhookClientConfig{
+		Service: &admissionregv1.ServiceReference{
+			Namespace: service.Namespace,
+			Name:      service.Name,
+			Path:      &path,
+			Port:      &port,
+		},
+	}
+}
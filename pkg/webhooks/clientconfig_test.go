@@ -0,0 +1,41 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/dispatcher"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+)
+
+func TestClientConfigPathIsServed(t *testing.T) {
+	service := webhooks.ServiceConfig{Namespace: "openshift-validation-webhook", Name: "validation-webhook", Port: 443}
+
+	// Register the handlers the same way main does
+	d := dispatcher.NewDispatcher(webhooks.Webhooks)
+	mux := http.NewServeMux()
+	for _, hook := range webhooks.Webhooks {
+		mux.HandleFunc(hook().GetURI(), d.HandleRequest)
+	}
+
+	for name, hook := range webhooks.Webhooks {
+		clientConfig := webhooks.ClientConfig(hook(), service)
+		if clientConfig.Service == nil || clientConfig.Service.Path == nil {
+			t.Fatalf("%s: expected a service reference with a path", name)
+		}
+		path := *clientConfig.Service.Path
+		if !strings.HasPrefix(path, "/") {
+			t.Fatalf("%s: expected an absolute path, got %q", name, path)
+		}
+		if *clientConfig.Service.Port != service.Port {
+			t.Fatalf("%s: expected port %d, got %d", name, service.Port, *clientConfig.Service.Port)
+		}
+
+		_, pattern := mux.Handler(httptest.NewRequest(http.MethodPost, path, nil))
+		if pattern != path {
+			t.Fatalf("%s: expected the configured path %q to be served by its own handler, got pattern %q", name, path, pattern)
+		}
+	}
+}
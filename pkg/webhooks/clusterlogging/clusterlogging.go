@@ -7,7 +7,9 @@ import (
 	"strconv"
 
 	cl "github.com/openshift/cluster-logging-operator/pkg/apis/logging/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	utils "github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -18,7 +20,7 @@ import (
 const (
 	ClusterLoggingKind string = "ClusterLogging"
 	WebhookName        string = "clusterlogging-validation"
-	docString          string = `Managed OpenShift Customers may set log retention outside the allowed range of 0-7 days`
+	docString          string = `Managed OpenShift Customers may set log retention outside the allowed range of 0-7 days. They may not set the following managed ClusterLoggings to Unmanaged, nor remove their log collection or store: %s`
 )
 
 var (
@@ -37,17 +39,40 @@ var (
 			},
 		},
 	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-logging:cluster-logging-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedClusterLoggings is the inventory of managed ClusterLoggings, in
+	// the form of namespace/name
+	managedClusterLoggings = []string{
+		"openshift-logging/instance",
+	}
+	// managedComponents are the components of a managed ClusterLogging the log
+	// pipeline relies on, which can't be removed once present
+	managedComponents = []struct {
+		name    string
+		present func(*cl.ClusterLogging) bool
+	}{
+		{"collection", func(c *cl.ClusterLogging) bool { return c.Spec.Collection != nil && c.Spec.Collection.Logs.Type != "" }},
+		{"logStore", func(c *cl.ClusterLogging) bool { return c.Spec.LogStore != nil && c.Spec.LogStore.Type != "" }},
+	}
 )
 
 type ClusterloggingWebhook struct {
-	s runtime.Scheme
+	s *runtime.Scheme
 }
 
 // ObjectSelector implements Webhook interface
 func (s *ClusterloggingWebhook) ObjectSelector() *metav1.LabelSelector { return nil }
 
 func (s *ClusterloggingWebhook) Doc() string {
-	return docString
+	return fmt.Sprintf(docString, managedClusterLoggings)
 }
 
 // TimeoutSeconds implements Webhook interface
@@ -139,6 +164,12 @@ func (s *ClusterloggingWebhook) Authorized(request admissionctl.Request) admissi
 }
 
 func (s *ClusterloggingWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	if request.Operation == admissionv1.Update && !isAllowedUserGroup(request) {
+		if ret, disruptive := s.checkDisruptiveUpdate(request); disruptive {
+			return ret
+		}
+	}
+
 	clusterLogging, err := s.renderClusterLogging(request)
 	if err != nil {
 		return admissionctl.Errored(http.StatusBadRequest, err)
@@ -182,11 +213,60 @@ func (s *ClusterloggingWebhook) authorized(request admissionctl.Request) admissi
 	return ret
 }
 
+// checkDisruptiveUpdate checks if the update of a managed ClusterLogging
+// would break the log pipeline, by setting it to Unmanaged or by removing one
+// of its managedComponents. It returns the response to send when it does.
+func (s *ClusterloggingWebhook) checkDisruptiveUpdate(request admissionctl.Request) (admissionctl.Response, bool) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &cl.ClusterLogging{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		log.Error(err, "Couldn't render a ClusterLogging from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err), true
+	}
+	if newObj == nil || oldObj == nil {
+		return admissionctl.Response{}, false
+	}
+	newClusterLogging := newObj.(*cl.ClusterLogging)
+	oldClusterLogging := oldObj.(*cl.ClusterLogging)
+
+	name := oldClusterLogging.GetNamespace() + "/" + oldClusterLogging.GetName()
+	if !utils.SliceContains(name, managedClusterLoggings) {
+		return admissionctl.Response{}, false
+	}
+
+	if newClusterLogging.Spec.ManagementState == cl.ManagementStateUnmanaged && oldClusterLogging.Spec.ManagementState != cl.ManagementStateUnmanaged {
+		log.Info(fmt.Sprintf("Unmanaged managementState detected on managed ClusterLogging: %v", name))
+		return admissionctl.Denied(fmt.Sprintf("Setting managed ClusterLogging %v to Unmanaged is not allowed", name)), true
+	}
+	for _, component := range managedComponents {
+		if component.present(oldClusterLogging) && !component.present(newClusterLogging) {
+			log.Info(fmt.Sprintf("Removal of %s detected on managed ClusterLogging: %v", component.name, name))
+			return admissionctl.Denied(fmt.Sprintf("Removing the %s of managed ClusterLogging %v is not allowed", component.name, name)), true
+		}
+	}
+	return admissionctl.Response{}, false
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
 // renderClusterLogging decodes an *cl.ClusterLogging from the incoming request.
 // If the request includes an OldObject (from an update or deletion), it will be
 // preferred, otherwise, the Object will be preferred.
 func (s *ClusterloggingWebhook) renderClusterLogging(request admissionctl.Request) (*cl.ClusterLogging, error) {
-	decoder, err := admissionctl.NewDecoder(&s.s)
+	decoder, err := admissionctl.NewDecoder(s.s)
 	if err != nil {
 		return nil, err
 	}
@@ -197,6 +277,7 @@ func (s *ClusterloggingWebhook) renderClusterLogging(request admissionctl.Reques
 		err = decoder.DecodeRaw(request.Object, clusterLogging)
 	}
 	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
 		return nil, err
 	}
 	return clusterLogging, nil
@@ -282,10 +363,7 @@ func (s *ClusterloggingWebhook) SyncSetLabelSelector() metav1.LabelSelector {
 
 // NewWebhook creates a new webhook
 func NewWebhook() *ClusterloggingWebhook {
-	scheme := runtime.NewScheme()
-	cl.AddToScheme(scheme)
-
 	return &ClusterloggingWebhook{
-		s: *scheme,
+		s: utils.Scheme,
 	}
 }
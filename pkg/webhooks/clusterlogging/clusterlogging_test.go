@@ -149,3 +149,174 @@ func runTests(t *testing.T, tests []clusterloggingTestSuite) {
 		}
 	}
 }
+
+const managedObjectRaw string = `
+{
+	"apiVersion": "logging.openshift.io/v1",
+	"kind": "ClusterLogging",
+	"metadata": {
+		"name": "instance",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"managementState": "%s",
+		%s
+		"logStore": {
+			"type": "elasticsearch",
+			"retentionPolicy": {
+				"application": {
+					"maxAge": "%s"
+				},
+				"infra": {
+					"maxAge": "1h"
+				},
+				"audit": {
+					"maxAge": "1h"
+				}
+			}
+		}
+	}
+}`
+
+const collectionRaw string = `"collection": {
+			"logs": {
+				"type": "fluentd",
+				"fluentd": {}
+			}
+		},`
+
+type managedClusterLoggingTestSuite struct {
+	testID          string
+	namespace       string
+	username        string
+	userGroups      []string
+	oldState        string
+	newState        string
+	oldCollection   string
+	newCollection   string
+	oldAppMaxAge    string
+	newAppMaxAge    string
+	shouldBeAllowed bool
+}
+
+func runManagedTests(t *testing.T, tests []managedClusterLoggingTestSuite) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "logging.openshift.io",
+		Version: "v1",
+		Kind:    "ClusterLogging",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "logging.openshift.io",
+		Version:  "v1",
+		Resource: "clusterloggings",
+	}
+
+	for _, test := range tests {
+		obj := &runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(managedObjectRaw, test.namespace, test.newState, test.newCollection, test.newAppMaxAge)),
+		}
+		oldObj := &runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(managedObjectRaw, test.namespace, test.oldState, test.oldCollection, test.oldAppMaxAge)),
+		}
+		hook := clusterlogging.NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, obj, oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response: %+v", response)
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s %s update the ClusterLogging %s/instance in test %s. Test's expectation is that the user %s. Reason: %s, Message: %v",
+				test.username, testutils.CanCanNot(response.Allowed), test.namespace, test.testID,
+				testutils.CanCanNot(test.shouldBeAllowed), response.Result.Reason, response.Result.Message)
+		}
+	}
+}
+
+func Test_DisruptiveUpdateNotAllowed(t *testing.T) {
+	testSuites := []managedClusterLoggingTestSuite{
+		{
+			testID:          "user-cant-set-unmanaged",
+			namespace:       "openshift-logging",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			oldState:        "Managed",
+			newState:        "Unmanaged",
+			oldCollection:   collectionRaw,
+			newCollection:   collectionRaw,
+			oldAppMaxAge:    "7d",
+			newAppMaxAge:    "7d",
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-remove-collection",
+			namespace:       "openshift-logging",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			oldState:        "Managed",
+			newState:        "Managed",
+			oldCollection:   collectionRaw,
+			newCollection:   "",
+			oldAppMaxAge:    "7d",
+			newAppMaxAge:    "7d",
+			shouldBeAllowed: false,
+		},
+	}
+
+	runManagedTests(t, testSuites)
+}
+
+func Test_DisruptiveUpdateAllowed(t *testing.T) {
+	testSuites := []managedClusterLoggingTestSuite{
+		{
+			testID:          "user-can-tune-retention",
+			namespace:       "openshift-logging",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			oldState:        "Managed",
+			newState:        "Managed",
+			oldCollection:   collectionRaw,
+			newCollection:   collectionRaw,
+			oldAppMaxAge:    "7d",
+			newAppMaxAge:    "3d",
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "logging-operator-can-set-unmanaged",
+			namespace:       "openshift-logging",
+			username:        "system:serviceaccount:openshift-logging:cluster-logging-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-logging"},
+			oldState:        "Managed",
+			newState:        "Unmanaged",
+			oldCollection:   collectionRaw,
+			newCollection:   collectionRaw,
+			oldAppMaxAge:    "7d",
+			newAppMaxAge:    "7d",
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-set-unmanaged-outside-managed-namespace",
+			namespace:       "my-logging",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			oldState:        "Managed",
+			newState:        "Unmanaged",
+			oldCollection:   collectionRaw,
+			newCollection:   "",
+			oldAppMaxAge:    "7d",
+			newAppMaxAge:    "7d",
+			shouldBeAllowed: true,
+		},
+	}
+
+	runManagedTests(t, testSuites)
+}
@@ -0,0 +1,235 @@
+package clusterresourcequota
+
+import (
+	"fmt"
+	"net/http"
+
+	quotav1 "github.com/openshift/api/quota/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "clusterresourcequota-validation"
+	docString   string = `Managed OpenShift Customers may not modify or delete the following managed ClusterResourceQuotas: %s`
+	quotaKind   string = "ClusterResourceQuota"
+	quotaGroup  string = "quota.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{quotaGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"clusterresourcequotas"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-resource-quota-operator:resource-quota-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedClusterResourceQuotas is the inventory of managed
+	// ClusterResourceQuotas
+	managedClusterResourceQuotas = []string{
+		"managed-tenant-quota",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedClusterResourceQuotas)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedClusterResourceQuotas = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedClusterResourceQuotas": managedClusterResourceQuotas,
+		"allowedUsers":                 allowedUsers,
+		"allowedGroups":                allowedGroups,
+	}
+}
+
+// ClusterResourceQuotaWebhook protects managed ClusterResourceQuotas
+type ClusterResourceQuotaWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *ClusterResourceQuotaWebhook {
+	return &ClusterResourceQuotaWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *ClusterResourceQuotaWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	quota, err := s.renderClusterResourceQuota(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a ClusterResourceQuota from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if utils.SliceContains(quota.Name, managedClusterResourceQuotas) && !isAllowedUserGroup(request) {
+		switch request.Operation {
+		case admissionv1.Delete:
+			log.Info(fmt.Sprintf("Deleting operation detected on managed ClusterResourceQuota: %v", quota.Name))
+			ret = admissionctl.Denied(fmt.Sprintf("Deleting managed ClusterResourceQuota %v is not allowed", quota.Name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		case admissionv1.Update:
+			log.Info(fmt.Sprintf("Updating operation detected on managed ClusterResourceQuota: %v", quota.Name))
+			ret = admissionctl.Denied(fmt.Sprintf("Modifying managed ClusterResourceQuota %v is not allowed", quota.Name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderClusterResourceQuota renders the existing ClusterResourceQuota from
+// the request
+func (s *ClusterResourceQuotaWebhook) renderClusterResourceQuota(request admissionctl.Request) (*quotav1.ClusterResourceQuota, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	quota := &quotav1.ClusterResourceQuota{}
+
+	if len(request.OldObject.Raw) > 0 {
+		err = decoder.DecodeRaw(request.OldObject, quota)
+	}
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+
+	return quota, nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == quotaKind)
+	valid = valid && (request.Kind.Group == quotaGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *ClusterResourceQuotaWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedClusterResourceQuotas)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *ClusterResourceQuotaWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
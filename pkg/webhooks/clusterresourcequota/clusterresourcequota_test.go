@@ -0,0 +1,165 @@
+package clusterresourcequota
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type clusterResourceQuotaTestSuites struct {
+	testID          string
+	targetQuota     string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "quota.openshift.io/v1",
+	"kind": "ClusterResourceQuota",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"quota": {
+			"hard": {
+				"pods": "10"
+			}
+		},
+		"selector": {
+			"labels": {
+				"matchLabels": {
+					"tenant": "customer"
+				}
+			}
+		}
+	}
+}`
+
+func runClusterResourceQuotaTests(t *testing.T, tests []clusterResourceQuotaTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "quota.openshift.io",
+		Version: "v1",
+		Kind:    "ClusterResourceQuota",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "quota.openshift.io",
+		Version:  "v1",
+		Resource: "clusterresourcequotas",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetQuota)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s ClusterResourceQuota. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetQuota, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []clusterResourceQuotaTestSuites{
+		{
+			testID:          "user-cant-delete-managed-quota",
+			targetQuota:     "managed-tenant-quota",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-quota",
+			targetQuota:     "managed-tenant-quota",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runClusterResourceQuotaTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []clusterResourceQuotaTestSuites{
+		{
+			testID:          "user-can-create-new-quota",
+			targetQuota:     "customer-quota",
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-quota",
+			targetQuota:     "customer-quota",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "operator-can-modify-managed-quota",
+			targetQuota:     "managed-tenant-quota",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-resource-quota-operator:resource-quota-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-resource-quota-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runClusterResourceQuotaTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedClusterResourceQuotas
+	defer func() { managedClusterResourceQuotas = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"platform-quota"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []clusterResourceQuotaTestSuites{
+		{
+			testID:          "user-cant-delete-configured-quota",
+			targetQuota:     "platform-quota",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-quota",
+			targetQuota:     "managed-tenant-quota",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runClusterResourceQuotaTests(t, tests)
+}
@@ -0,0 +1,249 @@
+package clusterrolebinding
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName            string = "clusterrolebinding-validation"
+	docString              string = `Managed OpenShift Customers may not delete the following managed ClusterRoleBindings, nor change the role they refer to: %s`
+	clusterRoleBindingKind string = "ClusterRoleBinding"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{rbacv1.GroupName},
+				APIVersions: []string{"*"},
+				Resources:   []string{"clusterrolebindings"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:kube-system:generic-garbage-collector",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedClusterRoleBindings is the inventory of managed
+	// ClusterRoleBindings, which the platform components depend on
+	managedClusterRoleBindings = []string{
+		"cluster-monitoring-operator",
+		"prometheus-k8s",
+		"prometheus-operator",
+		"cluster-version-operator",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedClusterRoleBindings)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedClusterRoleBindings = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedClusterRoleBindings": managedClusterRoleBindings,
+		"allowedUsers":               allowedUsers,
+		"allowedGroups":              allowedGroups,
+	}
+}
+
+// ClusterRoleBindingWebhook protects the existence and the role of the
+// ClusterRoleBindings the platform components depend on
+type ClusterRoleBindingWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *ClusterRoleBindingWebhook {
+	return &ClusterRoleBindingWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *ClusterRoleBindingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *ClusterRoleBindingWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newBinding, oldBinding, err := s.renderClusterRoleBindings(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a ClusterRoleBinding from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if utils.SliceContains(oldBinding.Name, managedClusterRoleBindings) {
+		switch request.Operation {
+		case admissionv1.Delete:
+			log.Info(fmt.Sprintf("Deleting operation detected on managed ClusterRoleBinding: %v", oldBinding.Name))
+			ret = admissionctl.Denied(fmt.Sprintf("Deleting managed ClusterRoleBinding %v is not allowed", oldBinding.Name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		case admissionv1.Update:
+			if newBinding.RoleRef != oldBinding.RoleRef {
+				log.Info(fmt.Sprintf("RoleRef change detected on managed ClusterRoleBinding: %v", oldBinding.Name))
+				ret = admissionctl.Denied(fmt.Sprintf("Changing the role managed ClusterRoleBinding %v refers to is not allowed", oldBinding.Name))
+				ret.UID = request.AdmissionRequest.UID
+				return ret
+			}
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderClusterRoleBindings decodes both the Object and OldObject of the
+// request. The Object is nil on DELETE. Return order is: new, old, error.
+func (s *ClusterRoleBindingWebhook) renderClusterRoleBindings(request admissionctl.Request) (*rbacv1.ClusterRoleBinding, *rbacv1.ClusterRoleBinding, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &rbacv1.ClusterRoleBinding{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if oldObj == nil {
+		return nil, nil, fmt.Errorf("ClusterRoleBinding %s request is missing the existing object", request.Operation)
+	}
+	if request.Operation == admissionv1.Update && newObj == nil {
+		return nil, nil, fmt.Errorf("ClusterRoleBinding %s request is missing an object", request.Operation)
+	}
+
+	var newBinding *rbacv1.ClusterRoleBinding
+	if newObj != nil {
+		newBinding = newObj.(*rbacv1.ClusterRoleBinding)
+	}
+	return newBinding, oldObj.(*rbacv1.ClusterRoleBinding), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *ClusterRoleBindingWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *ClusterRoleBindingWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == clusterRoleBindingKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *ClusterRoleBindingWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *ClusterRoleBindingWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *ClusterRoleBindingWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *ClusterRoleBindingWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *ClusterRoleBindingWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *ClusterRoleBindingWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *ClusterRoleBindingWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *ClusterRoleBindingWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedClusterRoleBindings)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *ClusterRoleBindingWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
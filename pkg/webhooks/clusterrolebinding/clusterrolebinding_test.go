@@ -0,0 +1,185 @@
+package clusterrolebinding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type clusterRoleBindingTestSuites struct {
+	testID          string
+	targetName      string
+	operation       admissionv1.Operation
+	oldRole         string
+	newRole         string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "rbac.authorization.k8s.io/v1",
+	"kind": "ClusterRoleBinding",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	},
+	"roleRef": {
+		"apiGroup": "rbac.authorization.k8s.io",
+		"kind": "ClusterRole",
+		"name": "%s"
+	},
+	"subjects": [
+		{
+			"kind": "ServiceAccount",
+			"name": "cluster-monitoring-operator",
+			"namespace": "openshift-monitoring"
+		}
+	]
+}`
+
+func runClusterRoleBindingTests(t *testing.T, tests []clusterRoleBindingTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "rbac.authorization.k8s.io",
+		Version: "v1",
+		Kind:    "ClusterRoleBinding",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "clusterrolebindings",
+	}
+
+	for _, test := range tests {
+		newRole := test.newRole
+		if newRole == "" {
+			newRole = test.oldRole
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, newRole)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.oldRole)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the ClusterRoleBinding %s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []clusterRoleBindingTestSuites{
+		{
+			testID:          "user-cant-delete-managed-crb",
+			targetName:      "cluster-monitoring-operator",
+			operation:       admissionv1.Delete,
+			oldRole:         "cluster-monitoring-operator",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-change-roleref-of-managed-crb",
+			targetName:      "prometheus-k8s",
+			operation:       admissionv1.Update,
+			oldRole:         "prometheus-k8s",
+			newRole:         "view",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runClusterRoleBindingTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []clusterRoleBindingTestSuites{
+		{
+			testID:          "user-can-edit-customer-crb",
+			targetName:      "my-binding",
+			operation:       admissionv1.Update,
+			oldRole:         "view",
+			newRole:         "edit",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-crb",
+			targetName:      "my-binding",
+			operation:       admissionv1.Delete,
+			oldRole:         "view",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-update-managed-crb-keeping-roleref",
+			targetName:      "prometheus-k8s",
+			operation:       admissionv1.Update,
+			oldRole:         "prometheus-k8s",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "cluster-version-operator-can-delete-managed-crb",
+			targetName:      "cluster-monitoring-operator",
+			operation:       admissionv1.Delete,
+			oldRole:         "cluster-monitoring-operator",
+			username:        "system:serviceaccount:openshift-cluster-version:default",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-version"},
+			shouldBeAllowed: true,
+		},
+	}
+	runClusterRoleBindingTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedClusterRoleBindings
+	defer func() { managedClusterRoleBindings = oldInventory }()
+	managedClusterRoleBindings = []string{"logging-collector"}
+
+	tests := []clusterRoleBindingTestSuites{
+		{
+			testID:          "user-cant-delete-configured-crb",
+			targetName:      "logging-collector",
+			operation:       admissionv1.Delete,
+			oldRole:         "logging-collector",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-crb",
+			targetName:      "cluster-monitoring-operator",
+			operation:       admissionv1.Delete,
+			oldRole:         "cluster-monitoring-operator",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runClusterRoleBindingTests(t, tests)
+}
@@ -0,0 +1,334 @@
+package configmap
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName   string = "configmap-validation"
+	docString     string = `Managed OpenShift Customers may not edit the following managed ConfigMaps, or their listed keys beyond the allowed values: %s`
+	configMapKind string = "ConfigMap"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"configmaps"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-managed-upgrade-operator:managed-upgrade-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedConfigMaps is the inventory of managed ConfigMaps. Each entry is
+	// either namespace/name, which protects the whole data of the ConfigMap,
+	// namespace/name:key, which protects a single key, or
+	// namespace/name:key=value1,value2, which only lets the key be set to one
+	// of the values.
+	managedConfigMaps = []string{
+		"openshift-managed-upgrade-operator/managed-upgrade-operator-config",
+		"openshift-monitoring/cluster-monitoring-config:config.yaml",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedConfigMaps)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	if settings.Protected != nil {
+		if _, err := parseInventory(settings.Protected); err != nil {
+			return nil, err
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedConfigMaps = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedConfigMaps": managedConfigMaps,
+		"allowedUsers":      allowedUsers,
+		"allowedGroups":     allowedGroups,
+	}
+}
+
+// configMapPolicy is how a managed ConfigMap is protected
+type configMapPolicy struct {
+	// wholeData is set when no change to the data is allowed
+	wholeData bool
+	// keys maps each protected key to the values it may be set to. A key
+	// mapped to no values may not change at all.
+	keys map[string][]string
+}
+
+// parseInventory parses the entries of managedConfigMaps into the policy of
+// each ConfigMap, by namespace/name. Entries for the same ConfigMap add up.
+func parseInventory(inventory []string) (map[string]*configMapPolicy, error) {
+	policies := map[string]*configMapPolicy{}
+	for _, entry := range inventory {
+		name, key, hasKey := entry, "", false
+		if i := strings.Index(entry, ":"); i >= 0 {
+			name, key, hasKey = entry[:i], entry[i+1:], true
+		}
+		if parts := strings.Split(name, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("managed ConfigMap %q must be in the form namespace/name", entry)
+		}
+		policy, ok := policies[name]
+		if !ok {
+			policy = &configMapPolicy{keys: map[string][]string{}}
+			policies[name] = policy
+		}
+		if !hasKey {
+			policy.wholeData = true
+			continue
+		}
+		var values []string
+		if i := strings.Index(key, "="); i >= 0 {
+			key, values = key[:i], strings.Split(key[i+1:], ",")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("managed ConfigMap %q has no key", entry)
+		}
+		policy.keys[key] = append(policy.keys[key], values...)
+	}
+	return policies, nil
+}
+
+// ConfigMapWebhook protects the ConfigMaps operators read their
+// configuration from
+type ConfigMapWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *ConfigMapWebhook {
+	return &ConfigMapWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *ConfigMapWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *ConfigMapWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newConfigMap, oldConfigMap, err := s.renderOldAndNewConfigMaps(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a ConfigMap from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	policies, err := parseInventory(managedConfigMaps)
+	if err != nil {
+		log.Error(err, "Couldn't parse the inventory of managed ConfigMaps")
+		return admissionctl.Errored(http.StatusInternalServerError, err)
+	}
+
+	name := oldConfigMap.Namespace + "/" + oldConfigMap.Name
+	if policy, ok := policies[name]; ok {
+		if message := checkData(policy, oldConfigMap, newConfigMap); message != "" {
+			log.Info(fmt.Sprintf("Edit of managed ConfigMap detected: %s", name))
+			ret = admissionctl.Denied(fmt.Sprintf("%s of managed ConfigMap %s is not allowed", message, name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// checkData describes the first change from oldConfigMap to newConfigMap
+// which the policy doesn't allow, or returns an empty string
+func checkData(policy *configMapPolicy, oldConfigMap, newConfigMap *corev1.ConfigMap) string {
+	if policy.wholeData {
+		if !sameData(oldConfigMap.Data, newConfigMap.Data) || !sameBinaryData(oldConfigMap.BinaryData, newConfigMap.BinaryData) {
+			return "Changing the data"
+		}
+		return ""
+	}
+
+	keys := make([]string, 0, len(policy.keys))
+	for key := range policy.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		oldValue, oldOK := oldConfigMap.Data[key]
+		newValue, newOK := newConfigMap.Data[key]
+		if oldValue == newValue && oldOK == newOK {
+			continue
+		}
+		allowedValues := policy.keys[key]
+		switch {
+		case !newOK:
+			return fmt.Sprintf("Removing key %s", key)
+		case len(allowedValues) == 0:
+			return fmt.Sprintf("Changing key %s", key)
+		case !utils.SliceContains(newValue, allowedValues):
+			return fmt.Sprintf("Setting key %s to %q instead of one of %v", key, newValue, allowedValues)
+		}
+	}
+	return ""
+}
+
+// sameData compares data, a missing map being the same as an empty one
+func sameData(a, b map[string]string) bool {
+	return (len(a) == 0 && len(b) == 0) || reflect.DeepEqual(a, b)
+}
+
+// sameBinaryData compares binary data, a missing map being the same as an
+// empty one
+func sameBinaryData(a, b map[string][]byte) bool {
+	return (len(a) == 0 && len(b) == 0) || reflect.DeepEqual(a, b)
+}
+
+// renderOldAndNewConfigMaps decodes both the Object and OldObject of the
+// UPDATE request
+func (s *ConfigMapWebhook) renderOldAndNewConfigMaps(request admissionctl.Request) (*corev1.ConfigMap, *corev1.ConfigMap, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.ConfigMap{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("ConfigMap UPDATE request is missing an object")
+	}
+	return newObj.(*corev1.ConfigMap), oldObj.(*corev1.ConfigMap), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *ConfigMapWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *ConfigMapWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == configMapKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *ConfigMapWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *ConfigMapWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *ConfigMapWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *ConfigMapWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *ConfigMapWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *ConfigMapWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *ConfigMapWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *ConfigMapWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedConfigMaps)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *ConfigMapWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,210 @@
+package configmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type configMapTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldData         string
+	newData         string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "ConfigMap",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"data": {%s}
+}`
+
+func runConfigMapTests(t *testing.T, tests []configMapTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "ConfigMap",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "configmaps",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.newData)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.oldData)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s update the ConfigMap %s/%s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []configMapTestSuites{
+		{
+			testID:          "user-cant-edit-protected-key",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "cluster-monitoring-config",
+			oldData:         `"config.yaml": "enableUserWorkload: true"`,
+			newData:         `"config.yaml": "enableUserWorkload: false"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-remove-protected-key",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "cluster-monitoring-config",
+			oldData:         `"config.yaml": "enableUserWorkload: true"`,
+			newData:         ``,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-add-key-to-protected-configmap",
+			targetNamespace: "openshift-managed-upgrade-operator",
+			targetName:      "managed-upgrade-operator-config",
+			oldData:         `"config.yaml": "upgradeType: OSD"`,
+			newData:         `"config.yaml": "upgradeType: OSD", "extra": "value"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runConfigMapTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []configMapTestSuites{
+		{
+			testID:          "user-can-edit-unmanaged-configmap",
+			targetNamespace: "my-project",
+			targetName:      "cluster-monitoring-config",
+			oldData:         `"config.yaml": "enableUserWorkload: true"`,
+			newData:         `"config.yaml": "enableUserWorkload: false"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-edit-unprotected-key",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "cluster-monitoring-config",
+			oldData:         `"config.yaml": "enableUserWorkload: true"`,
+			newData:         `"config.yaml": "enableUserWorkload: true", "notes": "checked"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "operator-can-edit-protected-key",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "cluster-monitoring-config",
+			oldData:         `"config.yaml": "enableUserWorkload: true"`,
+			newData:         `"config.yaml": "enableUserWorkload: false"`,
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "sre-can-edit-protected-configmap",
+			targetNamespace: "openshift-managed-upgrade-operator",
+			targetName:      "managed-upgrade-operator-config",
+			oldData:         `"config.yaml": "upgradeType: OSD"`,
+			newData:         `"config.yaml": "upgradeType: ARO"`,
+			username:        "backplane-cluster-admin",
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: true,
+		},
+	}
+	runConfigMapTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedConfigMaps
+	defer func() { managedConfigMaps = oldInventory }()
+	managedConfigMaps = []string{"openshift-logging/collector-config:level=info,debug"}
+
+	tests := []configMapTestSuites{
+		{
+			testID:          "user-can-set-key-to-allowed-value",
+			targetNamespace: "openshift-logging",
+			targetName:      "collector-config",
+			oldData:         `"level": "info"`,
+			newData:         `"level": "debug"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-cant-set-key-to-other-value",
+			targetNamespace: "openshift-logging",
+			targetName:      "collector-config",
+			oldData:         `"level": "info"`,
+			newData:         `"level": "trace"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-edit-unconfigured-configmap",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "cluster-monitoring-config",
+			oldData:         `"config.yaml": "enableUserWorkload: true"`,
+			newData:         `"config.yaml": "enableUserWorkload: false"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runConfigMapTests(t, tests)
+}
+
+func TestInvalidInventory(t *testing.T) {
+	for _, inventory := range [][]string{
+		{"cluster-monitoring-config"},
+		{"openshift-monitoring/"},
+		{"openshift-monitoring/cluster-monitoring-config:=true"},
+	} {
+		if _, err := applySettings(config.WebhookSettings{Protected: inventory}); err == nil {
+			t.Fatalf("Expected inventory %v to be rejected", inventory)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package console
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName  string = "console-validation"
+	docString    string = `Managed OpenShift Customers may not set the managementState of the cluster Console to %s, or remove the managed console plugins %s.`
+	consoleKind  string = "Console"
+	consoleGroup string = "operator.openshift.io"
+	// consoleName is the name of the singleton Console operator configuration
+	consoleName string = "cluster"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{consoleGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"consoles"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-console-operator:console-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// disruptiveManagementStates are the managementState values which take
+	// the console away from the console operator
+	disruptiveManagementStates = []string{
+		"Removed",
+		"Unmanaged",
+	}
+	// managedConsolePlugins is the inventory of managed console plugins which
+	// must stay enabled
+	managedConsolePlugins = []string{
+		"managed-console-plugin",
+	}
+)
+
+// ConsoleWebhook protects the cluster Console operator configuration
+type ConsoleWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *ConsoleWebhook {
+	return &ConsoleWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *ConsoleWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *ConsoleWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newConsole, oldConsole, err := s.renderOldAndNewConsoles(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Console from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if oldConsole.GetName() != consoleName {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	managementState, _, _ := unstructured.NestedString(newConsole.Object, "spec", "managementState")
+	if utils.SliceContains(managementState, disruptiveManagementStates) {
+		log.Info(fmt.Sprintf("Console managementState change to %s detected", managementState))
+		ret = admissionctl.Denied(fmt.Sprintf("Setting the managementState of the cluster Console to %s is not allowed", managementState))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	oldPlugins, _, _ := unstructured.NestedStringSlice(oldConsole.Object, "spec", "plugins")
+	newPlugins, _, _ := unstructured.NestedStringSlice(newConsole.Object, "spec", "plugins")
+	for _, plugin := range managedConsolePlugins {
+		if utils.SliceContains(plugin, oldPlugins) && !utils.SliceContains(plugin, newPlugins) {
+			log.Info(fmt.Sprintf("Removal of managed console plugin %s detected", plugin))
+			ret = admissionctl.Denied(fmt.Sprintf("Removing the managed console plugin %s is not allowed", plugin))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewConsoles decodes both the Object and OldObject of the
+// request. The fields inspected are read generically, so the object is not
+// decoded into the typed Console. Return order is: new, old, error.
+func (s *ConsoleWebhook) renderOldAndNewConsoles(request admissionctl.Request) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	newConsole := &unstructured.Unstructured{}
+	oldConsole := &unstructured.Unstructured{}
+
+	err = decoder.DecodeRaw(request.Object, newConsole)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	err = decoder.DecodeRaw(request.OldObject, oldConsole)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+
+	return newConsole, oldConsole, nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *ConsoleWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *ConsoleWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == consoleKind)
+	valid = valid && (request.Kind.Group == consoleGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *ConsoleWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *ConsoleWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *ConsoleWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *ConsoleWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *ConsoleWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *ConsoleWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *ConsoleWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *ConsoleWebhook) Doc() string {
+	return fmt.Sprintf(docString, disruptiveManagementStates, managedConsolePlugins)
+}
+
+// RequiredCapabilities implements CapabilityDependent interface. The Console operator only runs with the Console capability.
+func (s *ConsoleWebhook) RequiredCapabilities() []string {
+	return []string{"Console"}
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *ConsoleWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,164 @@
+package console
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type consoleTestSuites struct {
+	testID             string
+	oldManagementState string
+	newManagementState string
+	oldPlugins         string
+	newPlugins         string
+	oldLogLevel        string
+	newLogLevel        string
+	username           string
+	userGroups         []string
+	shouldBeAllowed    bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "operator.openshift.io/v1",
+	"kind": "Console",
+	"metadata": {
+		"name": "cluster",
+		"uid": "1234"
+	},
+	"spec": {
+		"managementState": "%s",
+		"logLevel": "%s",
+		"plugins": %s
+	}
+}`
+
+func runConsoleTests(t *testing.T, tests []consoleTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "operator.openshift.io",
+		Version: "v1",
+		Kind:    "Console",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "operator.openshift.io",
+		Version:  "v1",
+		Resource: "consoles",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.newManagementState, test.newLogLevel, test.newPlugins)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.oldManagementState, test.oldLogLevel, test.oldPlugins)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: %s (groups=%s) %s update the cluster Console. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []consoleTestSuites{
+		{
+			testID:             "user-cant-remove-console",
+			oldManagementState: "Managed",
+			newManagementState: "Removed",
+			oldPlugins:         `["managed-console-plugin"]`,
+			newPlugins:         `["managed-console-plugin"]`,
+			oldLogLevel:        "Normal",
+			newLogLevel:        "Normal",
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    false,
+		},
+		{
+			testID:             "user-cant-unmanage-console",
+			oldManagementState: "Managed",
+			newManagementState: "Unmanaged",
+			oldPlugins:         `[]`,
+			newPlugins:         `[]`,
+			oldLogLevel:        "Normal",
+			newLogLevel:        "Normal",
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    false,
+		},
+		{
+			testID:             "user-cant-remove-managed-plugin",
+			oldManagementState: "Managed",
+			newManagementState: "Managed",
+			oldPlugins:         `["managed-console-plugin", "customer-plugin"]`,
+			newPlugins:         `["customer-plugin"]`,
+			oldLogLevel:        "Normal",
+			newLogLevel:        "Normal",
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    false,
+		},
+	}
+	runConsoleTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []consoleTestSuites{
+		{
+			testID:             "user-can-change-log-level",
+			oldManagementState: "Managed",
+			newManagementState: "Managed",
+			oldPlugins:         `["managed-console-plugin"]`,
+			newPlugins:         `["managed-console-plugin"]`,
+			oldLogLevel:        "Normal",
+			newLogLevel:        "Debug",
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    true,
+		},
+		{
+			testID:             "user-can-add-and-remove-customer-plugin",
+			oldManagementState: "Managed",
+			newManagementState: "Managed",
+			oldPlugins:         `["managed-console-plugin", "customer-plugin"]`,
+			newPlugins:         `["managed-console-plugin", "other-customer-plugin"]`,
+			oldLogLevel:        "Normal",
+			newLogLevel:        "Normal",
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    true,
+		},
+		{
+			testID:             "console-operator-can-remove-console",
+			oldManagementState: "Managed",
+			newManagementState: "Removed",
+			oldPlugins:         `[]`,
+			newPlugins:         `[]`,
+			oldLogLevel:        "Normal",
+			newLogLevel:        "Normal",
+			username:           "system:serviceaccount:openshift-console-operator:console-operator",
+			userGroups:         []string{"system:serviceaccounts", "system:serviceaccounts:openshift-console-operator"},
+			shouldBeAllowed:    true,
+		},
+	}
+	runConsoleTests(t, tests)
+}
@@ -0,0 +1,309 @@
+package costallocation
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName     string = "costallocation-validation"
+	docString       string = `Managed OpenShift Customers must set the following cost allocation labels on their Deployments and StatefulSets, and on their pod templates, outside of the platform namespaces and of namespaces matching any of %s: %s`
+	deploymentKind  string = "Deployment"
+	statefulSetKind string = "StatefulSet"
+	// exemptNamespacesParameter is the parameter of the configuration file
+	// setting the comma-separated exemptNamespaces
+	exemptNamespacesParameter string = "exemptNamespaces"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"deployments", "statefulsets"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// requiredLabels are the cost allocation labels customer workloads must
+	// carry. None are required unless configured, as they are specific to
+	// the chargeback of each organization.
+	requiredLabels = []string{}
+	// exemptNamespaces are regular expressions matching the namespaces where
+	// workloads need no cost allocation labels, on top of the privileged
+	// namespaces
+	exemptNamespaces = []string{}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &exemptNamespaces, &requiredLabels)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var exempt []string
+	for name, value := range settings.Parameters {
+		if name != exemptNamespacesParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		for _, namespace := range strings.Split(value, ",") {
+			namespace = strings.TrimSpace(namespace)
+			if namespace == "" {
+				return nil, fmt.Errorf("%s %q has an empty namespace", exemptNamespacesParameter, value)
+			}
+			if _, err := regexp.Compile(namespace); err != nil {
+				return nil, fmt.Errorf("%s %q is not a valid regular expression: %v", exemptNamespacesParameter, namespace, err)
+			}
+			exempt = append(exempt, namespace)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if exempt != nil {
+			exemptNamespaces = exempt
+		}
+		if settings.Protected != nil {
+			requiredLabels = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"exemptNamespaces": exemptNamespaces,
+		"requiredLabels":   requiredLabels,
+		"allowedUsers":     allowedUsers,
+		"allowedGroups":    allowedGroups,
+	}
+}
+
+// CostAllocationWebhook requires customer workloads to carry the cost
+// allocation labels chargeback relies on
+type CostAllocationWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *CostAllocationWebhook {
+	return &CostAllocationWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *CostAllocationWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *CostAllocationWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Create || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	workload, template, err := s.renderWorkload(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a workload from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isExemptNamespace(workload.Namespace) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	var problems []string
+	if missing := missingLabels(workload.Labels); len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("the %s lacks %v", request.Kind.Kind, missing))
+	}
+	if missing := missingLabels(template.Labels); len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("its pod template lacks %v", missing))
+	}
+	if len(problems) > 0 {
+		log.Info(fmt.Sprintf("%s %s/%s lacking cost allocation labels detected", request.Kind.Kind, workload.Namespace, workload.Name), "problems", problems)
+		ret = admissionctl.Denied(fmt.Sprintf("%s %s must carry the cost allocation labels %v, but %s", request.Kind.Kind, workload.Name, requiredLabels, strings.Join(problems, " and ")))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderWorkload renders the Deployment or StatefulSet being created. Return
+// order is: the metadata of the workload, of its pod template, error.
+func (s *CostAllocationWebhook) renderWorkload(request admissionctl.Request) (*metav1.ObjectMeta, *metav1.ObjectMeta, error) {
+	var newObject func() runtime.Object
+	switch request.Kind.Kind {
+	case deploymentKind:
+		newObject = func() runtime.Object { return &appsv1.Deployment{} }
+	case statefulSetKind:
+		newObject = func() runtime.Object { return &appsv1.StatefulSet{} }
+	default:
+		return nil, nil, fmt.Errorf("%s is not a Deployment nor a StatefulSet", request.Kind.Kind)
+	}
+
+	newObj, _, err := utils.RenderObjects(s.s, request, newObject)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil {
+		return nil, nil, fmt.Errorf("%s %s request is missing an object", request.Kind.Kind, request.Operation)
+	}
+
+	var workload, template *metav1.ObjectMeta
+	switch obj := newObj.(type) {
+	case *appsv1.Deployment:
+		workload, template = &obj.ObjectMeta, &obj.Spec.Template.ObjectMeta
+	case *appsv1.StatefulSet:
+		workload, template = &obj.ObjectMeta, &obj.Spec.Template.ObjectMeta
+	default:
+		return nil, nil, fmt.Errorf("unexpected %T", newObj)
+	}
+	// The namespace may be left out of the object on CREATE
+	if workload.Namespace == "" {
+		workload.Namespace = request.Namespace
+	}
+	return workload, template, nil
+}
+
+// missingLabels returns the requiredLabels which are absent or empty
+func missingLabels(labels map[string]string) []string {
+	var missing []string
+	for _, label := range requiredLabels {
+		if labels[label] == "" {
+			missing = append(missing, label)
+		}
+	}
+	return missing
+}
+
+// isExemptNamespace checks if workloads in the namespace need no cost
+// allocation labels
+func isExemptNamespace(namespace string) bool {
+	return config.IsPrivilegedNamespace(namespace) || utils.RegexSliceContains(namespace, exemptNamespaces)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *CostAllocationWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *CostAllocationWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == deploymentKind || request.Kind.Kind == statefulSetKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *CostAllocationWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *CostAllocationWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *CostAllocationWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *CostAllocationWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *CostAllocationWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *CostAllocationWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *CostAllocationWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *CostAllocationWebhook) Doc() string {
+	return fmt.Sprintf(docString, exemptNamespaces, requiredLabels)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *CostAllocationWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
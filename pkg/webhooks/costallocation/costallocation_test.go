@@ -0,0 +1,235 @@
+package costallocation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type costAllocationTestSuites struct {
+	testID          string
+	kind            string
+	resource        string
+	targetNamespace string
+	labels          string
+	templateLabels  string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "apps/v1",
+	"kind": "%s",
+	"metadata": {
+		"name": "test-workload",
+		"namespace": "%s",
+		"uid": "1234",
+		"labels": %s
+	},
+	"spec": {
+		"selector": {"matchLabels": {"app": "test"}},
+		"template": {
+			"metadata": {"labels": %s},
+			"spec": {"containers": [{"name": "test", "image": "quay.io/test/test:latest"}]}
+		}
+	}
+}`
+
+const (
+	labeled   string = `{"app": "test", "cost-center": "1234", "team": "payments"}`
+	unlabeled string = `{"app": "test"}`
+)
+
+func runCostAllocationTests(t *testing.T, tests []costAllocationTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "apps",
+			Version: "v1",
+			Kind:    test.kind,
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    "apps",
+			Version:  "v1",
+			Resource: test.resource,
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.kind, test.targetNamespace, test.labels, test.templateLabels)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s create the %s labeled %s with pod template labeled %s in %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.kind, test.labels, test.templateLabels, test.targetNamespace, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+// requireLabels makes the tests require the cost allocation labels, which
+// aren't required until configured
+func requireLabels() func() {
+	oldLabels := requiredLabels
+	requiredLabels = []string{"cost-center", "team"}
+	return func() { requiredLabels = oldLabels }
+}
+
+func TestUserNegative(t *testing.T) {
+	defer requireLabels()()
+
+	tests := []costAllocationTestSuites{
+		{
+			testID:          "user-cant-create-unlabeled-deployment",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "my-project",
+			labels:          unlabeled,
+			templateLabels:  labeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-create-deployment-with-unlabeled-pod-template",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "my-project",
+			labels:          labeled,
+			templateLabels:  unlabeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-create-unlabeled-statefulset",
+			kind:            "StatefulSet",
+			resource:        "statefulsets",
+			targetNamespace: "my-project",
+			labels:          unlabeled,
+			templateLabels:  unlabeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runCostAllocationTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	defer requireLabels()()
+
+	tests := []costAllocationTestSuites{
+		{
+			testID:          "user-can-create-labeled-deployment",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "my-project",
+			labels:          labeled,
+			templateLabels:  labeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-create-labeled-statefulset",
+			kind:            "StatefulSet",
+			resource:        "statefulsets",
+			targetNamespace: "my-project",
+			labels:          labeled,
+			templateLabels:  labeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "unlabeled-deployment-in-platform-namespace",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "kube-system",
+			labels:          unlabeled,
+			templateLabels:  unlabeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-create-unlabeled-deployment",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "my-project",
+			labels:          unlabeled,
+			templateLabels:  unlabeled,
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runCostAllocationTests(t, tests)
+}
+
+func TestExemptNamespaces(t *testing.T) {
+	defer requireLabels()()
+	oldNamespaces := exemptNamespaces
+	defer func() { exemptNamespaces = oldNamespaces }()
+	exemptNamespaces = []string{"^sandbox-.*"}
+
+	tests := []costAllocationTestSuites{
+		{
+			testID:          "user-can-create-unlabeled-deployment-in-exempt-namespace",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "sandbox-user1",
+			labels:          unlabeled,
+			templateLabels:  unlabeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-cant-create-unlabeled-deployment-outside-exempt-namespace",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "production",
+			labels:          unlabeled,
+			templateLabels:  unlabeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runCostAllocationTests(t, tests)
+}
+
+func TestNoRequiredLabels(t *testing.T) {
+	tests := []costAllocationTestSuites{
+		{
+			testID:          "user-can-create-unlabeled-deployment-until-configured",
+			kind:            "Deployment",
+			resource:        "deployments",
+			targetNamespace: "my-project",
+			labels:          unlabeled,
+			templateLabels:  unlabeled,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runCostAllocationTests(t, tests)
+}
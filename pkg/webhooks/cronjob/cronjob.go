@@ -0,0 +1,258 @@
+package cronjob
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "cronjob-validation"
+	docString   string = `Managed OpenShift Customers may not delete or suspend the following managed CronJobs: %s`
+	cronJobKind string = "CronJob"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"batch"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"cronjobs"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:kube-system:namespace-controller",
+		"system:serviceaccount:openshift-image-registry:cluster-image-registry-operator",
+		"system:serviceaccount:openshift-operator-lifecycle-manager:olm-operator-serviceaccount",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedCronJobs is the inventory of managed CronJobs, in the form of
+	// namespace/name. The etcd backup CronJobs are protected by the
+	// etcdbackup-validation webhook.
+	managedCronJobs = []string{
+		"openshift-image-registry/image-pruner",
+		"openshift-operator-lifecycle-manager/collect-profiles",
+		"openshift-sre-pruning/builds-pruner",
+		"openshift-sre-pruning/deployments-pruner",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedCronJobs)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedCronJobs = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedCronJobs": managedCronJobs,
+		"allowedUsers":    allowedUsers,
+		"allowedGroups":   allowedGroups,
+	}
+}
+
+// CronJobWebhook keeps the managed CronJobs, eg the pruners, from being
+// deleted or suspended
+type CronJobWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *CronJobWebhook {
+	return &CronJobWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *CronJobWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *CronJobWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newCronJob, oldCronJob, err := s.renderCronJobs(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a CronJob from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if oldCronJob != nil && isManaged(oldCronJob) {
+		name := oldCronJob.Namespace + "/" + oldCronJob.Name
+		switch request.Operation {
+		case admissionv1.Delete:
+			log.Info(fmt.Sprintf("Deleting operation detected on managed CronJob: %v", name))
+			ret = admissionctl.Denied(fmt.Sprintf("Deleting managed CronJob %v is not allowed", name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		case admissionv1.Update:
+			if newCronJob != nil && isSuspended(newCronJob) && !isSuspended(oldCronJob) {
+				log.Info(fmt.Sprintf("Suspension detected on managed CronJob: %v", name))
+				ret = admissionctl.Denied(fmt.Sprintf("Suspending managed CronJob %v is not allowed", name))
+				ret.UID = request.AdmissionRequest.UID
+				return ret
+			}
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderCronJobs decodes both the Object and OldObject of the request. Either
+// is nil when absent from the request. Return order is: new, old, error.
+func (s *CronJobWebhook) renderCronJobs(request admissionctl.Request) (*batchv1.CronJob, *batchv1.CronJob, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &batchv1.CronJob{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+
+	var newCronJob, oldCronJob *batchv1.CronJob
+	if newObj != nil {
+		newCronJob = newObj.(*batchv1.CronJob)
+	}
+	if oldObj != nil {
+		oldCronJob = oldObj.(*batchv1.CronJob)
+	}
+	return newCronJob, oldCronJob, nil
+}
+
+// isManaged checks if the CronJob is in the managed inventory
+func isManaged(cronJob *batchv1.CronJob) bool {
+	return utils.SliceContains(cronJob.Namespace+"/"+cronJob.Name, managedCronJobs)
+}
+
+// isSuspended checks if the CronJob is kept from scheduling new Jobs
+func isSuspended(cronJob *batchv1.CronJob) bool {
+	return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *CronJobWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *CronJobWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == cronJobKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *CronJobWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *CronJobWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *CronJobWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *CronJobWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *CronJobWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *CronJobWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *CronJobWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *CronJobWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedCronJobs)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *CronJobWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
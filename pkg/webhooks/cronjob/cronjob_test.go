@@ -0,0 +1,182 @@
+package cronjob
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type cronJobTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldSuspend      bool
+	newSuspend      bool
+	username        string
+	operation       admissionv1.Operation
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "batch/v1",
+	"kind": "CronJob",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"schedule": "0 */6 * * *",
+		"suspend": %t
+	}
+}`
+
+func runCronJobTests(t *testing.T, tests []cronJobTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "batch",
+		Version: "v1",
+		Kind:    "CronJob",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1",
+		Resource: "cronjobs",
+	}
+
+	for _, test := range tests {
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.oldSuspend)),
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.newSuspend)),
+		}
+		if test.operation == admissionv1.Delete {
+			// testutils sends obj as the OldObject of a DELETE
+			obj = oldObj
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s/%s CronJob. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []cronJobTestSuites{
+		{
+			testID:          "user-cant-suspend-managed-cronjob",
+			targetNamespace: "openshift-image-registry",
+			targetName:      "image-pruner",
+			newSuspend:      true,
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-managed-cronjob",
+			targetNamespace: "openshift-sre-pruning",
+			targetName:      "builds-pruner",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runCronJobTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []cronJobTestSuites{
+		{
+			testID:          "user-can-suspend-customer-cronjob",
+			targetNamespace: "customer-ns",
+			targetName:      "my-cronjob",
+			newSuspend:      true,
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-cronjob",
+			targetNamespace: "customer-ns",
+			targetName:      "my-cronjob",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-resume-managed-cronjob",
+			targetNamespace: "openshift-image-registry",
+			targetName:      "image-pruner",
+			oldSuspend:      true,
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "registry-operator-can-suspend-image-pruner",
+			targetNamespace: "openshift-image-registry",
+			targetName:      "image-pruner",
+			newSuspend:      true,
+			username:        "system:serviceaccount:openshift-image-registry:cluster-image-registry-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-image-registry"},
+			shouldBeAllowed: true,
+		},
+	}
+	runCronJobTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedCronJobs
+	defer func() { managedCronJobs = oldInventory }()
+	managedCronJobs = []string{"openshift-config/cert-rotation"}
+
+	tests := []cronJobTestSuites{
+		{
+			testID:          "user-cant-suspend-configured-cronjob",
+			targetNamespace: "openshift-config",
+			targetName:      "cert-rotation",
+			newSuspend:      true,
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-cronjob",
+			targetNamespace: "openshift-image-registry",
+			targetName:      "image-pruner",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runCronJobTests(t, tests)
+}
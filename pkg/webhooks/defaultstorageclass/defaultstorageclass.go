@@ -0,0 +1,259 @@
+package defaultstorageclass
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName      string = "defaultstorageclass-validation"
+	docString        string = `Managed OpenShift Customers may not remove the default StorageClass annotation from the following managed StorageClasses, nor make another StorageClass the default: %s`
+	storageClassKind string = "StorageClass"
+	// defaultClassAnnotation marks the StorageClass PersistentVolumeClaims
+	// without one are provisioned with
+	defaultClassAnnotation string = "storageclass.kubernetes.io/is-default-class"
+	// betaDefaultClassAnnotation is the deprecated defaultClassAnnotation,
+	// which is still honored
+	betaDefaultClassAnnotation string = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"storage.k8s.io"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"storageclasses"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-cluster-storage-operator:cluster-storage-operator",
+		"system:serviceaccount:openshift-cluster-csi-drivers:aws-ebs-csi-driver-operator",
+		"system:serviceaccount:openshift-cluster-csi-drivers:gcp-pd-csi-driver-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedStorageClasses is the inventory of managed StorageClasses, which
+	// the cluster default StorageClass is one of
+	managedStorageClasses = []string{
+		"gp2",
+		"gp2-csi",
+		"gp3-csi",
+		"standard-csi",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedStorageClasses)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedStorageClasses = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedStorageClasses": managedStorageClasses,
+		"allowedUsers":          allowedUsers,
+		"allowedGroups":         allowedGroups,
+	}
+}
+
+// DefaultStorageClassWebhook keeps dynamic provisioning working by keeping
+// the cluster default StorageClass managed
+type DefaultStorageClassWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *DefaultStorageClassWebhook {
+	return &DefaultStorageClassWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *DefaultStorageClassWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *DefaultStorageClassWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newStorageClass, oldStorageClass, err := s.renderStorageClasses(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a StorageClass from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	wasDefault := oldStorageClass != nil && isDefault(oldStorageClass)
+	managed := utils.SliceContains(newStorageClass.Name, managedStorageClasses)
+	switch {
+	case managed && wasDefault && !isDefault(newStorageClass):
+		log.Info(fmt.Sprintf("Removal of the default StorageClass annotation detected on managed StorageClass: %v", newStorageClass.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Removing the %s annotation from managed StorageClass %v is not allowed", defaultClassAnnotation, newStorageClass.Name))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	case !managed && !wasDefault && isDefault(newStorageClass):
+		log.Info(fmt.Sprintf("Default StorageClass annotation detected on unmanaged StorageClass: %v", newStorageClass.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Making StorageClass %v the default is not allowed, the default StorageClass is one of the managed StorageClasses %v", newStorageClass.Name, managedStorageClasses))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderStorageClasses decodes both the Object and OldObject of the request.
+// The OldObject is nil on CREATE. Return order is: new, old, error.
+func (s *DefaultStorageClassWebhook) renderStorageClasses(request admissionctl.Request) (*storagev1.StorageClass, *storagev1.StorageClass, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &storagev1.StorageClass{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil {
+		return nil, nil, fmt.Errorf("StorageClass %s request is missing an object", request.Operation)
+	}
+	if request.Operation == admissionv1.Update && oldObj == nil {
+		return nil, nil, fmt.Errorf("StorageClass %s request is missing the existing object", request.Operation)
+	}
+
+	var oldStorageClass *storagev1.StorageClass
+	if oldObj != nil {
+		oldStorageClass = oldObj.(*storagev1.StorageClass)
+	}
+	return newObj.(*storagev1.StorageClass), oldStorageClass, nil
+}
+
+// isDefault checks if the StorageClass is annotated as a default one, under
+// either annotation
+func isDefault(storageClass *storagev1.StorageClass) bool {
+	return storageClass.Annotations[defaultClassAnnotation] == "true" || storageClass.Annotations[betaDefaultClassAnnotation] == "true"
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *DefaultStorageClassWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *DefaultStorageClassWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == storageClassKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *DefaultStorageClassWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *DefaultStorageClassWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *DefaultStorageClassWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *DefaultStorageClassWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *DefaultStorageClassWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *DefaultStorageClassWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *DefaultStorageClassWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *DefaultStorageClassWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedStorageClasses)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *DefaultStorageClassWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
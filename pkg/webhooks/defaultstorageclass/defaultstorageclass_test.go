@@ -0,0 +1,204 @@
+package defaultstorageclass
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type defaultStorageClassTestSuites struct {
+	testID          string
+	targetName      string
+	operation       admissionv1.Operation
+	oldDefault      string
+	newDefault      string
+	oldType         string
+	newType         string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "storage.k8s.io/v1",
+	"kind": "StorageClass",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234",
+		"annotations": {
+			"storageclass.kubernetes.io/is-default-class": "%s"
+		}
+	},
+	"provisioner": "ebs.csi.aws.com",
+	"parameters": {
+		"type": "%s"
+	}
+}`
+
+func runDefaultStorageClassTests(t *testing.T, tests []defaultStorageClassTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "storage.k8s.io",
+		Version: "v1",
+		Kind:    "StorageClass",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "storage.k8s.io",
+		Version:  "v1",
+		Resource: "storageclasses",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.newDefault, test.newType)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.oldDefault, test.oldType)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the StorageClass %s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []defaultStorageClassTestSuites{
+		{
+			testID:          "user-cant-remove-default-annotation",
+			targetName:      "gp3-csi",
+			operation:       admissionv1.Update,
+			oldDefault:      "true",
+			newDefault:      "false",
+			oldType:         "gp3",
+			newType:         "gp3",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-make-own-storageclass-default",
+			targetName:      "my-storageclass",
+			operation:       admissionv1.Update,
+			oldDefault:      "false",
+			newDefault:      "true",
+			oldType:         "io1",
+			newType:         "io1",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-create-default-storageclass",
+			targetName:      "my-storageclass",
+			operation:       admissionv1.Create,
+			newDefault:      "true",
+			newType:         "io1",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runDefaultStorageClassTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []defaultStorageClassTestSuites{
+		{
+			testID:          "user-can-change-parameters-of-default-storageclass",
+			targetName:      "gp3-csi",
+			operation:       admissionv1.Update,
+			oldDefault:      "true",
+			newDefault:      "true",
+			oldType:         "gp3",
+			newType:         "io2",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-create-storageclass",
+			targetName:      "my-storageclass",
+			operation:       admissionv1.Create,
+			newDefault:      "false",
+			newType:         "io1",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-unset-default-of-own-storageclass",
+			targetName:      "my-storageclass",
+			operation:       admissionv1.Update,
+			oldDefault:      "true",
+			newDefault:      "false",
+			oldType:         "io1",
+			newType:         "io1",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "storage-operator-can-remove-default-annotation",
+			targetName:      "gp2",
+			operation:       admissionv1.Update,
+			oldDefault:      "true",
+			newDefault:      "false",
+			oldType:         "gp2",
+			newType:         "gp2",
+			username:        "system:serviceaccount:openshift-cluster-storage-operator:cluster-storage-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-storage-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runDefaultStorageClassTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedStorageClasses
+	defer func() { managedStorageClasses = oldInventory }()
+	managedStorageClasses = []string{"managed-premium"}
+
+	tests := []defaultStorageClassTestSuites{
+		{
+			testID:          "user-cant-remove-default-annotation-from-configured-storageclass",
+			targetName:      "managed-premium",
+			operation:       admissionv1.Update,
+			oldDefault:      "true",
+			newDefault:      "false",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-make-unconfigured-storageclass-default",
+			targetName:      "gp3-csi",
+			operation:       admissionv1.Update,
+			oldDefault:      "false",
+			newDefault:      "true",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runDefaultStorageClassTests(t, tests)
+}
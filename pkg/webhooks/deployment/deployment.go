@@ -0,0 +1,252 @@
+package deployment
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName    string = "deployment-validation"
+	docString      string = `Managed OpenShift Customers may not scale the following managed Deployments to zero replicas: %s`
+	deploymentKind string = "Deployment"
+	scaleKind      string = "Scale"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"deployments", "deployments/scale"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-console-operator:console-operator",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-authentication-operator:authentication-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedDeployments is the inventory of managed Deployments, in the form
+	// of namespace/name
+	managedDeployments = []string{
+		"openshift-console/console",
+		"openshift-console/downloads",
+		"openshift-authentication/oauth-openshift",
+		"openshift-monitoring/prometheus-operator",
+		"openshift-monitoring/thanos-querier",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedDeployments)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedDeployments = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedDeployments": managedDeployments,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// DeploymentWebhook protects managed Deployments from being scaled to zero
+type DeploymentWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *DeploymentWebhook {
+	return &DeploymentWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *DeploymentWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *DeploymentWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	name, replicas, err := s.renderReplicas(request)
+	if err != nil {
+		log.Error(err, "Couldn't render the replica count from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if replicas == 0 && utils.SliceContains(name, managedDeployments) {
+		log.Info(fmt.Sprintf("Scale to zero detected on managed Deployment: %s", name))
+		ret = admissionctl.Denied(fmt.Sprintf("Scaling managed Deployment %s to zero replicas is not allowed", name))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderReplicas returns the namespace/name of the targeted Deployment and the
+// requested number of replicas. Scaling may happen either through the
+// Deployment itself or through its scale subresource.
+func (s *DeploymentWebhook) renderReplicas(request admissionctl.Request) (string, int32, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if request.Kind.Kind == scaleKind {
+		scale := &autoscalingv1.Scale{}
+		err = decoder.DecodeRaw(request.Object, scale)
+		if err != nil {
+			metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+			return "", 0, err
+		}
+		return scale.Namespace + "/" + scale.Name, scale.Spec.Replicas, nil
+	}
+
+	deployment := &appsv1.Deployment{}
+	err = decoder.DecodeRaw(request.Object, deployment)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return "", 0, err
+	}
+	// An unset replica count defaults to 1
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Namespace + "/" + deployment.Name, replicas, nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *DeploymentWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *DeploymentWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == deploymentKind || request.Kind.Kind == scaleKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *DeploymentWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *DeploymentWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *DeploymentWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *DeploymentWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *DeploymentWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *DeploymentWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *DeploymentWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *DeploymentWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedDeployments)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *DeploymentWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
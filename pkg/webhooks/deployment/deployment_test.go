@@ -0,0 +1,194 @@
+package deployment
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type deploymentTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	replicas        int32
+	viaScale        bool
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testDeploymentRaw string = `
+{
+	"apiVersion": "apps/v1",
+	"kind": "Deployment",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"replicas": %d
+	}
+}`
+
+const testScaleRaw string = `
+{
+	"apiVersion": "autoscaling/v1",
+	"kind": "Scale",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"replicas": %d
+	}
+}`
+
+func runDeploymentTests(t *testing.T, tests []deploymentTestSuites) {
+	gvr := metav1.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments",
+	}
+
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "apps",
+			Version: "v1",
+			Kind:    "Deployment",
+		}
+		rawObjString := fmt.Sprintf(testDeploymentRaw, test.targetName, test.targetNamespace, test.replicas)
+		oldObjString := fmt.Sprintf(testDeploymentRaw, test.targetName, test.targetNamespace, 2)
+		if test.viaScale {
+			gvk = metav1.GroupVersionKind{
+				Group:   "autoscaling",
+				Version: "v1",
+				Kind:    "Scale",
+			}
+			rawObjString = fmt.Sprintf(testScaleRaw, test.targetName, test.targetNamespace, test.replicas)
+			oldObjString = fmt.Sprintf(testScaleRaw, test.targetName, test.targetNamespace, 2)
+		}
+
+		obj := runtime.RawExtension{
+			Raw: []byte(rawObjString),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(oldObjString),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s scale %s/%s to %d. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, test.replicas, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []deploymentTestSuites{
+		{
+			testID:          "user-cant-scale-console-to-zero",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			replicas:        0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-scale-console-to-zero-via-scale",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			replicas:        0,
+			viaScale:        true,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runDeploymentTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []deploymentTestSuites{
+		{
+			testID:          "user-can-scale-up-console",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			replicas:        3,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-scale-customer-deployment-to-zero",
+			targetNamespace: "my-project",
+			targetName:      "my-app",
+			replicas:        0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "console-operator-can-scale-console-to-zero",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			replicas:        0,
+			username:        "system:serviceaccount:openshift-console-operator:console-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-console-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runDeploymentTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedDeployments
+	defer func() { managedDeployments = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"my-project/frontend"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []deploymentTestSuites{
+		{
+			testID:          "user-cant-scale-configured-deployment-to-zero",
+			targetNamespace: "my-project",
+			targetName:      "frontend",
+			replicas:        0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-scale-unconfigured-deployment-to-zero",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			replicas:        0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runDeploymentTests(t, tests)
+}
@@ -0,0 +1,303 @@
+package deploymentimage
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName    string = "deploymentimage-validation"
+	docString      string = `Managed OpenShift Customers may not change the container images of the following managed Deployments to images outside of %s: %s`
+	deploymentKind string = "Deployment"
+	// trustedRegistriesParameter is the parameter of the configuration file
+	// setting the comma-separated trustedRegistries
+	trustedRegistriesParameter string = "trustedRegistries"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"deployments"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-console-operator:console-operator",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-authentication-operator:authentication-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedDeployments is the inventory of managed Deployments, in the form
+	// of namespace/name
+	managedDeployments = []string{
+		"openshift-console/console",
+		"openshift-console/downloads",
+		"openshift-authentication/oauth-openshift",
+		"openshift-monitoring/prometheus-operator",
+		"openshift-monitoring/thanos-querier",
+	}
+	// trustedRegistries are the repository prefixes the images of managed
+	// Deployments may be pulled from
+	trustedRegistries = []string{
+		"quay.io/openshift-release-dev/",
+		"registry.redhat.io/",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &trustedRegistries, &managedDeployments)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var registries []string
+	for name, value := range settings.Parameters {
+		if name != trustedRegistriesParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		for _, registry := range strings.Split(value, ",") {
+			registry = strings.TrimSpace(registry)
+			if registry == "" {
+				return nil, fmt.Errorf("%s %q has an empty registry", trustedRegistriesParameter, value)
+			}
+			registries = append(registries, registry)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if registries != nil {
+			trustedRegistries = registries
+		}
+		if settings.Protected != nil {
+			managedDeployments = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"trustedRegistries":  trustedRegistries,
+		"managedDeployments": managedDeployments,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// DeploymentImageWebhook keeps the managed Deployments running trusted
+// images
+type DeploymentImageWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *DeploymentImageWebhook {
+	return &DeploymentImageWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *DeploymentImageWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *DeploymentImageWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newDeployment, oldDeployment, err := s.renderOldAndNewDeployments(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Deployment from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	name := newDeployment.Namespace + "/" + newDeployment.Name
+	if utils.SliceContains(name, managedDeployments) {
+		if images := untrustedImages(newDeployment, oldDeployment); len(images) > 0 {
+			log.Info(fmt.Sprintf("Change to untrusted images %v detected on managed Deployment: %s", images, name))
+			ret = admissionctl.Denied(fmt.Sprintf("Changing the images of managed Deployment %s to %v is not allowed, images must come from %v", name, images, trustedRegistries))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewDeployments decodes both the Object and OldObject of the
+// request. Return order is: new, old, error.
+func (s *DeploymentImageWebhook) renderOldAndNewDeployments(request admissionctl.Request) (*appsv1.Deployment, *appsv1.Deployment, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &appsv1.Deployment{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("Deployment UPDATE request is missing an object")
+	}
+
+	return newObj.(*appsv1.Deployment), oldObj.(*appsv1.Deployment), nil
+}
+
+// untrustedImages returns the images the update sets on containers of the
+// Deployment which don't come from trustedRegistries. Images which were
+// already running are left alone, so an unrelated update doesn't fail.
+func untrustedImages(newDeployment, oldDeployment *appsv1.Deployment) []string {
+	previous := containerImages(oldDeployment)
+	var untrusted []string
+	for container, image := range containerImages(newDeployment) {
+		if image == previous[container] || isTrusted(image) {
+			continue
+		}
+		untrusted = append(untrusted, image)
+	}
+	sort.Strings(untrusted)
+	return untrusted
+}
+
+// containerImages maps the name of each container of the Deployment, init
+// containers included, to its image
+func containerImages(deployment *appsv1.Deployment) map[string]string {
+	images := map[string]string{}
+	for _, containers := range [][]corev1.Container{deployment.Spec.Template.Spec.InitContainers, deployment.Spec.Template.Spec.Containers} {
+		for _, container := range containers {
+			images[container.Name] = container.Image
+		}
+	}
+	return images
+}
+
+// isTrusted checks if the image comes from one of trustedRegistries
+func isTrusted(image string) bool {
+	for _, registry := range trustedRegistries {
+		if strings.HasPrefix(image, registry) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *DeploymentImageWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *DeploymentImageWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == deploymentKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *DeploymentImageWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *DeploymentImageWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *DeploymentImageWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *DeploymentImageWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *DeploymentImageWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *DeploymentImageWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *DeploymentImageWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *DeploymentImageWebhook) Doc() string {
+	return fmt.Sprintf(docString, trustedRegistries, managedDeployments)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *DeploymentImageWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,195 @@
+package deploymentimage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type deploymentImageTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldImage        string
+	newImage        string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "apps/v1",
+	"kind": "Deployment",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"template": {
+			"spec": {
+				"containers": [
+					{
+						"name": "console",
+						"image": "%s"
+					}
+				]
+			}
+		}
+	}
+}`
+
+const (
+	managedImage       string = "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:1111"
+	bumpedManagedImage string = "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222"
+	untrustedImage     string = "docker.io/someone/console:patched"
+)
+
+func runDeploymentImageTests(t *testing.T, tests []deploymentImageTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "apps",
+		Version: "v1",
+		Kind:    "Deployment",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.newImage)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.oldImage)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s change the image of the Deployment %s/%s to %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, test.newImage, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []deploymentImageTestSuites{
+		{
+			testID:          "user-cant-swap-managed-image-for-untrusted",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			oldImage:        managedImage,
+			newImage:        untrustedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runDeploymentImageTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []deploymentImageTestSuites{
+		{
+			testID:          "user-can-bump-managed-image",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			oldImage:        managedImage,
+			newImage:        bumpedManagedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-keep-existing-untrusted-image",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			oldImage:        untrustedImage,
+			newImage:        untrustedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-set-any-image-on-customer-deployment",
+			targetNamespace: "my-project",
+			targetName:      "console",
+			oldImage:        managedImage,
+			newImage:        untrustedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "console-operator-can-set-any-image",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			oldImage:        managedImage,
+			newImage:        untrustedImage,
+			username:        "system:serviceaccount:openshift-console-operator:console-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-console-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runDeploymentImageTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory, oldRegistries := managedDeployments, trustedRegistries
+	defer func() { managedDeployments, trustedRegistries = oldInventory, oldRegistries }()
+	managedDeployments = []string{"openshift-ingress/router-default"}
+	trustedRegistries = []string{"docker.io/someone/"}
+
+	tests := []deploymentImageTestSuites{
+		{
+			testID:          "user-cant-swap-configured-deployment-image",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-default",
+			oldImage:        untrustedImage,
+			newImage:        managedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-use-configured-registry",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-default",
+			oldImage:        managedImage,
+			newImage:        untrustedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-swap-unconfigured-deployment-image",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			oldImage:        untrustedImage,
+			newImage:        managedImage,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runDeploymentImageTests(t, tests)
+}
@@ -0,0 +1,276 @@
+package egress
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName        string = "egress-validation"
+	docString          string = `Managed OpenShift Customers may not modify or delete the following managed EgressFirewalls %s or EgressIPs %s.`
+	egressGroup        string = "k8s.ovn.org"
+	egressFirewallKind string = "EgressFirewall"
+	egressIPKind       string = "EgressIP"
+	// managedEgressIPsParameter is the parameter of the configuration file
+	// setting the comma-separated managedEgressIPs
+	managedEgressIPsParameter string = "managedEgressIPs"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+
+	namespacedScope = admissionregv1.NamespacedScope
+	clusterScope    = admissionregv1.ClusterScope
+	rules           = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{egressGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"egressfirewalls"},
+				Scope:       &namespacedScope,
+			},
+		},
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{egressGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"egressips"},
+				Scope:       &clusterScope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-network-operator:cluster-network-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedEgressFirewalls is the inventory of managed EgressFirewalls, in
+	// the form of namespace/name
+	managedEgressFirewalls = []string{
+		"openshift-backplane/default",
+		"openshift-backplane-srep/default",
+	}
+	// managedEgressIPs is the inventory of managed (cluster-scoped) EgressIPs
+	managedEgressIPs = []string{
+		"managed-egress",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedEgressFirewalls, &managedEgressIPs)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it. The protected list sets the
+// managedEgressFirewalls.
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var egressIPs []string
+	for name, value := range settings.Parameters {
+		if name != managedEgressIPsParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		for _, egressIP := range strings.Split(value, ",") {
+			egressIP = strings.TrimSpace(egressIP)
+			if egressIP == "" {
+				return nil, fmt.Errorf("%s %q has an empty name", managedEgressIPsParameter, value)
+			}
+			egressIPs = append(egressIPs, egressIP)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedEgressFirewalls = settings.Protected
+		}
+		if egressIPs != nil {
+			managedEgressIPs = egressIPs
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedEgressFirewalls": managedEgressFirewalls,
+		"managedEgressIPs":       managedEgressIPs,
+		"allowedUsers":           allowedUsers,
+		"allowedGroups":          allowedGroups,
+	}
+}
+
+// EgressWebhook protects managed EgressFirewall and EgressIP objects
+type EgressWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *EgressWebhook {
+	return &EgressWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *EgressWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *EgressWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	obj, err := s.renderEgressObject(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an egress object from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isManaged(request.Kind.Kind, obj) && !isAllowedUserGroup(request) {
+		log.Info(fmt.Sprintf("%s operation detected on managed %s: %s/%s", request.Operation, request.Kind.Kind, obj.GetNamespace(), obj.GetName()))
+		ret = admissionctl.Denied(fmt.Sprintf("Modifying or deleting managed %s objects is not allowed", request.Kind.Kind))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderEgressObject renders the EgressFirewall or EgressIP from the request.
+// The k8s.ovn.org types are not vendored, so the object is decoded generically.
+func (s *EgressWebhook) renderEgressObject(request admissionctl.Request) (*unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+
+	if len(request.OldObject.Raw) > 0 {
+		err = decoder.DecodeRaw(request.OldObject, obj)
+	}
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// isManaged checks if the object is in the relevant managed inventory
+func isManaged(kind string, obj *unstructured.Unstructured) bool {
+	switch kind {
+	case egressFirewallKind:
+		return utils.SliceContains(obj.GetNamespace()+"/"+obj.GetName(), managedEgressFirewalls)
+	case egressIPKind:
+		return utils.SliceContains(obj.GetName(), managedEgressIPs)
+	}
+	return false
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *EgressWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *EgressWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Group == egressGroup)
+	valid = valid && (request.Kind.Kind == egressFirewallKind || request.Kind.Kind == egressIPKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *EgressWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *EgressWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *EgressWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *EgressWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *EgressWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *EgressWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *EgressWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *EgressWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedEgressFirewalls, managedEgressIPs)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *EgressWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
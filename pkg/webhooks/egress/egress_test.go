@@ -0,0 +1,214 @@
+package egress
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type egressTestSuites struct {
+	testID          string
+	kind            string
+	resource        string
+	targetNamespace string
+	targetName      string
+	username        string
+	operation       admissionv1.Operation
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "k8s.ovn.org/v1",
+	"kind": "%s",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	}
+}`
+
+func runEgressTests(t *testing.T, tests []egressTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "k8s.ovn.org",
+			Version: "v1",
+			Kind:    test.kind,
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    "k8s.ovn.org",
+			Version:  "v1",
+			Resource: test.resource,
+		}
+		rawObjString := fmt.Sprintf(testObjectRaw, test.kind, test.targetName, test.targetNamespace)
+
+		obj := runtime.RawExtension{
+			Raw: []byte(rawObjString),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.kind, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []egressTestSuites{
+		{
+			testID:          "user-cant-delete-managed-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "openshift-backplane",
+			targetName:      "default",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "openshift-backplane",
+			targetName:      "default",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-managed-egressip",
+			kind:            "EgressIP",
+			resource:        "egressips",
+			targetName:      "managed-egress",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runEgressTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []egressTestSuites{
+		{
+			testID:          "user-can-modify-customer-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "my-project",
+			targetName:      "default",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "my-project",
+			targetName:      "default",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "network-operator-can-modify-managed-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "openshift-backplane",
+			targetName:      "default",
+			username:        "system:serviceaccount:openshift-network-operator:cluster-network-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-network-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runEgressTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldFirewalls, oldEgressIPs := managedEgressFirewalls, managedEgressIPs
+	defer func() { managedEgressFirewalls, managedEgressIPs = oldFirewalls, oldEgressIPs }()
+	apply, err := applySettings(config.WebhookSettings{
+		Protected:  []string{"my-project/default"},
+		Parameters: map[string]string{managedEgressIPsParameter: "my-egress, other-egress"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []egressTestSuites{
+		{
+			testID:          "user-cant-delete-configured-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "my-project",
+			targetName:      "default",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-egressfirewall",
+			kind:            "EgressFirewall",
+			resource:        "egressfirewalls",
+			targetNamespace: "openshift-backplane",
+			targetName:      "default",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-cant-delete-configured-egressip",
+			kind:            "EgressIP",
+			resource:        "egressips",
+			targetName:      "other-egress",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-egressip",
+			kind:            "EgressIP",
+			resource:        "egressips",
+			targetName:      "managed-egress",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runEgressTests(t, tests)
+
+	if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{managedEgressIPsParameter: "my-egress,"}}); err == nil {
+		t.Fatalf("Expected an empty EgressIP name to be rejected")
+	}
+}
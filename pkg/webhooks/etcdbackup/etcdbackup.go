@@ -0,0 +1,246 @@
+package etcdbackup
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "etcdbackup-validation"
+	docString   string = `Managed OpenShift Customers may not delete or suspend the following managed etcd backup CronJobs: %s`
+	cronJobKind string = "CronJob"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"batch"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"cronjobs"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:kube-system:namespace-controller",
+		"system:serviceaccount:openshift-etcd-operator:etcd-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedBackupCronJobs is the inventory of managed etcd backup CronJobs,
+	// in the form of namespace/name
+	managedBackupCronJobs = []string{
+		"openshift-etcd/managed-etcd-backup",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedBackupCronJobs)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedBackupCronJobs = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedBackupCronJobs": managedBackupCronJobs,
+		"allowedUsers":          allowedUsers,
+		"allowedGroups":         allowedGroups,
+	}
+}
+
+// EtcdBackupWebhook protects the managed etcd backup CronJobs
+type EtcdBackupWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *EtcdBackupWebhook {
+	return &EtcdBackupWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *EtcdBackupWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *EtcdBackupWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	newCronJob, oldCronJob, err := s.renderCronJobs(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a CronJob from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if oldCronJob != nil && isManagedBackup(oldCronJob) && !isAllowedUserGroup(request) {
+		name := oldCronJob.Namespace + "/" + oldCronJob.Name
+		switch request.Operation {
+		case admissionv1.Delete:
+			log.Info(fmt.Sprintf("Deleting operation detected on managed etcd backup CronJob: %v", name))
+			ret = admissionctl.Denied(fmt.Sprintf("Deleting managed etcd backup CronJob %v is not allowed", name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		case admissionv1.Update:
+			if newCronJob != nil && isSuspended(newCronJob) && !isSuspended(oldCronJob) {
+				log.Info(fmt.Sprintf("Suspension detected on managed etcd backup CronJob: %v", name))
+				ret = admissionctl.Denied(fmt.Sprintf("Suspending managed etcd backup CronJob %v is not allowed", name))
+				ret.UID = request.AdmissionRequest.UID
+				return ret
+			}
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderCronJobs decodes both the Object and OldObject of the request. Either
+// is nil when absent from the request. Return order is: new, old, error.
+func (s *EtcdBackupWebhook) renderCronJobs(request admissionctl.Request) (*batchv1.CronJob, *batchv1.CronJob, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &batchv1.CronJob{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+
+	var newCronJob, oldCronJob *batchv1.CronJob
+	if newObj != nil {
+		newCronJob = newObj.(*batchv1.CronJob)
+	}
+	if oldObj != nil {
+		oldCronJob = oldObj.(*batchv1.CronJob)
+	}
+	return newCronJob, oldCronJob, nil
+}
+
+// isManagedBackup checks if the CronJob is in the managed inventory
+func isManagedBackup(cronJob *batchv1.CronJob) bool {
+	return utils.SliceContains(cronJob.Namespace+"/"+cronJob.Name, managedBackupCronJobs)
+}
+
+// isSuspended checks if the CronJob is kept from scheduling new backups
+func isSuspended(cronJob *batchv1.CronJob) bool {
+	return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *EtcdBackupWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *EtcdBackupWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == cronJobKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *EtcdBackupWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *EtcdBackupWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *EtcdBackupWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *EtcdBackupWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *EtcdBackupWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *EtcdBackupWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *EtcdBackupWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *EtcdBackupWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedBackupCronJobs)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *EtcdBackupWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
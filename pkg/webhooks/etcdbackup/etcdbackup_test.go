@@ -0,0 +1,174 @@
+package etcdbackup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type etcdBackupTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldSuspend      bool
+	newSuspend      bool
+	username        string
+	operation       admissionv1.Operation
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "batch/v1",
+	"kind": "CronJob",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"schedule": "0 */6 * * *",
+		"suspend": %t
+	}
+}`
+
+func runEtcdBackupTests(t *testing.T, tests []etcdBackupTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "batch",
+		Version: "v1",
+		Kind:    "CronJob",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "batch",
+		Version:  "v1",
+		Resource: "cronjobs",
+	}
+
+	for _, test := range tests {
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.oldSuspend)),
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.newSuspend)),
+		}
+		if test.operation == admissionv1.Delete {
+			// testutils sends obj as the OldObject of a DELETE
+			obj = oldObj
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s/%s CronJob. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []etcdBackupTestSuites{
+		{
+			testID:          "user-cant-delete-managed-backup",
+			targetNamespace: "openshift-etcd",
+			targetName:      "managed-etcd-backup",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-suspend-managed-backup",
+			targetNamespace: "openshift-etcd",
+			targetName:      "managed-etcd-backup",
+			newSuspend:      true,
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runEtcdBackupTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []etcdBackupTestSuites{
+		{
+			testID:          "user-can-delete-customer-backup",
+			targetNamespace: "customer-ns",
+			targetName:      "my-backup",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-make-benign-change-to-managed-backup",
+			targetNamespace: "openshift-etcd",
+			targetName:      "managed-etcd-backup",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "etcd-operator-can-delete-managed-backup",
+			targetNamespace: "openshift-etcd",
+			targetName:      "managed-etcd-backup",
+			username:        "system:serviceaccount:openshift-etcd-operator:etcd-operator",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-etcd-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runEtcdBackupTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedBackupCronJobs
+	defer func() { managedBackupCronJobs = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"openshift-etcd-backup/hourly"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []etcdBackupTestSuites{
+		{
+			testID:          "user-cant-delete-configured-backup",
+			targetNamespace: "openshift-etcd-backup",
+			targetName:      "hourly",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-backup",
+			targetNamespace: "openshift-etcd",
+			targetName:      "managed-etcd-backup",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runEtcdBackupTests(t, tests)
+}
@@ -0,0 +1,221 @@
+package featuregate
+
+import (
+	"fmt"
+	"net/http"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName     string = "featuregate-validation"
+	docString       string = `Managed OpenShift Customers may not set the cluster FeatureGate feature set to %s, nor enable custom feature gates other than %s.`
+	featureGateKind string = "FeatureGate"
+	configGroup     string = "config.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{configGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"featuregates"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// disallowedFeatureSets are the feature sets which leave the cluster
+	// unsupported or unable to upgrade
+	disallowedFeatureSets = []string{
+		string(configv1.TechPreviewNoUpgrade),
+	}
+	// allowedCustomFeatureGates are the only gates which may be enabled
+	// through the CustomNoUpgrade feature set
+	allowedCustomFeatureGates = []string{}
+)
+
+// FeatureGateWebhook keeps customers from enabling unsupported feature gates
+type FeatureGateWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *FeatureGateWebhook {
+	return &FeatureGateWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *FeatureGateWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *FeatureGateWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newFeatureGate, oldFeatureGate, err := s.renderOldAndNewFeatureGates(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a FeatureGate from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	featureSet := string(newFeatureGate.Spec.FeatureSet)
+	if featureSet != string(oldFeatureGate.Spec.FeatureSet) && utils.SliceContains(featureSet, disallowedFeatureSets) {
+		log.Info(fmt.Sprintf("Change of feature set to %s detected on FeatureGate %s", featureSet, newFeatureGate.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Setting the feature set of the cluster FeatureGate to %s is not allowed", featureSet))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if gates := disallowedCustomGates(newFeatureGate, oldFeatureGate); len(gates) > 0 {
+		log.Info(fmt.Sprintf("Enabling of custom feature gates %v detected on FeatureGate %s", gates, newFeatureGate.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Enabling the custom feature gates %v is not allowed", gates))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewFeatureGates decodes both the Object and OldObject of the
+// request. Return order is: new, old, error.
+func (s *FeatureGateWebhook) renderOldAndNewFeatureGates(request admissionctl.Request) (*configv1.FeatureGate, *configv1.FeatureGate, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &configv1.FeatureGate{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("FeatureGate UPDATE request is missing an object")
+	}
+
+	return newObj.(*configv1.FeatureGate), oldObj.(*configv1.FeatureGate), nil
+}
+
+// disallowedCustomGates returns the custom feature gates the update newly
+// enables, which are not in allowedCustomFeatureGates. Gates which were
+// already enabled are left alone, so an update doesn't fail on a
+// pre-existing configuration.
+func disallowedCustomGates(newFeatureGate, oldFeatureGate *configv1.FeatureGate) []string {
+	if newFeatureGate.Spec.FeatureSet != configv1.CustomNoUpgrade || newFeatureGate.Spec.CustomNoUpgrade == nil {
+		return nil
+	}
+	var previouslyEnabled []string
+	if oldFeatureGate.Spec.FeatureSet == configv1.CustomNoUpgrade && oldFeatureGate.Spec.CustomNoUpgrade != nil {
+		previouslyEnabled = oldFeatureGate.Spec.CustomNoUpgrade.Enabled
+	}
+
+	var disallowed []string
+	for _, gate := range newFeatureGate.Spec.CustomNoUpgrade.Enabled {
+		if !utils.SliceContains(gate, allowedCustomFeatureGates) && !utils.SliceContains(gate, previouslyEnabled) {
+			disallowed = append(disallowed, gate)
+		}
+	}
+	return disallowed
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *FeatureGateWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *FeatureGateWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == featureGateKind)
+	valid = valid && (request.Kind.Group == configGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *FeatureGateWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *FeatureGateWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *FeatureGateWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *FeatureGateWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *FeatureGateWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *FeatureGateWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *FeatureGateWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *FeatureGateWebhook) Doc() string {
+	return fmt.Sprintf(docString, disallowedFeatureSets, allowedCustomFeatureGates)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *FeatureGateWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,125 @@
+package featuregate
+
+import (
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type featureGateTestSuites struct {
+	testID          string
+	oldObject       string
+	newObject       string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const (
+	defaultFeatureGateRaw  string = `{"apiVersion": "config.openshift.io/v1", "kind": "FeatureGate", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {}}`
+	techPreviewRaw         string = `{"apiVersion": "config.openshift.io/v1", "kind": "FeatureGate", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"featureSet": "TechPreviewNoUpgrade"}}`
+	customGatesRaw         string = `{"apiVersion": "config.openshift.io/v1", "kind": "FeatureGate", "metadata": {"name": "cluster", "uid": "1234"}, "spec": {"featureSet": "CustomNoUpgrade", "customNoUpgrade": {"enabled": ["SomeAlphaGate"]}}}`
+	labelledFeatureGateRaw string = `{"apiVersion": "config.openshift.io/v1", "kind": "FeatureGate", "metadata": {"name": "cluster", "uid": "1234", "labels": {"team": "platform"}}, "spec": {}}`
+)
+
+func runFeatureGateTests(t *testing.T, tests []featureGateTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "config.openshift.io",
+		Version: "v1",
+		Kind:    "FeatureGate",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "featuregates",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{Raw: []byte(test.newObject)}
+		oldObj := runtime.RawExtension{Raw: []byte(test.oldObject)}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s update the FeatureGate. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []featureGateTestSuites{
+		{
+			testID:          "user-cant-enable-techpreview",
+			oldObject:       defaultFeatureGateRaw,
+			newObject:       techPreviewRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-enable-custom-gates",
+			oldObject:       defaultFeatureGateRaw,
+			newObject:       customGatesRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runFeatureGateTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []featureGateTestSuites{
+		{
+			testID:          "user-can-make-noop-update",
+			oldObject:       defaultFeatureGateRaw,
+			newObject:       labelledFeatureGateRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "backplane-can-enable-techpreview",
+			oldObject:       defaultFeatureGateRaw,
+			newObject:       techPreviewRaw,
+			username:        "backplane-cluster-admin",
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: true,
+		},
+	}
+	runFeatureGateTests(t, tests)
+}
+
+func TestAllowedCustomFeatureGates(t *testing.T) {
+	oldGates := allowedCustomFeatureGates
+	defer func() { allowedCustomFeatureGates = oldGates }()
+	allowedCustomFeatureGates = []string{"SomeAlphaGate"}
+
+	tests := []featureGateTestSuites{
+		{
+			testID:          "user-can-enable-allowed-custom-gate",
+			oldObject:       defaultFeatureGateRaw,
+			newObject:       customGatesRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runFeatureGateTests(t, tests)
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
-	admissionv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -22,7 +21,7 @@ const (
 // if it made by a customer to manage hive-labeled resources
 type HiveOwnershipWebhook struct {
 	mu sync.Mutex
-	s  runtime.Scheme
+	s  *runtime.Scheme
 }
 
 var (
@@ -130,10 +129,7 @@ func (s *HiveOwnershipWebhook) SyncSetLabelSelector() metav1.LabelSelector {
 
 // NewWebhook creates a new webhook
 func NewWebhook() *HiveOwnershipWebhook {
-	scheme := runtime.NewScheme()
-	admissionv1.AddToScheme(scheme)
-
 	return &HiveOwnershipWebhook{
-		s: *scheme,
+		s: utils.Scheme,
 	}
 }
@@ -0,0 +1,194 @@
+package hpa
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type hpaTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	username        string
+	operation       admissionv1.Operation
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "autoscaling/v2",
+	"kind": "HorizontalPodAutoscaler",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"scaleTargetRef": {
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"name": "%s"
+		},
+		"minReplicas": 2,
+		"maxReplicas": %d
+	}
+}`
+
+func runHPATests(t *testing.T, tests []hpaTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "autoscaling",
+		Version: "v2",
+		Kind:    "HorizontalPodAutoscaler",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "autoscaling",
+		Version:  "v2",
+		Resource: "horizontalpodautoscalers",
+	}
+
+	for _, test := range tests {
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.targetName, 4)),
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.targetName, 40)),
+		}
+		if test.operation == admissionv1.Delete {
+			// testutils sends obj as the OldObject of a DELETE
+			obj = oldObj
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s/%s HorizontalPodAutoscaler. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []hpaTestSuites{
+		{
+			testID:          "user-cant-update-managed-hpa",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "thanos-querier",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-managed-hpa",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runHPATests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []hpaTestSuites{
+		{
+			testID:          "user-can-update-customer-hpa",
+			targetNamespace: "customer-ns",
+			targetName:      "my-app",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-hpa",
+			targetNamespace: "customer-ns",
+			targetName:      "my-app",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "monitoring-operator-can-update-managed-hpa",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "thanos-querier",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-delete-managed-hpa",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			username:        "srep-user",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "platform-identity-can-delete-managed-hpa",
+			targetNamespace: "openshift-console",
+			targetName:      "console",
+			username:        "system:serviceaccount:openshift-cluster-version:default",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-version"},
+			shouldBeAllowed: true,
+		},
+	}
+	runHPATests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedHPAs
+	defer func() { managedHPAs = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"openshift-ingress/router-default"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []hpaTestSuites{
+		{
+			testID:          "user-cant-update-configured-hpa",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-default",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-update-unconfigured-hpa",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "thanos-querier",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runHPATests(t, tests)
+}
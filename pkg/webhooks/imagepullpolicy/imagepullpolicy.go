@@ -0,0 +1,290 @@
+package imagepullpolicy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "imagepullpolicy-validation"
+	docString   string = `Managed OpenShift Customers may not create or update Pods in namespaces matching any of %s unless every container pulls its image with one of the following policies: %s`
+	podKind     string = "Pod"
+	// allowedPullPoliciesParameter is the parameter of the configuration file
+	// setting the comma-separated allowedPullPolicies
+	allowedPullPoliciesParameter string = "allowedPullPolicies"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"*"},
+				Resources:   []string{"pods"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// enforcedNamespaces are regular expressions matching the namespaces in
+	// which the image pull policies of Pods are enforced. None are unless
+	// configured.
+	enforcedNamespaces = []string{}
+	// allowedPullPolicies are the image pull policies containers may use in
+	// the enforced namespaces. Never runs whatever image is cached on the
+	// node, however stale; set Always alone to always check the registry.
+	allowedPullPolicies = []string{
+		string(corev1.PullAlways),
+		string(corev1.PullIfNotPresent),
+	}
+	// pullPolicies are the valid image pull policies
+	pullPolicies = []string{
+		string(corev1.PullAlways),
+		string(corev1.PullIfNotPresent),
+		string(corev1.PullNever),
+	}
+)
+
+func init() {
+	hookconfig.RegisterPolicySource(WebhookName, effectivePolicy)
+	hookconfig.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &allowedPullPolicies, &enforcedNamespaces)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings hookconfig.WebhookSettings) (func(), error) {
+	if settings.Mode == hookconfig.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var policies []string
+	for name, value := range settings.Parameters {
+		if name != allowedPullPoliciesParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		for _, policy := range strings.Split(value, ",") {
+			policy = strings.TrimSpace(policy)
+			if !utils.SliceContains(policy, pullPolicies) {
+				return nil, fmt.Errorf("%s must be made of %v, not %q", allowedPullPoliciesParameter, pullPolicies, policy)
+			}
+			policies = append(policies, policy)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if policies != nil {
+			allowedPullPolicies = policies
+		}
+		if settings.Protected != nil {
+			enforcedNamespaces = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for hookconfig.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"allowedPullPolicies": allowedPullPolicies,
+		"enforcedNamespaces":  enforcedNamespaces,
+		"allowedUsers":        allowedUsers,
+		"allowedGroups":       allowedGroups,
+	}
+}
+
+// ImagePullPolicyWebhook keeps Pods in the enforced namespaces from running
+// cached, possibly stale, images
+type ImagePullPolicyWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *ImagePullPolicyWebhook {
+	return &ImagePullPolicyWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *ImagePullPolicyWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *ImagePullPolicyWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	pod, err := s.renderPod(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Pod from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if !utils.RegexSliceContains(pod.Namespace, enforcedNamespaces) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if container, policy := disallowedContainer(pod); container != "" {
+		log.Info(fmt.Sprintf("Pod %s/%s with container %s pulling its image with policy %s detected", pod.Namespace, pod.Name, container, policy))
+		ret = admissionctl.Denied(fmt.Sprintf("Container %s may not pull its image with policy %s in namespace %s, use one of %v", container, policy, pod.Namespace, allowedPullPolicies))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderPod renders the Pod being created or updated
+func (s *ImagePullPolicyWebhook) renderPod(request admissionctl.Request) (*corev1.Pod, error) {
+	newObj, _, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.Pod{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if newObj == nil {
+		return nil, fmt.Errorf("Pod %s request is missing an object", request.Operation)
+	}
+	pod := newObj.(*corev1.Pod)
+	// The namespace may be left out of the object on CREATE
+	if pod.Namespace == "" {
+		pod.Namespace = request.Namespace
+	}
+	return pod, nil
+}
+
+// disallowedContainer returns the first container, init and ephemeral
+// containers included, whose image pull policy isn't allowed, along with
+// that policy. The API server defaults the policy before calling webhooks.
+func disallowedContainer(pod *corev1.Pod) (string, corev1.PullPolicy) {
+	for _, container := range pod.Spec.InitContainers {
+		if !utils.SliceContains(string(container.ImagePullPolicy), allowedPullPolicies) {
+			return container.Name, container.ImagePullPolicy
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if !utils.SliceContains(string(container.ImagePullPolicy), allowedPullPolicies) {
+			return container.Name, container.ImagePullPolicy
+		}
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if !utils.SliceContains(string(container.ImagePullPolicy), allowedPullPolicies) {
+			return container.Name, container.ImagePullPolicy
+		}
+	}
+	return "", ""
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *ImagePullPolicyWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *ImagePullPolicyWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == podKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *ImagePullPolicyWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *ImagePullPolicyWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *ImagePullPolicyWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *ImagePullPolicyWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *ImagePullPolicyWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *ImagePullPolicyWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *ImagePullPolicyWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *ImagePullPolicyWebhook) Doc() string {
+	return fmt.Sprintf(docString, enforcedNamespaces, allowedPullPolicies)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *ImagePullPolicyWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,219 @@
+package imagepullpolicy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type imagePullPolicyTestSuites struct {
+	testID          string
+	targetNamespace string
+	pullPolicy      string
+	initPullPolicy  string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {
+		"name": "test-pod",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"initContainers": [
+			{
+				"name": "init",
+				"image": "quay.io/test/init:latest",
+				"imagePullPolicy": "%s"
+			}
+		],
+		"containers": [
+			{
+				"name": "app",
+				"image": "quay.io/test/app:latest",
+				"imagePullPolicy": "%s"
+			}
+		]
+	}
+}`
+
+func runImagePullPolicyTests(t *testing.T, tests []imagePullPolicyTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Pod",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}
+
+	for _, test := range tests {
+		initPullPolicy := test.initPullPolicy
+		if initPullPolicy == "" {
+			initPullPolicy = "Always"
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetNamespace, initPullPolicy, test.pullPolicy)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s create a Pod pulling with policies %s/%s in %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), initPullPolicy, test.pullPolicy, test.targetNamespace, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+// enforce makes the tests enforce the pull policies in the namespaces, none
+// of which are enforced until configured
+func enforce(namespaces ...string) func() {
+	oldNamespaces := enforcedNamespaces
+	enforcedNamespaces = namespaces
+	return func() { enforcedNamespaces = oldNamespaces }
+}
+
+func TestUserNegative(t *testing.T) {
+	defer enforce("^openshift-.*")()
+
+	tests := []imagePullPolicyTestSuites{
+		{
+			testID:          "user-cant-create-pod-never-pulling",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "Never",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-create-pod-with-init-container-never-pulling",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "Always",
+			initPullPolicy:  "Never",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runImagePullPolicyTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	defer enforce("^openshift-.*")()
+
+	tests := []imagePullPolicyTestSuites{
+		{
+			testID:          "user-can-create-pod-always-pulling",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "Always",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-create-pod-pulling-if-not-present",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "IfNotPresent",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-create-pod-never-pulling-in-unenforced-namespace",
+			targetNamespace: "my-project",
+			pullPolicy:      "Never",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-create-pod-never-pulling",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "Never",
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runImagePullPolicyTests(t, tests)
+}
+
+func TestConfiguredPullPolicies(t *testing.T) {
+	defer enforce("^openshift-.*")()
+	oldPolicies := allowedPullPolicies
+	defer func() { allowedPullPolicies = oldPolicies }()
+	apply, err := applySettings(config.WebhookSettings{Parameters: map[string]string{allowedPullPoliciesParameter: "Always"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []imagePullPolicyTestSuites{
+		{
+			testID:          "user-cant-create-pod-pulling-if-not-present",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "IfNotPresent",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-create-pod-always-pulling",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "Always",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runImagePullPolicyTests(t, tests)
+
+	for _, parameters := range []map[string]string{
+		{allowedPullPoliciesParameter: "Sometimes"},
+		{allowedPullPoliciesParameter: ""},
+		{"forbiddenPullPolicies": "Never"},
+	} {
+		if _, err := applySettings(config.WebhookSettings{Parameters: parameters}); err == nil {
+			t.Fatalf("Expected parameters %v to be rejected", parameters)
+		}
+	}
+}
+
+func TestNoEnforcedNamespaces(t *testing.T) {
+	tests := []imagePullPolicyTestSuites{
+		{
+			testID:          "user-can-create-pod-never-pulling-until-configured",
+			targetNamespace: "openshift-gitops",
+			pullPolicy:      "Never",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runImagePullPolicyTests(t, tests)
+}
@@ -0,0 +1,226 @@
+package imageregistry
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName         string = "imageregistry-validation"
+	docString           string = `Managed OpenShift Customers may not set the managementState of the cluster image registry Config to %s, or remove its storage configuration.`
+	registryConfigKind  string = "Config"
+	registryConfigGroup string = "imageregistry.operator.openshift.io"
+	// registryConfigName is the name of the singleton image registry Config
+	registryConfigName string = "cluster"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{registryConfigGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"configs"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-image-registry:cluster-image-registry-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// disruptiveManagementStates are the managementState values which take
+	// the internal registry away
+	disruptiveManagementStates = []string{
+		"Removed",
+	}
+)
+
+// ImageRegistryWebhook protects the cluster image registry Config
+type ImageRegistryWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *ImageRegistryWebhook {
+	return &ImageRegistryWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *ImageRegistryWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *ImageRegistryWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newConfig, oldConfig, err := s.renderOldAndNewConfigs(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an image registry Config from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if oldConfig.GetName() != registryConfigName {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	managementState, _, _ := unstructured.NestedString(newConfig.Object, "spec", "managementState")
+	if utils.SliceContains(managementState, disruptiveManagementStates) {
+		log.Info(fmt.Sprintf("Image registry managementState change to %s detected", managementState))
+		ret = admissionctl.Denied(fmt.Sprintf("Setting the managementState of the cluster image registry Config to %s is not allowed", managementState))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if hasStorage(oldConfig) && !hasStorage(newConfig) {
+		log.Info("Removal of the image registry storage configuration detected")
+		ret = admissionctl.Denied("Removing the storage configuration of the cluster image registry Config is not allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewConfigs decodes both the Object and OldObject of the request.
+// Storage is checked for presence regardless of the backend, so the object is
+// decoded generically. Return order is: new, old, error.
+func (s *ImageRegistryWebhook) renderOldAndNewConfigs(request admissionctl.Request) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	newConfig := &unstructured.Unstructured{}
+	oldConfig := &unstructured.Unstructured{}
+
+	err = decoder.DecodeRaw(request.Object, newConfig)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	err = decoder.DecodeRaw(request.OldObject, oldConfig)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+
+	return newConfig, oldConfig, nil
+}
+
+// hasStorage checks if any storage backend is configured
+func hasStorage(config *unstructured.Unstructured) bool {
+	storage, found, err := unstructured.NestedMap(config.Object, "spec", "storage")
+	if err != nil || !found {
+		return false
+	}
+	// managementState only records who manages the storage, it isn't a backend
+	delete(storage, "managementState")
+	return len(storage) > 0
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *ImageRegistryWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *ImageRegistryWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == registryConfigKind)
+	valid = valid && (request.Kind.Group == registryConfigGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *ImageRegistryWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *ImageRegistryWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *ImageRegistryWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *ImageRegistryWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *ImageRegistryWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *ImageRegistryWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *ImageRegistryWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *ImageRegistryWebhook) Doc() string {
+	return fmt.Sprintf(docString, disruptiveManagementStates)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *ImageRegistryWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,145 @@
+package imageregistry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type imageRegistryTestSuites struct {
+	testID             string
+	oldManagementState string
+	newManagementState string
+	oldStorage         string
+	newStorage         string
+	oldReplicas        int
+	newReplicas        int
+	username           string
+	userGroups         []string
+	shouldBeAllowed    bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "imageregistry.operator.openshift.io/v1",
+	"kind": "Config",
+	"metadata": {
+		"name": "cluster",
+		"uid": "1234"
+	},
+	"spec": {
+		"managementState": "%s",
+		"replicas": %d,
+		"storage": %s
+	}
+}`
+
+const (
+	s3Storage    string = `{"s3": {"bucket": "cluster-image-registry", "region": "us-east-1"}, "managementState": "Managed"}`
+	emptyStorage string = `{"managementState": "Managed"}`
+)
+
+func runImageRegistryTests(t *testing.T, tests []imageRegistryTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "imageregistry.operator.openshift.io",
+		Version: "v1",
+		Kind:    "Config",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "imageregistry.operator.openshift.io",
+		Version:  "v1",
+		Resource: "configs",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.newManagementState, test.newReplicas, test.newStorage)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.oldManagementState, test.oldReplicas, test.oldStorage)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: %s (groups=%s) %s update the image registry Config. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []imageRegistryTestSuites{
+		{
+			testID:             "user-cant-remove-registry",
+			oldManagementState: "Managed",
+			newManagementState: "Removed",
+			oldStorage:         s3Storage,
+			newStorage:         s3Storage,
+			oldReplicas:        2,
+			newReplicas:        2,
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    false,
+		},
+		{
+			testID:             "user-cant-remove-storage",
+			oldManagementState: "Managed",
+			newManagementState: "Managed",
+			oldStorage:         s3Storage,
+			newStorage:         emptyStorage,
+			oldReplicas:        2,
+			newReplicas:        2,
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    false,
+		},
+	}
+	runImageRegistryTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []imageRegistryTestSuites{
+		{
+			testID:             "user-can-tune-replicas",
+			oldManagementState: "Managed",
+			newManagementState: "Managed",
+			oldStorage:         s3Storage,
+			newStorage:         s3Storage,
+			oldReplicas:        2,
+			newReplicas:        3,
+			username:           "user1",
+			userGroups:         []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:    true,
+		},
+		{
+			testID:             "registry-operator-can-remove-registry",
+			oldManagementState: "Managed",
+			newManagementState: "Removed",
+			oldStorage:         s3Storage,
+			newStorage:         emptyStorage,
+			oldReplicas:        2,
+			newReplicas:        2,
+			username:           "system:serviceaccount:openshift-image-registry:cluster-image-registry-operator",
+			userGroups:         []string{"system:serviceaccounts", "system:serviceaccounts:openshift-image-registry"},
+			shouldBeAllowed:    true,
+		},
+	}
+	runImageRegistryTests(t, tests)
+}
@@ -0,0 +1,321 @@
+package ingresstls
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "ingress-tls-validation"
+	docString   string = `Managed OpenShift Customers may not create or update Routes or Ingresses whose TLS settings allow a protocol older than %s, or weak ciphers`
+	routeKind   string = "Route"
+	ingressKind string = "Ingress"
+
+	// minTLSVersionParameter is the parameter of the configuration file
+	// setting minTLSVersion
+	minTLSVersionParameter string = "minTLSVersion"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"route.openshift.io"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"routes"},
+				Scope:       &scope,
+			},
+		},
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"networking.k8s.io"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"ingresses"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-ingress-operator:ingress-operator",
+		// Routes of Ingresses are created by the controller, from an
+		// Ingress which was checked already
+		"system:serviceaccount:openshift-infra:ingress-to-route-controller",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// tlsVersions are the TLS protocols, oldest first, as named by OpenSSL
+	tlsVersions = []string{"SSLv2", "SSLv3", "TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"}
+	// minTLSVersion is the oldest of tlsVersions which may be enabled
+	minTLSVersion = "TLSv1.2"
+	// protocolsAnnotations are the annotations listing the enabled TLS
+	// protocols, separated with spaces
+	protocolsAnnotations = []string{
+		"nginx.ingress.kubernetes.io/ssl-protocols",
+	}
+	// ciphersAnnotations are the annotations holding an OpenSSL cipher list,
+	// separated with colons
+	ciphersAnnotations = []string{
+		"nginx.ingress.kubernetes.io/ssl-ciphers",
+	}
+	// weakCipherParts are the parts of OpenSSL cipher names which make them
+	// weak
+	weakCipherParts = []string{"NULL", "EXPORT", "EXP", "DES", "3DES", "RC2", "RC4", "MD5", "aNULL", "eNULL"}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &minTLSVersion)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	if settings.Protected != nil {
+		return nil, fmt.Errorf("protected is not supported")
+	}
+	for name, value := range settings.Parameters {
+		if name != minTLSVersionParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		if !utils.SliceContains(value, tlsVersions) {
+			return nil, fmt.Errorf("%s must be one of %v, not %q", minTLSVersionParameter, tlsVersions, value)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if value, ok := settings.Parameters[minTLSVersionParameter]; ok {
+			minTLSVersion = value
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"minTLSVersion": minTLSVersion,
+		"allowedUsers":  allowedUsers,
+		"allowedGroups": allowedGroups,
+	}
+}
+
+// IngressTLSWebhook keeps the TLS settings of customer Routes and Ingresses
+// compliant
+type IngressTLSWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *IngressTLSWebhook {
+	return &IngressTLSWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *IngressTLSWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *IngressTLSWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	obj, err := s.renderObject(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an object from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if problem := tlsProblem(obj.GetAnnotations()); problem != "" {
+		log.Info(fmt.Sprintf("Non-compliant TLS settings detected on %s %s/%s: %s", request.Kind.Kind, obj.GetNamespace(), obj.GetName(), problem))
+		ret = admissionctl.Denied(fmt.Sprintf("The TLS settings of %s %s are not allowed: %s", request.Kind.Kind, obj.GetName(), problem))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// tlsProblem describes the first non-compliant TLS setting in the
+// annotations, or returns an empty string
+func tlsProblem(annotations map[string]string) string {
+	minIndex := versionIndex(minTLSVersion)
+	for _, annotation := range protocolsAnnotations {
+		for _, protocol := range strings.Fields(annotations[annotation]) {
+			// Unknown protocols are left to the ingress controller to reject
+			if i := versionIndex(protocol); i >= 0 && i < minIndex {
+				return fmt.Sprintf("%s enables %s, older than %s", annotation, protocol, minTLSVersion)
+			}
+		}
+	}
+	for _, annotation := range ciphersAnnotations {
+		if weak := weakCiphers(annotations[annotation]); len(weak) > 0 {
+			return fmt.Sprintf("%s enables weak ciphers %v", annotation, weak)
+		}
+	}
+	return ""
+}
+
+// versionIndex returns the index of the protocol in tlsVersions, or -1
+func versionIndex(protocol string) int {
+	for i, version := range tlsVersions {
+		if version == protocol {
+			return i
+		}
+	}
+	return -1
+}
+
+// weakCiphers returns the sorted entries of the OpenSSL cipher list which
+// enable a weak cipher. Entries removing ciphers, starting with ! or -, are
+// fine.
+func weakCiphers(list string) []string {
+	weak := []string{}
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ':' || r == ',' || r == ' ' }) {
+		if strings.HasPrefix(entry, "!") || strings.HasPrefix(entry, "-") {
+			continue
+		}
+		for _, part := range strings.Split(strings.TrimPrefix(entry, "+"), "-") {
+			if utils.SliceContains(part, weakCipherParts) {
+				weak = append(weak, entry)
+				break
+			}
+		}
+	}
+	sort.Strings(weak)
+	return weak
+}
+
+// renderObject renders the Route or Ingress being created or updated. Only
+// its annotations are inspected, so it is decoded generically.
+func (s *IngressTLSWebhook) renderObject(request admissionctl.Request) (*unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if len(request.Object.Raw) == 0 {
+		return nil, fmt.Errorf("%s %s request is missing an object", request.Kind.Kind, request.Operation)
+	}
+	if err := decoder.DecodeRaw(request.Object, obj); err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	return obj, nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *IngressTLSWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *IngressTLSWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == routeKind || request.Kind.Kind == ingressKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *IngressTLSWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *IngressTLSWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *IngressTLSWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *IngressTLSWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *IngressTLSWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *IngressTLSWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *IngressTLSWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *IngressTLSWebhook) Doc() string {
+	return fmt.Sprintf(docString, minTLSVersion)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *IngressTLSWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,238 @@
+package ingresstls
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type ingressTLSTestSuites struct {
+	testID          string
+	kind            string
+	protocols       string
+	ciphers         string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const (
+	testRouteRaw string = `
+{
+	"apiVersion": "route.openshift.io/v1",
+	"kind": "Route",
+	"metadata": {
+		"name": "my-app",
+		"namespace": "my-project",
+		"uid": "1234",
+		"annotations": {
+			"nginx.ingress.kubernetes.io/ssl-protocols": "%s",
+			"nginx.ingress.kubernetes.io/ssl-ciphers": "%s"
+		}
+	},
+	"spec": {
+		"host": "my-app.apps.example.com",
+		"to": {"kind": "Service", "name": "my-app"},
+		"tls": {"termination": "edge"}
+	}
+}`
+	testIngressRaw string = `
+{
+	"apiVersion": "networking.k8s.io/v1",
+	"kind": "Ingress",
+	"metadata": {
+		"name": "my-app",
+		"namespace": "my-project",
+		"uid": "1234",
+		"annotations": {
+			"nginx.ingress.kubernetes.io/ssl-protocols": "%s",
+			"nginx.ingress.kubernetes.io/ssl-ciphers": "%s"
+		}
+	},
+	"spec": {
+		"tls": [{"hosts": ["my-app.apps.example.com"], "secretName": "my-app-tls"}]
+	}
+}`
+	compliantCiphers string = "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES256-GCM-SHA384:!RC4:!3DES"
+)
+
+func runIngressTLSTests(t *testing.T, tests []ingressTLSTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "route.openshift.io",
+			Version: "v1",
+			Kind:    "Route",
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    "route.openshift.io",
+			Version:  "v1",
+			Resource: "routes",
+		}
+		raw := testRouteRaw
+		if test.kind == ingressKind {
+			gvk = metav1.GroupVersionKind{
+				Group:   "networking.k8s.io",
+				Version: "v1",
+				Kind:    "Ingress",
+			}
+			gvr = metav1.GroupVersionResource{
+				Group:    "networking.k8s.io",
+				Version:  "v1",
+				Resource: "ingresses",
+			}
+			raw = testIngressRaw
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(raw, test.protocols, test.ciphers)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s create the %s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), gvk.Kind, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []ingressTLSTestSuites{
+		{
+			testID:          "user-cant-enable-tls-1.0-on-ingress",
+			kind:            ingressKind,
+			protocols:       "TLSv1 TLSv1.1 TLSv1.2",
+			ciphers:         compliantCiphers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-enable-sslv3-on-route",
+			kind:            routeKind,
+			protocols:       "SSLv3 TLSv1.2",
+			ciphers:         compliantCiphers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-enable-rc4",
+			kind:            ingressKind,
+			protocols:       "TLSv1.2 TLSv1.3",
+			ciphers:         "ECDHE-RSA-AES256-GCM-SHA384:RC4-SHA",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-enable-3des",
+			kind:            routeKind,
+			protocols:       "TLSv1.2",
+			ciphers:         "ECDHE-RSA-AES256-GCM-SHA384:DES-CBC3-SHA",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runIngressTLSTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []ingressTLSTestSuites{
+		{
+			testID:          "user-can-create-compliant-ingress",
+			kind:            ingressKind,
+			protocols:       "TLSv1.2 TLSv1.3",
+			ciphers:         compliantCiphers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-create-compliant-route",
+			kind:            routeKind,
+			protocols:       "TLSv1.3",
+			ciphers:         compliantCiphers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-leave-tls-settings-to-the-controller",
+			kind:            ingressKind,
+			protocols:       "",
+			ciphers:         "",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "route-controller-can-copy-ingress",
+			kind:            routeKind,
+			protocols:       "TLSv1 TLSv1.2",
+			ciphers:         compliantCiphers,
+			username:        "system:serviceaccount:openshift-infra:ingress-to-route-controller",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-infra"},
+			shouldBeAllowed: true,
+		},
+	}
+	runIngressTLSTests(t, tests)
+}
+
+func TestConfiguredMinTLSVersion(t *testing.T) {
+	oldMinTLSVersion := minTLSVersion
+	defer func() { minTLSVersion = oldMinTLSVersion }()
+	apply, err := applySettings(config.WebhookSettings{Parameters: map[string]string{minTLSVersionParameter: "TLSv1.3"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []ingressTLSTestSuites{
+		{
+			testID:          "user-cant-enable-tls-1.2",
+			kind:            ingressKind,
+			protocols:       "TLSv1.2 TLSv1.3",
+			ciphers:         compliantCiphers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-enable-tls-1.3",
+			kind:            ingressKind,
+			protocols:       "TLSv1.3",
+			ciphers:         compliantCiphers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runIngressTLSTests(t, tests)
+
+	for _, parameters := range []map[string]string{
+		{minTLSVersionParameter: "TLSv2"},
+		{"maxTLSVersion": "TLSv1.3"},
+	} {
+		if _, err := applySettings(config.WebhookSettings{Parameters: parameters}); err == nil {
+			t.Fatalf("Expected parameters %v to be rejected", parameters)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package kubeadmin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type kubeadminTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Secret",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"data": {
+		"kubeadmin": "%s"
+	}
+}`
+
+func runKubeadminTests(t *testing.T, tests []kubeadminTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Secret",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "secrets",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, "bmV3")),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, "b2xk")),
+		}
+		if test.operation == admissionv1.Delete {
+			obj = oldObj
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s/%s Secret in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []kubeadminTestSuites{
+		{
+			testID:          "user-cant-delete-kubeadmin",
+			targetNamespace: "kube-system",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-update-kubeadmin",
+			targetNamespace: "kube-system",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "dedicated-admin-cant-delete-kubeadmin",
+			targetNamespace: "kube-system",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"dedicated-admins", "system:authenticated"},
+			shouldBeAllowed: false,
+		},
+	}
+	runKubeadminTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []kubeadminTestSuites{
+		{
+			testID:          "rotation-sa-can-update-kubeadmin",
+			targetNamespace: "kube-system",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-backplane-managed-scripts:script-runner",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-backplane-managed-scripts"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-delete-kubeadmin",
+			targetNamespace: "kube-system",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Delete,
+			username:        "srep-user",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-update-other-secret",
+			targetNamespace: "kube-system",
+			targetName:      "my-secret",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-kubeadmin-named-secret-elsewhere",
+			targetNamespace: "my-namespace",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runKubeadminTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := emergencyAccessSecrets
+	defer func() { emergencyAccessSecrets = oldInventory }()
+	emergencyAccessSecrets = []string{"my-namespace/break-glass"}
+
+	tests := []kubeadminTestSuites{
+		{
+			testID:          "user-cant-delete-configured-secret",
+			targetNamespace: "my-namespace",
+			targetName:      "break-glass",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-update-unconfigured-kubeadmin",
+			targetNamespace: "kube-system",
+			targetName:      "kubeadmin",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runKubeadminTests(t, tests)
+}
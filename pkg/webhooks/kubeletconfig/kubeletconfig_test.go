@@ -0,0 +1,155 @@
+package kubeletconfig
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type kubeletConfigTestSuites struct {
+	testID          string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "machineconfiguration.openshift.io/v1",
+	"kind": "KubeletConfig",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"kubeletConfig": {
+			"evictionHard": {
+				"memory.available": "500Mi"
+			}
+		},
+		"machineConfigPoolSelector": {
+			"matchLabels": {
+				"pools.operator.machineconfiguration.openshift.io/worker": ""
+			}
+		}
+	}
+}`
+
+func runKubeletConfigTests(t *testing.T, tests []kubeletConfigTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "machineconfiguration.openshift.io",
+		Version: "v1",
+		Kind:    "KubeletConfig",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "machineconfiguration.openshift.io",
+		Version:  "v1",
+		Resource: "kubeletconfigs",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the %s KubeletConfig. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []kubeletConfigTestSuites{
+		{
+			testID:          "user-cant-modify-managed-kubeletconfig",
+			targetName:      "managed-kubelet-config",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-managed-kubeletconfig",
+			targetName:      "managed-kubelet-config",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runKubeletConfigTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []kubeletConfigTestSuites{
+		{
+			testID:          "user-can-modify-customer-kubeletconfig",
+			targetName:      "customer-kubelet-config",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "mco-can-modify-managed-kubeletconfig",
+			targetName:      "managed-kubelet-config",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-machine-config-operator:machine-config-controller",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-machine-config-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runKubeletConfigTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedKubeletConfigs
+	defer func() { managedKubeletConfigs = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"set-max-pods"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []kubeletConfigTestSuites{
+		{
+			testID:          "user-cant-modify-configured-kubeletconfig",
+			targetName:      "set-max-pods",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-modify-unconfigured-kubeletconfig",
+			targetName:      "managed-kubelet-config",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runKubeletConfigTests(t, tests)
+}
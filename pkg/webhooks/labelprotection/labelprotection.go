@@ -0,0 +1,365 @@
+package labelprotection
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "label-protection-validation"
+	docString   string = `Managed OpenShift Customers may not perform the following operations on objects labelled %s=%s: %s`
+	// protectionLabelParameter is the parameter of the configuration file
+	// setting the protectionLabel and protectionValue, as label=value
+	protectionLabelParameter string = "protectionLabel"
+)
+
+// protectedResource describes a set of resources in an API group and the
+// operations which are denied on those objects carrying the protection label
+type protectedResource struct {
+	Group      string
+	Resources  []string
+	Operations []admissionregv1.OperationType
+}
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.AllScopes
+
+	// protectionLabel and protectionValue mark an object as immutable for
+	// non-managed identities
+	protectionLabel = "api.openshift.com/protected"
+	protectionValue = "true"
+	// protectedResources are the resources the protection label is enforced on
+	protectedResources = []protectedResource{
+		{
+			Group:      "",
+			Resources:  []string{"configmaps", "secrets"},
+			Operations: []admissionregv1.OperationType{admissionregv1.Update, admissionregv1.Delete},
+		},
+		{
+			Group:      "rbac.authorization.k8s.io",
+			Resources:  []string{"clusterrolebindings", "clusterroles", "rolebindings", "roles"},
+			Operations: []admissionregv1.OperationType{admissionregv1.Update, admissionregv1.Delete},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &protectedResources, &protectionLabel, &protectionValue)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it. The protected list sets the
+// protectedResources, see parseProtectedResource. The API server only calls
+// the webhook for the rules and object selector of its deployed
+// configuration, which build/syncset.go generates from the built-in
+// settings, so resources, operations or a label which the file adds are
+// only enforced once they are part of that configuration too.
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var resources []protectedResource
+	for _, entry := range settings.Protected {
+		pr, err := parseProtectedResource(entry)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, pr)
+	}
+	var label, value string
+	for name, parameter := range settings.Parameters {
+		if name != protectionLabelParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		parts := strings.SplitN(parameter, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%s %q must be in the form label=value", protectionLabelParameter, parameter)
+		}
+		label, value = parts[0], parts[1]
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			protectedResources = resources
+		}
+		if label != "" {
+			protectionLabel, protectionValue = label, value
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// parseProtectedResource parses an entry of the protected list, in the form
+// resource.group:OPERATION,OPERATION, eg clusterroles.rbac.authorization.k8s.io:DELETE.
+// The group is left out for the core API group, eg configmaps:UPDATE, and
+// the operations default to UPDATE and DELETE. CONNECT is not an operation
+// on the object itself, so it can't be protected by its label.
+func parseProtectedResource(entry string) (protectedResource, error) {
+	resource, operations := entry, ""
+	if i := strings.Index(entry, ":"); i >= 0 {
+		resource, operations = entry[:i], entry[i+1:]
+	}
+	pr := protectedResource{
+		Operations: []admissionregv1.OperationType{admissionregv1.Update, admissionregv1.Delete},
+	}
+	if i := strings.Index(resource, "."); i >= 0 {
+		resource, pr.Group = resource[:i], resource[i+1:]
+	}
+	if resource == "" {
+		return protectedResource{}, fmt.Errorf("protected resource %q has no resource", entry)
+	}
+	pr.Resources = []string{resource}
+	if operations == "" {
+		return pr, nil
+	}
+	pr.Operations = nil
+	for _, operation := range strings.Split(operations, ",") {
+		switch op := admissionregv1.OperationType(strings.TrimSpace(operation)); op {
+		case admissionregv1.Create, admissionregv1.Update, admissionregv1.Delete:
+			pr.Operations = append(pr.Operations, op)
+		default:
+			return protectedResource{}, fmt.Errorf("protected resource %q has an unknown operation %q", entry, operation)
+		}
+	}
+	return pr, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"protectionLabel":    protectionLabel,
+		"protectionValue":    protectionValue,
+		"protectedResources": protectedResources,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// LabelProtectionWebhook denies changes to objects carrying the protection
+// label, for any of the configured resources
+type LabelProtectionWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *LabelProtectionWebhook {
+	return &LabelProtectionWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *LabelProtectionWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *LabelProtectionWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if !isProtectedOperation(request) || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	obj, err := s.renderObject(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an object from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if obj.GetLabels()[protectionLabel] == protectionValue {
+		log.Info(fmt.Sprintf("%s operation detected on protected %s: %s/%s", request.Operation, request.Kind.Kind, obj.GetNamespace(), obj.GetName()))
+		ret = admissionctl.Denied(fmt.Sprintf("%s of %s objects labelled %s=%s is not allowed", request.Operation, request.Kind.Kind, protectionLabel, protectionValue))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderObject renders the existing object targeted by the request, or the
+// new object on CREATE. Any resource may be configured, so the object is
+// decoded generically.
+func (s *LabelProtectionWebhook) renderObject(request admissionctl.Request) (*unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+
+	if len(request.OldObject.Raw) > 0 {
+		err = decoder.DecodeRaw(request.OldObject, obj)
+	} else if len(request.Object.Raw) > 0 {
+		err = decoder.DecodeRaw(request.Object, obj)
+	}
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// findProtectedResource returns the protectedResource configuration matching
+// the resource of the request, if any
+func findProtectedResource(request admissionctl.Request) (protectedResource, bool) {
+	for _, pr := range protectedResources {
+		if pr.Group == request.Resource.Group && utils.SliceContains(request.Resource.Resource, pr.Resources) {
+			return pr, true
+		}
+	}
+	return protectedResource{}, false
+}
+
+// isProtectedOperation checks if the operation of the request is denied for
+// its resource
+func isProtectedOperation(request admissionctl.Request) bool {
+	pr, ok := findProtectedResource(request)
+	if !ok {
+		return false
+	}
+	for _, op := range pr.Operations {
+		if string(op) == string(request.Operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *LabelProtectionWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *LabelProtectionWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	_, protected := findProtectedResource(request)
+	valid = valid && protected
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *LabelProtectionWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *LabelProtectionWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *LabelProtectionWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface. There is one rule per configured
+// protectedResource.
+func (s *LabelProtectionWebhook) Rules() []admissionregv1.RuleWithOperations {
+	rules := []admissionregv1.RuleWithOperations{}
+	for _, pr := range protectedResources {
+		rules = append(rules, admissionregv1.RuleWithOperations{
+			Operations: pr.Operations,
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{pr.Group},
+				APIVersions: []string{"*"},
+				Resources:   pr.Resources,
+				Scope:       &scope,
+			},
+		})
+	}
+	return rules
+}
+
+// ObjectSelector implements Webhook interface. Only objects carrying the
+// protection label are sent to the webhook.
+func (s *LabelProtectionWebhook) ObjectSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			protectionLabel: protectionValue,
+		},
+	}
+}
+
+// SideEffects implements Webhook interface
+func (s *LabelProtectionWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *LabelProtectionWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *LabelProtectionWebhook) Doc() string {
+	protected := []string{}
+	for _, pr := range protectedResources {
+		for _, resource := range pr.Resources {
+			gr := resource
+			if pr.Group != "" {
+				gr = resource + "." + pr.Group
+			}
+			protected = append(protected, fmt.Sprintf("%s %v", gr, pr.Operations))
+		}
+	}
+	return fmt.Sprintf(docString, protectionLabel, protectionValue, protected)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *LabelProtectionWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,324 @@
+package labelprotection
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type labelProtectionTestSuites struct {
+	testID          string
+	gvk             metav1.GroupVersionKind
+	gvr             metav1.GroupVersionResource
+	labels          string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "%s",
+	"kind": "%s",
+	"metadata": {
+		"name": "managed-object",
+		"namespace": "openshift-config",
+		"uid": "1234",
+		"labels": %s
+	}
+}`
+
+var (
+	configMapGVK = metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+	configMapGVR = metav1.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+
+	clusterRoleGVK = metav1.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+	clusterRoleGVR = metav1.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+)
+
+const (
+	protectedLabels   string = `{"api.openshift.com/protected": "true"}`
+	unprotectedLabels string = `{"app": "customer"}`
+)
+
+func createRawJSONString(gvk metav1.GroupVersionKind, labels string) string {
+	apiVersion := gvk.Version
+	if gvk.Group != "" {
+		apiVersion = gvk.Group + "/" + gvk.Version
+	}
+	return fmt.Sprintf(testObjectRaw, apiVersion, gvk.Kind, labels)
+}
+
+func runLabelProtectionTests(t *testing.T, tests []labelProtectionTestSuites) {
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(createRawJSONString(test.gvk, test.labels)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, test.gvk, test.gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: %s (groups=%s) %s %s the %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.gvk.Kind, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []labelProtectionTestSuites{
+		{
+			testID:          "user-cant-update-protected-configmap",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-protected-configmap",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-update-protected-clusterrole",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-protected-clusterrole",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runLabelProtectionTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []labelProtectionTestSuites{
+		{
+			testID:          "user-can-update-unlabelled-configmap",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          unprotectedLabels,
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-unlabelled-clusterrole",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          unprotectedLabels,
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "sre-can-delete-protected-clusterrole",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Delete,
+			username:        "sre-user",
+			userGroups:      []string{"system:authenticated", "system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runLabelProtectionTests(t, tests)
+}
+
+func TestPerOperationProtection(t *testing.T) {
+	oldResources := protectedResources
+	defer func() { protectedResources = oldResources }()
+	protectedResources = []protectedResource{
+		{
+			Group:      "",
+			Resources:  []string{"configmaps"},
+			Operations: []admissionregv1.OperationType{admissionregv1.Delete},
+		},
+	}
+
+	tests := []labelProtectionTestSuites{
+		{
+			testID:          "delete-only-protection-allows-update",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "delete-only-protection-denies-delete",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runLabelProtectionTests(t, tests)
+}
+
+func TestCreateProtection(t *testing.T) {
+	oldResources := protectedResources
+	defer func() { protectedResources = oldResources }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"configmaps:CREATE"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []labelProtectionTestSuites{
+		{
+			testID:          "create-protection-denies-labelled-create",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "create-protection-allows-unlabelled-create",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          unprotectedLabels,
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "create-protection-allows-srep-create",
+			gvk:             configMapGVK,
+			gvr:             configMapGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Create,
+			username:        "srep-user",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runLabelProtectionTests(t, tests)
+}
+
+func TestConfigFileSettings(t *testing.T) {
+	oldResources, oldLabel, oldValue := protectedResources, protectionLabel, protectionValue
+	defer func() { protectedResources, protectionLabel, protectionValue = oldResources, oldLabel, oldValue }()
+	apply, err := applySettings(config.WebhookSettings{
+		Protected:  []string{"clusterroles.rbac.authorization.k8s.io:DELETE"},
+		Parameters: map[string]string{protectionLabelParameter: "example.com/locked=yes"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	lockedLabels := `{"example.com/locked": "yes"}`
+	tests := []labelProtectionTestSuites{
+		{
+			testID:          "configured-label-denies-configured-operation",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          lockedLabels,
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "configured-label-allows-unconfigured-operation",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          lockedLabels,
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "built-in-label-no-longer-protects",
+			gvk:             clusterRoleGVK,
+			gvr:             clusterRoleGVR,
+			labels:          protectedLabels,
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runLabelProtectionTests(t, tests)
+
+	if _, ok := findProtectedResource(admissionctl.Request{AdmissionRequest: admissionv1.AdmissionRequest{Resource: configMapGVR}}); ok {
+		t.Fatalf("Expected the configured resources to replace the built-in ones")
+	}
+}
+
+func TestParseProtectedResource(t *testing.T) {
+	pr, err := parseProtectedResource("configmaps")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	expected := protectedResource{
+		Group:      "",
+		Resources:  []string{"configmaps"},
+		Operations: []admissionregv1.OperationType{admissionregv1.Update, admissionregv1.Delete},
+	}
+	if !reflect.DeepEqual(pr, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, pr)
+	}
+
+	for _, entry := range []string{".rbac.authorization.k8s.io", "configmaps:PATCH", "configmaps:CONNECT"} {
+		if _, err := parseProtectedResource(entry); err == nil {
+			t.Fatalf("Expected %q to be rejected", entry)
+		}
+	}
+	if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{protectionLabelParameter: "example.com/locked"}}); err == nil {
+		t.Fatalf("Expected a protection label without a value to be rejected")
+	}
+}
@@ -0,0 +1,242 @@
+package limitrange
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName    string = "limitrange-validation"
+	docString      string = `Managed OpenShift Customers may not modify or delete managed LimitRanges, whose namespace/name matches any of: %s`
+	limitRangeKind string = "LimitRange"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"limitranges"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:kube-system:namespace-controller",
+		"system:serviceaccount:openshift-resource-quota-operator:resource-quota-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedLimitRanges is the inventory of managed LimitRanges, as regular
+	// expressions matched against namespace/name. This allows a LimitRange
+	// stamped into every tenant namespace to be covered by a single entry.
+	managedLimitRanges = []string{
+		`^[^/]+/managed-limitrange$`,
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedLimitRanges)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	for _, entry := range settings.Protected {
+		if _, err := regexp.Compile(entry); err != nil {
+			return nil, fmt.Errorf("protected entry %q is not a valid regular expression: %v", entry, err)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedLimitRanges = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedLimitRanges": managedLimitRanges,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// LimitRangeWebhook protects managed LimitRanges in tenant namespaces
+type LimitRangeWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *LimitRangeWebhook {
+	return &LimitRangeWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *LimitRangeWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *LimitRangeWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	limitRange, err := s.renderLimitRange(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a LimitRange from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if limitRange != nil && isManagedLimitRange(limitRange) && !isAllowedUserGroup(request) {
+		name := limitRange.Namespace + "/" + limitRange.Name
+		switch request.Operation {
+		case admissionv1.Delete:
+			log.Info(fmt.Sprintf("Deleting operation detected on managed LimitRange: %v", name))
+			ret = admissionctl.Denied(fmt.Sprintf("Deleting managed LimitRange %v is not allowed", name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		case admissionv1.Update:
+			log.Info(fmt.Sprintf("Updating operation detected on managed LimitRange: %v", name))
+			ret = admissionctl.Denied(fmt.Sprintf("Modifying managed LimitRange %v is not allowed", name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderLimitRange renders the existing LimitRange from the request. It is
+// nil when the request carries no OldObject.
+func (s *LimitRangeWebhook) renderLimitRange(request admissionctl.Request) (*corev1.LimitRange, error) {
+	_, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.LimitRange{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if oldObj == nil {
+		return nil, nil
+	}
+
+	return oldObj.(*corev1.LimitRange), nil
+}
+
+// isManagedLimitRange checks if the LimitRange is in the managed inventory
+func isManagedLimitRange(limitRange *corev1.LimitRange) bool {
+	return utils.RegexSliceContains(limitRange.Namespace+"/"+limitRange.Name, managedLimitRanges)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *LimitRangeWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *LimitRangeWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == limitRangeKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *LimitRangeWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *LimitRangeWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *LimitRangeWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *LimitRangeWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *LimitRangeWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *LimitRangeWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *LimitRangeWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *LimitRangeWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedLimitRanges)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *LimitRangeWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,174 @@
+package limitrange
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type limitRangeTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "LimitRange",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"limits": [
+			{
+				"type": "Container",
+				"max": {
+					"cpu": "2"
+				}
+			}
+		]
+	}
+}`
+
+func runLimitRangeTests(t *testing.T, tests []limitRangeTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "LimitRange",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "limitranges",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the LimitRange %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []limitRangeTestSuites{
+		{
+			testID:          "user-cant-delete-managed-limitrange",
+			targetNamespace: "tenant-a",
+			targetName:      "managed-limitrange",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-limitrange",
+			targetNamespace: "tenant-b",
+			targetName:      "managed-limitrange",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runLimitRangeTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []limitRangeTestSuites{
+		{
+			testID:          "user-can-create-additional-limitrange",
+			targetNamespace: "tenant-a",
+			targetName:      "customer-limitrange",
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-limitrange",
+			targetNamespace: "tenant-a",
+			targetName:      "customer-limitrange",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "operator-can-modify-managed-limitrange",
+			targetNamespace: "tenant-a",
+			targetName:      "managed-limitrange",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-resource-quota-operator:resource-quota-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-resource-quota-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runLimitRangeTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedLimitRanges
+	defer func() { managedLimitRanges = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{`^tenant-a/platform-limits$`}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []limitRangeTestSuites{
+		{
+			testID:          "user-cant-delete-configured-limitrange",
+			targetNamespace: "tenant-a",
+			targetName:      "platform-limits",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-same-name-in-other-namespace",
+			targetNamespace: "tenant-b",
+			targetName:      "platform-limits",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runLimitRangeTests(t, tests)
+
+	if _, err := applySettings(config.WebhookSettings{Protected: []string{`^tenant-a/(platform-limits$`}}); err == nil {
+		t.Fatalf("Expected an invalid regular expression to be rejected")
+	}
+}
@@ -0,0 +1,291 @@
+package machineset
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName    string = "machineset-validation"
+	docString      string = `Managed OpenShift Customers may not delete, or scale to zero, the managed MachineSets matching the following patterns: %s`
+	machineSetKind string = "MachineSet"
+	scaleKind      string = "Scale"
+	machineGroup   string = "machine.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{machineGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"machinesets", "machinesets/scale"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-machine-api:machine-api-operator",
+		"system:serviceaccount:openshift-machine-api:machine-api-controllers",
+		"system:serviceaccount:openshift-machine-api:cluster-autoscaler",
+		"system:serviceaccount:kube-system:generic-garbage-collector",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedMachineSets is the inventory of managed MachineSets, as regular
+	// expressions matched against namespace/name. Their names start with the
+	// infrastructure ID of the cluster, so they can't be listed as is.
+	managedMachineSets = []string{
+		`^openshift-machine-api/.+-infra-[a-z0-9-]+$`,
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedMachineSets)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedMachineSets = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedMachineSets": managedMachineSets,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// MachineSetWebhook keeps the managed machine pools from being removed
+type MachineSetWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *MachineSetWebhook {
+	return &MachineSetWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *MachineSetWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *MachineSetWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	name, oldReplicas, newReplicas, err := s.renderMachineSet(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a MachineSet from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if utils.RegexSliceContains(name, managedMachineSets) {
+		switch {
+		case request.Operation == admissionv1.Delete:
+			log.Info(fmt.Sprintf("Deleting operation detected on managed MachineSet: %v", name))
+			ret = admissionctl.Denied(fmt.Sprintf("Deleting managed MachineSet %v is not allowed", name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		case request.Operation == admissionv1.Update && newReplicas == 0 && oldReplicas > 0:
+			log.Info(fmt.Sprintf("Scaling to zero detected on managed MachineSet: %v", name))
+			ret = admissionctl.Denied(fmt.Sprintf("Scaling managed MachineSet %v to zero is not allowed", name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderMachineSet returns the namespace/name of the MachineSet targeted by
+// the request, and its replicas before and after an UPDATE. Scaling through
+// the scale subresource sends Scales rather than the MachineSet.
+func (s *MachineSetWebhook) renderMachineSet(request admissionctl.Request) (string, int64, int64, error) {
+	if request.Kind.Kind == scaleKind {
+		return s.renderScales(request)
+	}
+
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	// The machine API types are not vendored, so the objects are decoded
+	// generically
+	decode := func(raw runtime.RawExtension) (*unstructured.Unstructured, int64, error) {
+		machineSet := &unstructured.Unstructured{}
+		if err := decoder.DecodeRaw(raw, machineSet); err != nil {
+			return nil, 0, err
+		}
+		replicas, found, err := unstructured.NestedInt64(machineSet.Object, "spec", "replicas")
+		if err != nil {
+			return nil, 0, err
+		}
+		// Replicas default to 1 when unset
+		if !found {
+			replicas = 1
+		}
+		return machineSet, replicas, nil
+	}
+
+	if len(request.OldObject.Raw) == 0 {
+		return "", 0, 0, fmt.Errorf("MachineSet %s request is missing the existing object", request.Operation)
+	}
+	oldMachineSet, oldReplicas, err := decode(request.OldObject)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return "", 0, 0, err
+	}
+	newReplicas := oldReplicas
+	if request.Operation == admissionv1.Update {
+		if len(request.Object.Raw) == 0 {
+			return "", 0, 0, fmt.Errorf("MachineSet %s request is missing an object", request.Operation)
+		}
+		if _, newReplicas, err = decode(request.Object); err != nil {
+			metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+			return "", 0, 0, err
+		}
+	}
+	return oldMachineSet.GetNamespace() + "/" + oldMachineSet.GetName(), oldReplicas, newReplicas, nil
+}
+
+// renderScales renders the Scales of an UPDATE of the scale subresource
+func (s *MachineSetWebhook) renderScales(request admissionctl.Request) (string, int64, int64, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &autoscalingv1.Scale{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return "", 0, 0, err
+	}
+	if newObj == nil || oldObj == nil {
+		return "", 0, 0, fmt.Errorf("Scale %s request is missing an object", request.Operation)
+	}
+	oldScale, newScale := oldObj.(*autoscalingv1.Scale), newObj.(*autoscalingv1.Scale)
+	return oldScale.Namespace + "/" + oldScale.Name, int64(oldScale.Spec.Replicas), int64(newScale.Spec.Replicas), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *MachineSetWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *MachineSetWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == machineSetKind || request.Kind.Kind == scaleKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *MachineSetWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *MachineSetWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *MachineSetWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *MachineSetWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *MachineSetWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *MachineSetWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *MachineSetWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *MachineSetWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedMachineSets)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *MachineSetWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
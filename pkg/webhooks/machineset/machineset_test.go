@@ -0,0 +1,237 @@
+package machineset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type machineSetTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	scale           bool
+	oldReplicas     int
+	newReplicas     int
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const (
+	testObjectRaw string = `
+{
+	"apiVersion": "machine.openshift.io/v1beta1",
+	"kind": "MachineSet",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"replicas": %d
+	}
+}`
+	testScaleRaw string = `
+{
+	"apiVersion": "autoscaling/v1",
+	"kind": "Scale",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"replicas": %d
+	}
+}`
+)
+
+func runMachineSetTests(t *testing.T, tests []machineSetTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "machine.openshift.io",
+			Version: "v1beta1",
+			Kind:    "MachineSet",
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    "machine.openshift.io",
+			Version:  "v1beta1",
+			Resource: "machinesets",
+		}
+		raw := testObjectRaw
+		if test.scale {
+			gvk = metav1.GroupVersionKind{
+				Group:   "autoscaling",
+				Version: "v1",
+				Kind:    "Scale",
+			}
+			raw = testScaleRaw
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(raw, test.targetName, test.targetNamespace, test.newReplicas)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(raw, test.targetName, test.targetNamespace, test.oldReplicas)),
+		}
+		if test.operation == admissionv1.Delete {
+			obj = oldObj
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the MachineSet %s/%s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []machineSetTestSuites{
+		{
+			testID:          "user-cant-scale-infra-to-zero",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1a",
+			operation:       admissionv1.Update,
+			oldReplicas:     1,
+			newReplicas:     0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-scale-infra-to-zero-through-subresource",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1a",
+			operation:       admissionv1.Update,
+			scale:           true,
+			oldReplicas:     1,
+			newReplicas:     0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-infra",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1b",
+			operation:       admissionv1.Delete,
+			oldReplicas:     1,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runMachineSetTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []machineSetTestSuites{
+		{
+			testID:          "user-can-scale-customer-machineset",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-gpu-us-east-1a",
+			operation:       admissionv1.Update,
+			oldReplicas:     2,
+			newReplicas:     0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-machineset",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-gpu-us-east-1a",
+			operation:       admissionv1.Delete,
+			oldReplicas:     2,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-scale-up-infra",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1a",
+			operation:       admissionv1.Update,
+			scale:           true,
+			oldReplicas:     1,
+			newReplicas:     2,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "autoscaler-can-scale-infra-to-zero",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1a",
+			operation:       admissionv1.Update,
+			scale:           true,
+			oldReplicas:     1,
+			newReplicas:     0,
+			username:        "system:serviceaccount:openshift-machine-api:cluster-autoscaler",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-machine-api"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "mao-can-delete-infra",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1a",
+			operation:       admissionv1.Delete,
+			oldReplicas:     1,
+			username:        "system:serviceaccount:openshift-machine-api:machine-api-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-machine-api"},
+			shouldBeAllowed: true,
+		},
+	}
+	runMachineSetTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedMachineSets
+	defer func() { managedMachineSets = oldInventory }()
+	managedMachineSets = []string{`^openshift-machine-api/.+-gpu-[a-z0-9-]+$`}
+
+	tests := []machineSetTestSuites{
+		{
+			testID:          "user-cant-scale-configured-machineset-to-zero",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-gpu-us-east-1a",
+			operation:       admissionv1.Update,
+			oldReplicas:     2,
+			newReplicas:     0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-scale-unconfigured-machineset-to-zero",
+			targetNamespace: "openshift-machine-api",
+			targetName:      "mycluster-x7k2p-infra-us-east-1a",
+			operation:       admissionv1.Update,
+			oldReplicas:     1,
+			newReplicas:     0,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runMachineSetTests(t, tests)
+}
@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"bytes"
+
+	"github.com/ghodss/yaml"
+)
+
+// manifestSeparator separates the documents of a YAML stream
+const manifestSeparator string = "---\n"
+
+// Manifests renders the webhook configuration of each of the hooks, in the
+// order of their names, as a single YAML stream ready to be applied. See
+// WebhookConfiguration for what each is rendered as. Hooks without rules are
+// skipped, as the API server would never call them.
+func Manifests(hooks RegisteredWebhooks, service ServiceConfig) ([]byte, error) {
+	var stream bytes.Buffer
+	for _, name := range hooks.Names() {
+		hook := hooks[name]()
+		if len(hook.Rules()) == 0 {
+			continue
+		}
+
+		manifest, err := WebhookConfiguration(hook, service)
+		if err != nil {
+			return nil, err
+		}
+		y, err := yaml.Marshal(manifest)
+		if err != nil {
+			return nil, err
+		}
+		stream.WriteString(manifestSeparator)
+		stream.Write(y)
+	}
+	return stream.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+func TestSCCManifest(t *testing.T) {
+	service := webhooks.ServiceConfig{Namespace: "openshift-validation-webhook", Name: "validation-webhook", Port: 443}
+	hooks := webhooks.RegisteredWebhooks{scc.WebhookName: webhooks.Webhooks[scc.WebhookName]}
+
+	manifests, err := webhooks.Manifests(hooks, service)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	golden, err := ioutil.ReadFile("testdata/scc-validation.yaml")
+	if err != nil {
+		t.Fatalf("Couldn't read the golden manifest: %s", err.Error())
+	}
+	if !bytes.Equal(manifests, golden) {
+		t.Fatalf("The manifest of %s doesn't match testdata/scc-validation.yaml, got:\n%s", scc.WebhookName, manifests)
+	}
+}
+
+func TestManifestsCoverEveryHook(t *testing.T) {
+	service := webhooks.ServiceConfig{Namespace: "openshift-validation-webhook", Name: "validation-webhook", Port: 443}
+
+	manifests, err := webhooks.Manifests(webhooks.Webhooks, service)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	for _, hook := range webhooks.Webhooks {
+		if len(hook().Rules()) == 0 {
+			continue
+		}
+		if !bytes.Contains(manifests, []byte("name: sre-"+hook().Name()+"\n")) {
+			t.Fatalf("Expected the manifests to carry the configuration of %s", hook().Name())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MatchCondition mirrors validatingwebhookconfiguration.webhooks[].matchConditions[],
+// which the vendored admissionregistration/v1 API predates. The API server,
+// from Kubernetes 1.28 on, only calls the webhook for the requests matching
+// all of its conditions.
+type MatchCondition struct {
+	// Name identifies the condition in the errors the API server reports
+	Name string `json:"name"`
+	// Expression is the CEL expression the request must evaluate to true
+	// for, eg `object.roleRef.name.startsWith('system:openshift:scc:')`
+	Expression string `json:"expression"`
+}
+
+// MatchConditioner may be implemented by a Webhook which can be filtered out
+// by the API server, saving the calls whose requests it would allow anyway
+type MatchConditioner interface {
+	// MatchConditions are the conditions the requests must match for the
+	// API server to call the webhook
+	MatchConditions() []MatchCondition
+}
+
+// WebhookConfiguration renders the configuration which registers the hook
+// with the API server: a MutatingWebhookConfiguration for a MutatingWebhook,
+// a ValidatingWebhookConfiguration for any other hook. The match conditions
+// of a MatchConditioner are set on it, which requires rendering it
+// unstructured as the typed configurations have no room for them.
+func WebhookConfiguration(hook Webhook, service ServiceConfig) (interface{}, error) {
+	var configuration interface{}
+	if mutating, ok := hook.(MutatingWebhook); ok {
+		mutatingConfiguration := MutatingWebhookConfiguration(mutating, service)
+		configuration = &mutatingConfiguration
+	} else {
+		validatingConfiguration := ValidatingWebhookConfiguration(hook, service)
+		configuration = &validatingConfiguration
+	}
+
+	conditioner, ok := hook.(MatchConditioner)
+	if !ok || len(conditioner.MatchConditions()) == 0 {
+		return configuration, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configuration)
+	if err != nil {
+		return nil, err
+	}
+	conditions := []interface{}{}
+	for _, condition := range conditioner.MatchConditions() {
+		conditions = append(conditions, map[string]interface{}{
+			"name":       condition.Name,
+			"expression": condition.Expression,
+		})
+	}
+	webhooks, _, err := unstructured.NestedSlice(content, "webhooks")
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.(map[string]interface{})["matchConditions"] = conditions
+	}
+	if err := unstructured.SetNestedSlice(content, webhooks, "webhooks"); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
@@ -0,0 +1,58 @@
+package webhooks_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+// sccRoleBindingWebhook is a webhook only called for the ClusterRoleBindings
+// granting the use of a SCC
+type sccRoleBindingWebhook struct {
+	webhooks.Webhook
+}
+
+func (sccRoleBindingWebhook) MatchConditions() []webhooks.MatchCondition {
+	return []webhooks.MatchCondition{
+		{
+			Name:       "scc-role",
+			Expression: "object.roleRef.name.startsWith('system:openshift:scc:')",
+		},
+	}
+}
+
+func TestMatchConditions(t *testing.T) {
+	service := webhooks.ServiceConfig{Namespace: "openshift-validation-webhook", Name: "validation-webhook", Port: 443}
+	hooks := webhooks.RegisteredWebhooks{
+		scc.WebhookName: func() webhooks.Webhook { return sccRoleBindingWebhook{scc.NewWebhook()} },
+	}
+
+	manifests, err := webhooks.Manifests(hooks, service)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	configuration := struct {
+		Kind     string `json:"kind"`
+		Webhooks []struct {
+			Name            string                    `json:"name"`
+			Rules           []interface{}             `json:"rules"`
+			MatchConditions []webhooks.MatchCondition `json:"matchConditions"`
+		} `json:"webhooks"`
+	}{}
+	if err := yaml.Unmarshal(manifests, &configuration); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if configuration.Kind != "ValidatingWebhookConfiguration" || len(configuration.Webhooks) != 1 {
+		t.Fatalf("Expected a ValidatingWebhookConfiguration with one webhook, got:\n%s", manifests)
+	}
+	webhook := configuration.Webhooks[0]
+	if webhook.Name != scc.WebhookName+".managed.openshift.io" || len(webhook.Rules) == 0 {
+		t.Fatalf("Expected the configuration of %s to be kept, got:\n%s", scc.WebhookName, manifests)
+	}
+	if expected := (sccRoleBindingWebhook{}).MatchConditions(); !reflect.DeepEqual(webhook.MatchConditions, expected) {
+		t.Fatalf("Expected the match conditions %v, got %v", expected, webhook.MatchConditions)
+	}
+}
@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"fmt"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MutatingWebhook is a Webhook which mutates the objects it admits, and is
+// therefore registered through a MutatingWebhookConfiguration
+type MutatingWebhook interface {
+	Webhook
+	// ReinvocationPolicy mirrors mutatingwebhookconfiguration.webhooks[].reinvocationPolicy.
+	// Return IfNeeded when another mutating webhook may undo the mutation.
+	ReinvocationPolicy() admissionregv1.ReinvocationPolicyType
+}
+
+// MutatingWebhookConfiguration renders the MutatingWebhookConfiguration which
+// registers the hook with the API server
+func MutatingWebhookConfiguration(hook MutatingWebhook, service ServiceConfig) admissionregv1.MutatingWebhookConfiguration {
+	failPolicy := hook.FailurePolicy()
+	timeout := hook.TimeoutSeconds()
+	matchPolicy := hook.MatchPolicy()
+	sideEffects := hook.SideEffects()
+	reinvocationPolicy := hook.ReinvocationPolicy()
+
+	return admissionregv1.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MutatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sre-%s", hook.Name()),
+
+			Annotations: map[string]string{
+				// service.beta.openshift.io/inject-cabundle annotation will instruct
+				// service-ca-operator to install a CA cert in the
+				// MutatingWebhookConfiguration object, which is required for
+				// Kubernetes to communicate securely to the Service.
+				"service.beta.openshift.io/inject-cabundle": "true",
+			},
+		},
+		Webhooks: []admissionregv1.MutatingWebhook{
+			{
+				AdmissionReviewVersions: []string{"v1"},
+				TimeoutSeconds:          &timeout,
+				SideEffects:             &sideEffects,
+				MatchPolicy:             &matchPolicy,
+				Name:                    fmt.Sprintf("%s.managed.openshift.io", hook.Name()),
+				NamespaceSelector:       namespaceSelector(hook),
+				ObjectSelector:          hook.ObjectSelector(),
+				FailurePolicy:           &failPolicy,
+				ReinvocationPolicy:      &reinvocationPolicy,
+				ClientConfig:            ClientConfig(hook, service),
+				Rules:                   hook.Rules(),
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"testing"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// fakeMutatingWebhook is a minimal MutatingWebhook
+type fakeMutatingWebhook struct{}
+
+func (f *fakeMutatingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return admissionctl.Allowed("")
+}
+func (f *fakeMutatingWebhook) GetURI() string                             { return "/fake-mutation" }
+func (f *fakeMutatingWebhook) Validate(request admissionctl.Request) bool { return true }
+func (f *fakeMutatingWebhook) Name() string                               { return "fake-mutation" }
+func (f *fakeMutatingWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+func (f *fakeMutatingWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+func (f *fakeMutatingWebhook) Rules() []admissionregv1.RuleWithOperations { return nil }
+func (f *fakeMutatingWebhook) ObjectSelector() *metav1.LabelSelector      { return nil }
+func (f *fakeMutatingWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+func (f *fakeMutatingWebhook) TimeoutSeconds() int32 { return 2 }
+func (f *fakeMutatingWebhook) Doc() string           { return "" }
+func (f *fakeMutatingWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return metav1.LabelSelector{}
+}
+func (f *fakeMutatingWebhook) ReinvocationPolicy() admissionregv1.ReinvocationPolicyType {
+	return admissionregv1.IfNeededReinvocationPolicy
+}
+
+func TestMutatingWebhookConfigurationReinvocationPolicy(t *testing.T) {
+	var hook Webhook = &fakeMutatingWebhook{}
+	mutating, ok := hook.(MutatingWebhook)
+	if !ok {
+		t.Fatalf("Expected the fake webhook to be a MutatingWebhook")
+	}
+
+	config := MutatingWebhookConfiguration(mutating, ServiceConfig{Namespace: "openshift-validation-webhook", Name: "validation-webhook", Port: 443})
+	if len(config.Webhooks) != 1 {
+		t.Fatalf("Expected a single webhook, got %d", len(config.Webhooks))
+	}
+	policy := config.Webhooks[0].ReinvocationPolicy
+	if policy == nil || *policy != admissionregv1.IfNeededReinvocationPolicy {
+		t.Fatalf("Expected reinvocationPolicy %s, got %v", admissionregv1.IfNeededReinvocationPolicy, policy)
+	}
+	if path := *config.Webhooks[0].ClientConfig.Service.Path; path != hook.GetURI() {
+		t.Fatalf("Expected path %s, got %s", hook.GetURI(), path)
+	}
+}
+
+func TestValidatingWebhooksAreNotMutating(t *testing.T) {
+	for name, hook := range Webhooks {
+		if _, ok := hook().(MutatingWebhook); ok {
+			t.Fatalf("Expected %s not to be a MutatingWebhook, as it is registered through a ValidatingWebhookConfiguration", name)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
@@ -65,7 +66,7 @@ var (
 // NamespaceWebhook validates a Namespace change
 type NamespaceWebhook struct {
 	mu sync.Mutex
-	s  runtime.Scheme
+	s  *runtime.Scheme
 }
 
 // ObjectSelector implements Webhook interface
@@ -126,7 +127,7 @@ func (s *NamespaceWebhook) Validate(req admissionctl.Request) bool {
 // (request.OldObject) objects returned. See the renderOldAndNewNamespaces
 // documentation for more.
 func (s *NamespaceWebhook) renderNamespace(req admissionctl.Request) (*corev1.Namespace, error) {
-	decoder, err := admissionctl.NewDecoder(&s.s)
+	decoder, err := admissionctl.NewDecoder(s.s)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +138,7 @@ func (s *NamespaceWebhook) renderNamespace(req admissionctl.Request) (*corev1.Na
 		err = decoder.Decode(req, namespace)
 	}
 	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, req.Kind.Kind)
 		return nil, err
 	}
 	return namespace, nil
@@ -151,7 +153,7 @@ func (s *NamespaceWebhook) renderNamespace(req admissionctl.Request) (*corev1.Na
 // If there is no corresponding namespace, this method will return nil in the
 // appropriate position.
 func (s *NamespaceWebhook) renderOldAndNewNamespaces(req admissionctl.Request) (*corev1.Namespace, *corev1.Namespace, error) {
-	decoder, err := admissionctl.NewDecoder(&s.s)
+	decoder, err := admissionctl.NewDecoder(s.s)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -162,6 +164,7 @@ func (s *NamespaceWebhook) renderOldAndNewNamespaces(req admissionctl.Request) (
 	} else {
 		err = decoder.DecodeRaw(req.OldObject, oldNamespace)
 		if err != nil {
+			metrics.IncrementDecodeErrors(WebhookName, req.Kind.Kind)
 			return nil, nil, err
 		}
 	}
@@ -172,6 +175,7 @@ func (s *NamespaceWebhook) renderOldAndNewNamespaces(req admissionctl.Request) (
 	} else {
 		err = decoder.Decode(req, newNamespace)
 		if err != nil {
+			metrics.IncrementDecodeErrors(WebhookName, req.Kind.Kind)
 			return nil, nil, err
 		}
 	}
@@ -319,12 +323,8 @@ func (s *NamespaceWebhook) SyncSetLabelSelector() metav1.LabelSelector {
 
 // NewWebhook creates a new webhook
 func NewWebhook() *NamespaceWebhook {
-	scheme := runtime.NewScheme()
-	admissionv1.AddToScheme(scheme)
-	corev1.AddToScheme(scheme)
-
 	return &NamespaceWebhook{
-		s: *scheme,
+		s: utils.Scheme,
 	}
 }
 
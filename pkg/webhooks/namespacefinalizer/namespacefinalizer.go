@@ -0,0 +1,267 @@
+package namespacefinalizer
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName   string = "namespace-finalizer-validation"
+	docString     string = `Managed OpenShift Customers may not remove the finalizers of platform namespaces, nor of namespaces matching any of %s, including through the finalize subresource, as force-deleting them orphans managed resources.`
+	namespaceKind string = "Namespace"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"*"},
+				Resources:   []string{"namespaces", "namespaces/finalize"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		// The namespace controller of the kube-controller-manager removes the
+		// kubernetes finalizer once the content of the namespace is deleted
+		"system:serviceaccount:kube-system:namespace-controller",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// protectedNamespaces are regular expressions matching the namespaces, on
+	// top of the platform ones, whose finalizers customers may not remove.
+	// See hookconfig.IsPrivilegedNamespace for the platform namespaces.
+	protectedNamespaces = []string{
+		"^openshift-.*",
+	}
+)
+
+func init() {
+	hookconfig.RegisterPolicySource(WebhookName, effectivePolicy)
+	hookconfig.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &protectedNamespaces)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings hookconfig.WebhookSettings) (func(), error) {
+	if settings.Mode == hookconfig.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			protectedNamespaces = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for hookconfig.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"protectedNamespaces": protectedNamespaces,
+		"allowedUsers":        allowedUsers,
+		"allowedGroups":       allowedGroups,
+	}
+}
+
+// NamespaceFinalizerWebhook keeps customers from force-deleting protected
+// namespaces by stripping their finalizers
+type NamespaceFinalizerWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *NamespaceFinalizerWebhook {
+	return &NamespaceFinalizerWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *NamespaceFinalizerWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *NamespaceFinalizerWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newNamespace, oldNamespace, err := s.renderOldAndNewNamespaces(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Namespace from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isProtected(oldNamespace.Name) {
+		if removed := removedFinalizers(oldNamespace, newNamespace); len(removed) > 0 {
+			log.Info(fmt.Sprintf("Removal of finalizers %v detected on protected namespace %s", removed, oldNamespace.Name), "subresource", request.SubResource)
+			ret = admissionctl.Denied(fmt.Sprintf("Removing the finalizers %v of namespace %s is not allowed, as force-deleting it orphans managed resources. Its controllers remove them once they are done", removed, oldNamespace.Name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// removedFinalizers returns the sorted finalizers of the old namespace which
+// are missing from the new one, both the metadata ones and the spec ones the
+// finalize subresource updates. Spec finalizers are prefixed with spec/.
+func removedFinalizers(oldNamespace, newNamespace *corev1.Namespace) []string {
+	removed := []string{}
+	for _, finalizer := range oldNamespace.Finalizers {
+		if !utils.SliceContains(finalizer, newNamespace.Finalizers) {
+			removed = append(removed, finalizer)
+		}
+	}
+	for _, finalizer := range oldNamespace.Spec.Finalizers {
+		if !hasSpecFinalizer(newNamespace, finalizer) {
+			removed = append(removed, "spec/"+string(finalizer))
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// hasSpecFinalizer checks if the spec of the namespace holds the finalizer
+func hasSpecFinalizer(namespace *corev1.Namespace, finalizer corev1.FinalizerName) bool {
+	for _, f := range namespace.Spec.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// renderOldAndNewNamespaces decodes both the Object and OldObject of the
+// UPDATE request
+func (s *NamespaceFinalizerWebhook) renderOldAndNewNamespaces(request admissionctl.Request) (*corev1.Namespace, *corev1.Namespace, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.Namespace{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("Namespace UPDATE request is missing an object")
+	}
+	return newObj.(*corev1.Namespace), oldObj.(*corev1.Namespace), nil
+}
+
+// isProtected checks if customers may not remove the finalizers of the
+// namespace
+func isProtected(namespace string) bool {
+	return hookconfig.IsPrivilegedNamespace(namespace) || utils.RegexSliceContains(namespace, protectedNamespaces)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *NamespaceFinalizerWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *NamespaceFinalizerWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == namespaceKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *NamespaceFinalizerWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *NamespaceFinalizerWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *NamespaceFinalizerWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *NamespaceFinalizerWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *NamespaceFinalizerWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *NamespaceFinalizerWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *NamespaceFinalizerWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *NamespaceFinalizerWebhook) Doc() string {
+	return fmt.Sprintf(docString, protectedNamespaces)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *NamespaceFinalizerWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,213 @@
+package namespacefinalizer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type namespaceFinalizerTestSuites struct {
+	testID          string
+	targetNamespace string
+	oldFinalizers   string
+	newFinalizers   string
+	oldSpec         string
+	newSpec         string
+	newLabels       string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Namespace",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234",
+		"labels": %s,
+		"finalizers": %s
+	},
+	"spec": {
+		"finalizers": %s
+	}
+}`
+
+const (
+	managedFinalizers string = `["operator.openshift.io/cleanup"]`
+	kubernetesSpec    string = `["kubernetes"]`
+	noFinalizers      string = `[]`
+	oldLabels         string = `{"team": "payments"}`
+)
+
+func runNamespaceFinalizerTests(t *testing.T, tests []namespaceFinalizerTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Namespace",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "namespaces",
+	}
+
+	for _, test := range tests {
+		newLabels := test.newLabels
+		if newLabels == "" {
+			newLabels = oldLabels
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetNamespace, newLabels, test.newFinalizers, test.newSpec)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetNamespace, oldLabels, test.oldFinalizers, test.oldSpec)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s update the finalizers of namespace %s from %s %s to %s %s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.oldFinalizers, test.oldSpec, test.newFinalizers, test.newSpec, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []namespaceFinalizerTestSuites{
+		{
+			testID:          "user-cant-remove-finalizer-of-protected-namespace",
+			targetNamespace: "openshift-gitops",
+			oldFinalizers:   managedFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         kubernetesSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-remove-kubernetes-finalizer-of-platform-namespace",
+			targetNamespace: "kube-system",
+			oldFinalizers:   noFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         noFinalizers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runNamespaceFinalizerTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []namespaceFinalizerTestSuites{
+		{
+			testID:          "user-can-change-label-of-protected-namespace",
+			targetNamespace: "openshift-monitoring",
+			oldFinalizers:   managedFinalizers,
+			newFinalizers:   managedFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         kubernetesSpec,
+			newLabels:       `{"team": "observability"}`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-add-finalizer-to-protected-namespace",
+			targetNamespace: "openshift-monitoring",
+			oldFinalizers:   noFinalizers,
+			newFinalizers:   managedFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         kubernetesSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-remove-finalizer-of-customer-namespace",
+			targetNamespace: "my-project",
+			oldFinalizers:   managedFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         noFinalizers,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "namespace-controller-can-remove-kubernetes-finalizer",
+			targetNamespace: "openshift-monitoring",
+			oldFinalizers:   noFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         noFinalizers,
+			username:        "system:serviceaccount:kube-system:namespace-controller",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:kube-system"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-remove-finalizer-of-protected-namespace",
+			targetNamespace: "openshift-monitoring",
+			oldFinalizers:   managedFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         kubernetesSpec,
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runNamespaceFinalizerTests(t, tests)
+}
+
+func TestConfiguredNamespaces(t *testing.T) {
+	oldNamespaces := protectedNamespaces
+	defer func() { protectedNamespaces = oldNamespaces }()
+	protectedNamespaces = []string{"^payments$"}
+
+	tests := []namespaceFinalizerTestSuites{
+		{
+			testID:          "user-cant-remove-finalizer-of-configured-namespace",
+			targetNamespace: "payments",
+			oldFinalizers:   managedFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         kubernetesSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-remove-finalizer-of-unconfigured-namespace",
+			targetNamespace: "openshift-gitops",
+			oldFinalizers:   managedFinalizers,
+			newFinalizers:   noFinalizers,
+			oldSpec:         kubernetesSpec,
+			newSpec:         kubernetesSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runNamespaceFinalizerTests(t, tests)
+}
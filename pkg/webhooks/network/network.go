@@ -0,0 +1,206 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "network-validation"
+	docString   string = `Managed OpenShift Customers may not change the network type, the cluster and service networks, or the node port range of the cluster Network config.`
+	networkKind string = "Network"
+	configGroup string = "config.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{configGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"networks"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-network-operator:cluster-network-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+)
+
+// NetworkWebhook protects the settings of the cluster Network config which
+// can't be changed without breaking the cluster
+type NetworkWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *NetworkWebhook {
+	return &NetworkWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *NetworkWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *NetworkWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newNetwork, oldNetwork, err := s.renderOldAndNewNetworks(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Network from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if changed := changedImmutableFields(newNetwork, oldNetwork); len(changed) > 0 {
+		log.Info(fmt.Sprintf("Change of %s detected on Network %s", strings.Join(changed, ", "), newNetwork.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Changing %s of the cluster Network config is not allowed", strings.Join(changed, ", ")))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewNetworks decodes both the Object and OldObject of the
+// request. Return order is: new, old, error.
+func (s *NetworkWebhook) renderOldAndNewNetworks(request admissionctl.Request) (*configv1.Network, *configv1.Network, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &configv1.Network{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("Network UPDATE request is missing an object")
+	}
+
+	return newObj.(*configv1.Network), oldObj.(*configv1.Network), nil
+}
+
+// changedImmutableFields returns the spec fields which may not change once
+// the cluster is installed, and differ between the two Networks
+func changedImmutableFields(newNetwork, oldNetwork *configv1.Network) []string {
+	changed := []string{}
+	if newNetwork.Spec.NetworkType != oldNetwork.Spec.NetworkType {
+		changed = append(changed, "spec.networkType")
+	}
+	if !reflect.DeepEqual(newNetwork.Spec.ClusterNetwork, oldNetwork.Spec.ClusterNetwork) {
+		changed = append(changed, "spec.clusterNetwork")
+	}
+	if !reflect.DeepEqual(newNetwork.Spec.ServiceNetwork, oldNetwork.Spec.ServiceNetwork) {
+		changed = append(changed, "spec.serviceNetwork")
+	}
+	if newNetwork.Spec.ServiceNodePortRange != oldNetwork.Spec.ServiceNodePortRange {
+		changed = append(changed, "spec.serviceNodePortRange")
+	}
+	return changed
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *NetworkWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *NetworkWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == networkKind)
+	valid = valid && (request.Kind.Group == configGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *NetworkWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *NetworkWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *NetworkWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *NetworkWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *NetworkWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *NetworkWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *NetworkWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *NetworkWebhook) Doc() string {
+	return docString
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *NetworkWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type networkTestSuites struct {
+	testID          string
+	newNetworkType  string
+	newServiceCIDR  string
+	newExternalIPs  string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const (
+	testObjectRaw string = `
+{
+	"apiVersion": "config.openshift.io/v1",
+	"kind": "Network",
+	"metadata": {
+		"name": "cluster",
+		"uid": "1234"
+	},
+	"spec": {
+		"networkType": "%s",
+		"clusterNetwork": [
+			{
+				"cidr": "10.128.0.0/14",
+				"hostPrefix": 23
+			}
+		],
+		"serviceNetwork": [
+			"%s"
+		],
+		"externalIP": {
+			"policy": {
+				"allowedCIDRs": [%s]
+			}
+		}
+	}
+}`
+	installedNetworkType string = "OpenShiftSDN"
+	installedServiceCIDR string = "172.30.0.0/16"
+)
+
+func runNetworkTests(t *testing.T, tests []networkTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "config.openshift.io",
+		Version: "v1",
+		Kind:    "Network",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "networks",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.newNetworkType, test.newServiceCIDR, test.newExternalIPs)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, installedNetworkType, installedServiceCIDR, "")),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s update the Network config. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []networkTestSuites{
+		{
+			testID:          "user-cant-change-network-type",
+			newNetworkType:  "OVNKubernetes",
+			newServiceCIDR:  installedServiceCIDR,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-change-service-network",
+			newNetworkType:  installedNetworkType,
+			newServiceCIDR:  "172.31.0.0/16",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runNetworkTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []networkTestSuites{
+		{
+			testID:          "user-can-allow-external-ips",
+			newNetworkType:  installedNetworkType,
+			newServiceCIDR:  installedServiceCIDR,
+			newExternalIPs:  `"192.168.0.0/24"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "network-operator-can-change-network-type",
+			newNetworkType:  "OVNKubernetes",
+			newServiceCIDR:  installedServiceCIDR,
+			username:        "system:serviceaccount:openshift-network-operator:cluster-network-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-network-operator"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-change-network-type",
+			newNetworkType:  "OVNKubernetes",
+			newServiceCIDR:  installedServiceCIDR,
+			username:        "srep-user",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runNetworkTests(t, tests)
+}
@@ -0,0 +1,278 @@
+package networkpolicy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName       string = "networkpolicy-validation"
+	docString         string = `Managed OpenShift Customers may not create or update NetworkPolicies allowing ingress from, or egress to, all peers in platform namespaces, nor in namespaces matching any of %s.`
+	networkPolicyKind string = "NetworkPolicy"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{networkingv1.GroupName},
+				APIVersions: []string{"*"},
+				Resources:   []string{"networkpolicies"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// isolatedNamespaces are regular expressions matching the namespaces, on
+	// top of the platform ones, whose isolation customers may not open up.
+	// See hookconfig.IsPrivilegedNamespace for the platform namespaces.
+	isolatedNamespaces = []string{
+		"^openshift-.*",
+	}
+	// allCIDRs are the ipBlocks matching every address
+	allCIDRs = []string{
+		"0.0.0.0/0",
+		"::/0",
+	}
+)
+
+func init() {
+	hookconfig.RegisterPolicySource(WebhookName, effectivePolicy)
+	hookconfig.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &isolatedNamespaces)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings hookconfig.WebhookSettings) (func(), error) {
+	if settings.Mode == hookconfig.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			isolatedNamespaces = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for hookconfig.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"isolatedNamespaces": isolatedNamespaces,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// NetworkPolicyWebhook keeps customers from opening up the isolation of the
+// platform namespaces with allow-all NetworkPolicies
+type NetworkPolicyWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *NetworkPolicyWebhook {
+	return &NetworkPolicyWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *NetworkPolicyWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *NetworkPolicyWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	policy, err := s.renderNetworkPolicy(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a NetworkPolicy from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isIsolated(policy.Namespace) {
+		if permissive := allowAllRules(policy); len(permissive) > 0 {
+			log.Info(fmt.Sprintf("Allow-all NetworkPolicy %s detected in isolated namespace %s: %v", policy.Name, policy.Namespace, permissive))
+			ret = admissionctl.Denied(fmt.Sprintf("NetworkPolicy %s may not allow traffic with all peers in namespace %s, as the platform isolates it. Restrict the %s with a podSelector, a non-empty namespaceSelector or a narrower ipBlock", policy.Name, policy.Namespace, strings.Join(permissive, ", ")))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderNetworkPolicy renders the NetworkPolicy being created or updated
+func (s *NetworkPolicyWebhook) renderNetworkPolicy(request admissionctl.Request) (*networkingv1.NetworkPolicy, error) {
+	newObj, _, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &networkingv1.NetworkPolicy{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if newObj == nil {
+		return nil, fmt.Errorf("NetworkPolicy %s request is missing an object", request.Operation)
+	}
+	return newObj.(*networkingv1.NetworkPolicy), nil
+}
+
+// isIsolated checks if the platform enforces the isolation of the namespace
+func isIsolated(namespace string) bool {
+	return hookconfig.IsPrivilegedNamespace(namespace) || utils.RegexSliceContains(namespace, isolatedNamespaces)
+}
+
+// allowAllRules describes the ingress and egress rules of the policy which
+// allow traffic with all peers: a rule without peers, or with a peer
+// selecting every namespace or every address
+func allowAllRules(policy *networkingv1.NetworkPolicy) []string {
+	var permissive []string
+	for i, rule := range policy.Spec.Ingress {
+		if allowsAll(rule.From) {
+			permissive = append(permissive, fmt.Sprintf("ingress rule %d", i))
+		}
+	}
+	for i, rule := range policy.Spec.Egress {
+		if allowsAll(rule.To) {
+			permissive = append(permissive, fmt.Sprintf("egress rule %d", i))
+		}
+	}
+	return permissive
+}
+
+// allowsAll checks if the peers of a rule match all traffic
+func allowsAll(peers []networkingv1.NetworkPolicyPeer) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peer.NamespaceSelector != nil && isEmptySelector(peer.NamespaceSelector) &&
+			(peer.PodSelector == nil || isEmptySelector(peer.PodSelector)) {
+			return true
+		}
+		if peer.IPBlock != nil && len(peer.IPBlock.Except) == 0 && utils.SliceContains(peer.IPBlock.CIDR, allCIDRs) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptySelector checks if the selector is `{}`, which selects everything
+func isEmptySelector(selector *metav1.LabelSelector) bool {
+	return len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *NetworkPolicyWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *NetworkPolicyWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == networkPolicyKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *NetworkPolicyWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *NetworkPolicyWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *NetworkPolicyWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *NetworkPolicyWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *NetworkPolicyWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *NetworkPolicyWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *NetworkPolicyWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *NetworkPolicyWebhook) Doc() string {
+	return fmt.Sprintf(docString, isolatedNamespaces)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *NetworkPolicyWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,172 @@
+package networkpolicy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type networkPolicyTestSuites struct {
+	testID          string
+	targetNamespace string
+	spec            string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "networking.k8s.io/v1",
+	"kind": "NetworkPolicy",
+	"metadata": {
+		"name": "test-policy",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": %s
+}`
+
+const (
+	allowAllIngressSpec    string = `{"podSelector": {}, "ingress": [{}], "policyTypes": ["Ingress"]}`
+	allNamespacesSpec      string = `{"podSelector": {}, "ingress": [{"from": [{"namespaceSelector": {}}]}], "policyTypes": ["Ingress"]}`
+	allAddressesEgressSpec string = `{"podSelector": {}, "egress": [{"to": [{"ipBlock": {"cidr": "0.0.0.0/0"}}]}], "policyTypes": ["Egress"]}`
+	scopedSpec             string = `{"podSelector": {"matchLabels": {"app": "web"}}, "ingress": [{"from": [{"namespaceSelector": {"matchLabels": {"name": "openshift-ingress"}}}]}], "policyTypes": ["Ingress"]}`
+	denyAllSpec            string = `{"podSelector": {}, "policyTypes": ["Ingress", "Egress"]}`
+)
+
+func runNetworkPolicyTests(t *testing.T, tests []networkPolicyTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "networking.k8s.io",
+		Version: "v1",
+		Kind:    "NetworkPolicy",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "networking.k8s.io",
+		Version:  "v1",
+		Resource: "networkpolicies",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetNamespace, test.spec)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s create the NetworkPolicy %s in %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.spec, test.targetNamespace, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []networkPolicyTestSuites{
+		{
+			testID:          "user-cant-allow-all-ingress-in-isolated-namespace",
+			targetNamespace: "openshift-monitoring",
+			spec:            allowAllIngressSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-allow-all-namespaces-in-isolated-namespace",
+			targetNamespace: "openshift-monitoring",
+			spec:            allNamespacesSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-allow-all-egress-in-platform-namespace",
+			targetNamespace: "kube-system",
+			spec:            allAddressesEgressSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runNetworkPolicyTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []networkPolicyTestSuites{
+		{
+			testID:          "user-can-create-scoped-policy-in-isolated-namespace",
+			targetNamespace: "openshift-monitoring",
+			spec:            scopedSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-deny-all-in-isolated-namespace",
+			targetNamespace: "openshift-monitoring",
+			spec:            denyAllSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-allow-all-in-customer-namespace",
+			targetNamespace: "my-project",
+			spec:            allowAllIngressSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-allow-all-in-isolated-namespace",
+			targetNamespace: "openshift-monitoring",
+			spec:            allowAllIngressSpec,
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runNetworkPolicyTests(t, tests)
+}
+
+func TestConfiguredNamespaces(t *testing.T) {
+	oldNamespaces := isolatedNamespaces
+	defer func() { isolatedNamespaces = oldNamespaces }()
+	isolatedNamespaces = []string{"^payments$"}
+
+	tests := []networkPolicyTestSuites{
+		{
+			testID:          "user-cant-allow-all-in-configured-namespace",
+			targetNamespace: "payments",
+			spec:            allowAllIngressSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-allow-all-in-unconfigured-namespace",
+			targetNamespace: "openshift-gitops",
+			spec:            allowAllIngressSpec,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runNetworkPolicyTests(t, tests)
+}
@@ -0,0 +1,203 @@
+package node
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "node-validation"
+	docString   string = `Managed OpenShift Customers may not cordon Nodes with any of the following roles: %s`
+	nodeKind    string = "Node"
+	// nodeRoleLabelPrefix prefixes the labels carrying the roles of a Node
+	nodeRoleLabelPrefix string = "node-role.kubernetes.io/"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"*"},
+				Resources:   []string{"nodes"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		// The MCO drains and cordons Nodes to roll out MachineConfigs
+		"system:serviceaccount:openshift-machine-config-operator:machine-config-daemon",
+		"system:serviceaccount:openshift-machine-config-operator:machine-config-controller",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// protectedNodeRoles are the roles of the Nodes which must not be cordoned
+	protectedNodeRoles = []string{
+		"master",
+		"infra",
+	}
+)
+
+// NodeWebhook keeps control plane and infra Nodes from being cordoned
+type NodeWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *NodeWebhook {
+	return &NodeWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *NodeWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *NodeWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newNode, oldNode, err := s.renderOldAndNewNodes(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Node from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if role := protectedRole(oldNode); role != "" && newNode.Spec.Unschedulable && !oldNode.Spec.Unschedulable {
+		log.Info(fmt.Sprintf("Cordoning of %s Node %s detected", role, newNode.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Cordoning %s Node %s is not allowed", role, newNode.Name))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewNodes decodes both the Object and OldObject of the request.
+// Return order is: new, old, error.
+func (s *NodeWebhook) renderOldAndNewNodes(request admissionctl.Request) (*corev1.Node, *corev1.Node, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.Node{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("Node UPDATE request is missing an object")
+	}
+
+	return newObj.(*corev1.Node), oldObj.(*corev1.Node), nil
+}
+
+// protectedRole returns the first protected role of the Node, if any. Roles
+// are checked on the existing Node, so that relabelling it in the same
+// request doesn't get around the check.
+func protectedRole(node *corev1.Node) string {
+	for _, role := range protectedNodeRoles {
+		if _, ok := node.Labels[nodeRoleLabelPrefix+role]; ok {
+			return role
+		}
+	}
+	return ""
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *NodeWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *NodeWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == nodeKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *NodeWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *NodeWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *NodeWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *NodeWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *NodeWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *NodeWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *NodeWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *NodeWebhook) Doc() string {
+	return fmt.Sprintf(docString, protectedNodeRoles)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *NodeWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
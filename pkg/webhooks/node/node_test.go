@@ -0,0 +1,139 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type nodeTestSuites struct {
+	testID           string
+	role             string
+	oldUnschedulable bool
+	newUnschedulable bool
+	username         string
+	userGroups       []string
+	shouldBeAllowed  bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Node",
+	"metadata": {
+		"name": "ip-10-0-1-1.ec2.internal",
+		"uid": "1234",
+		"labels": {
+			"kubernetes.io/os": "linux",
+			"node-role.kubernetes.io/%s": ""
+		}
+	},
+	"spec": {
+		"unschedulable": %t
+	}
+}`
+
+func runNodeTests(t *testing.T, tests []nodeTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Node",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "nodes",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.role, test.newUnschedulable)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.role, test.oldUnschedulable)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s update the %s Node. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.role, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []nodeTestSuites{
+		{
+			testID:           "user-cant-cordon-master",
+			role:             "master",
+			newUnschedulable: true,
+			username:         "user1",
+			userGroups:       []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:  false,
+		},
+		{
+			testID:           "user-cant-cordon-infra",
+			role:             "infra",
+			newUnschedulable: true,
+			username:         "user1",
+			userGroups:       []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:  false,
+		},
+	}
+	runNodeTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []nodeTestSuites{
+		{
+			testID:           "user-can-cordon-worker",
+			role:             "worker",
+			newUnschedulable: true,
+			username:         "user1",
+			userGroups:       []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:  true,
+		},
+		{
+			testID:           "user-can-uncordon-master",
+			role:             "master",
+			oldUnschedulable: true,
+			username:         "user1",
+			userGroups:       []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:  true,
+		},
+		{
+			testID:           "mco-can-cordon-master",
+			role:             "master",
+			newUnschedulable: true,
+			username:         "system:serviceaccount:openshift-machine-config-operator:machine-config-daemon",
+			userGroups:       []string{"system:serviceaccounts", "system:serviceaccounts:openshift-machine-config-operator"},
+			shouldBeAllowed:  true,
+		},
+		{
+			testID:           "srep-can-cordon-infra",
+			role:             "infra",
+			newUnschedulable: true,
+			username:         "srep-user",
+			userGroups:       []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed:  true,
+		},
+	}
+	runNodeTests(t, tests)
+}
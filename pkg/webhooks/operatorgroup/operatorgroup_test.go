@@ -0,0 +1,160 @@
+package operatorgroup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type operatorGroupTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "operators.coreos.com/v1",
+	"kind": "OperatorGroup",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"targetNamespaces": [
+			"%s"
+		]
+	}
+}`
+
+func runOperatorGroupTests(t *testing.T, tests []operatorGroupTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "operators.coreos.com",
+		Version: "v1",
+		Kind:    "OperatorGroup",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "operators.coreos.com",
+		Version:  "v1",
+		Resource: "operatorgroups",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.targetNamespace)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the OperatorGroup %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []operatorGroupTestSuites{
+		{
+			testID:          "user-cant-delete-managed-operatorgroup",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-operatorgroup",
+			targetNamespace: "openshift-rbac-permissions",
+			targetName:      "rbac-permissions-operator",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runOperatorGroupTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []operatorGroupTestSuites{
+		{
+			testID:          "user-can-delete-customer-operatorgroup",
+			targetNamespace: "my-project",
+			targetName:      "my-operator",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-same-name-in-customer-namespace",
+			targetNamespace: "my-project",
+			targetName:      "must-gather-operator",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "olm-can-modify-managed-operatorgroup",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-operator-lifecycle-manager:olm-operator-serviceaccount",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-operator-lifecycle-manager"},
+			shouldBeAllowed: true,
+		},
+	}
+	runOperatorGroupTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedOperatorGroups
+	defer func() { managedOperatorGroups = oldInventory }()
+	managedOperatorGroups = []string{"openshift-velero/managed-velero-operator"}
+
+	tests := []operatorGroupTestSuites{
+		{
+			testID:          "user-cant-delete-configured-operatorgroup",
+			targetNamespace: "openshift-velero",
+			targetName:      "managed-velero-operator",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-operatorgroup",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runOperatorGroupTests(t, tests)
+}
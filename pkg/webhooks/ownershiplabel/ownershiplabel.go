@@ -0,0 +1,301 @@
+package ownershiplabel
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "ownershiplabel-validation"
+	docString   string = `Managed OpenShift Customers may not remove or alter the following ownership labels of the managed objects labelled %s: %s`
+	// ownedByLabel marks a managed object with the component owning it
+	ownedByLabel string = "managed.openshift.io/owned-by"
+	// objectSelectorParameter is the parameter of the configuration file
+	// setting which objects are managed, as a label selector, eg
+	// "managed.openshift.io/owned-by,hive.openshift.io/managed=true"
+	objectSelectorParameter string = "objectSelector"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.AllScopes
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"*"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"*"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// ownershipLabels are the labels which may not be removed from, nor
+	// altered on, a managed object
+	ownershipLabels = []string{
+		ownedByLabel,
+	}
+	// objectSelector selects the managed objects. Only those are sent to the
+	// webhook, and the webhook checks it again, as it may be configured
+	// narrower than the selector the webhook is registered with.
+	objectSelector = metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: ownedByLabel, Operator: metav1.LabelSelectorOpExists},
+		},
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &objectSelector, &ownershipLabels)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var selector *metav1.LabelSelector
+	for name, value := range settings.Parameters {
+		if name != objectSelectorParameter {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		parsed, err := metav1.ParseToLabelSelector(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid label selector: %v", objectSelectorParameter, err)
+		}
+		selector = parsed
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if selector != nil {
+			objectSelector = *selector
+		}
+		if settings.Protected != nil {
+			ownershipLabels = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"objectSelector":  objectSelector,
+		"ownershipLabels": ownershipLabels,
+		"allowedUsers":    allowedUsers,
+		"allowedGroups":   allowedGroups,
+	}
+}
+
+// OwnershipLabelWebhook keeps customers from adopting managed objects by
+// stripping their ownership labels
+type OwnershipLabelWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *OwnershipLabelWebhook {
+	return &OwnershipLabelWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *OwnershipLabelWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *OwnershipLabelWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newObj, oldObj, err := s.renderOldAndNewObjects(request)
+	if err != nil {
+		log.Error(err, "Couldn't render an object from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	managed, err := isManaged(oldObj)
+	if err != nil {
+		log.Error(err, "Couldn't match the object against the configured selector")
+		return admissionctl.Errored(http.StatusInternalServerError, err)
+	}
+	if managed {
+		if changed := changedOwnershipLabels(newObj, oldObj); len(changed) > 0 {
+			log.Info(fmt.Sprintf("Change of ownership labels %v detected on managed %s: %s/%s", changed, request.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()))
+			ret = admissionctl.Denied(fmt.Sprintf("Removing or altering the ownership labels %v of managed %s %s is not allowed", changed, request.Kind.Kind, oldObj.GetName()))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewObjects decodes both the Object and OldObject of the
+// request. Any resource may be managed, so the objects are decoded
+// generically. Return order is: new, old, error.
+func (s *OwnershipLabelWebhook) renderOldAndNewObjects(request admissionctl.Request) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(request.Object.Raw) == 0 || len(request.OldObject.Raw) == 0 {
+		return nil, nil, fmt.Errorf("%s UPDATE request is missing an object", request.Kind.Kind)
+	}
+
+	newObj := &unstructured.Unstructured{}
+	oldObj := &unstructured.Unstructured{}
+	if err := decoder.DecodeRaw(request.Object, newObj); err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if err := decoder.DecodeRaw(request.OldObject, oldObj); err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	return newObj, oldObj, nil
+}
+
+// isManaged checks if the existing object matches objectSelector
+func isManaged(obj *unstructured.Unstructured) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&objectSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(obj.GetLabels())), nil
+}
+
+// changedOwnershipLabels returns the ownership labels the existing object
+// carries which the update removes or alters. Setting an ownership label the
+// object didn't carry is left alone.
+func changedOwnershipLabels(newObj, oldObj *unstructured.Unstructured) []string {
+	var changed []string
+	newLabels := newObj.GetLabels()
+	for _, label := range ownershipLabels {
+		oldValue, ok := oldObj.GetLabels()[label]
+		if !ok {
+			continue
+		}
+		if newValue, ok := newLabels[label]; !ok || newValue != oldValue {
+			changed = append(changed, label)
+		}
+	}
+	return changed
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *OwnershipLabelWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *OwnershipLabelWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *OwnershipLabelWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *OwnershipLabelWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *OwnershipLabelWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *OwnershipLabelWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface. Only the managed objects are
+// sent to the webhook. The API server matches an UPDATE against both the
+// old and new object, so the removal of a selected label is sent too.
+func (s *OwnershipLabelWebhook) ObjectSelector() *metav1.LabelSelector {
+	selector := objectSelector
+	return &selector
+}
+
+// SideEffects implements Webhook interface
+func (s *OwnershipLabelWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *OwnershipLabelWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *OwnershipLabelWebhook) Doc() string {
+	return fmt.Sprintf(docString, metav1.FormatLabelSelector(&objectSelector), ownershipLabels)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *OwnershipLabelWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,173 @@
+package ownershiplabel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type ownershipLabelTestSuites struct {
+	testID          string
+	oldLabels       string
+	newLabels       string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "ConfigMap",
+	"metadata": {
+		"name": "managed-config",
+		"namespace": "openshift-config",
+		"uid": "1234",
+		"labels": %s
+	}
+}`
+
+const (
+	ownedLabels        string = `{"managed.openshift.io/owned-by": "cluster-config-operator"}`
+	reownedLabels      string = `{"managed.openshift.io/owned-by": "customer"}`
+	extraOwnedLabels   string = `{"managed.openshift.io/owned-by": "cluster-config-operator", "team": "payments"}`
+	strippedLabels     string = `{"team": "payments"}`
+	customerLabels     string = `{"app": "customer"}`
+	customerMoreLabels string = `{"app": "customer", "team": "payments"}`
+)
+
+func runOwnershipLabelTests(t *testing.T, tests []ownershipLabelTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "ConfigMap",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "configmaps",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.newLabels)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.oldLabels)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s update the labels. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []ownershipLabelTestSuites{
+		{
+			testID:          "user-cant-remove-ownership-label",
+			oldLabels:       extraOwnedLabels,
+			newLabels:       strippedLabels,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-alter-ownership-label",
+			oldLabels:       ownedLabels,
+			newLabels:       reownedLabels,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runOwnershipLabelTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []ownershipLabelTestSuites{
+		{
+			testID:          "user-can-add-unrelated-label",
+			oldLabels:       ownedLabels,
+			newLabels:       extraOwnedLabels,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-label-unmanaged-object",
+			oldLabels:       customerLabels,
+			newLabels:       customerMoreLabels,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-remove-ownership-label",
+			oldLabels:       extraOwnedLabels,
+			newLabels:       strippedLabels,
+			username:        "system:serviceaccount:openshift-backplane-srep:1234",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runOwnershipLabelTests(t, tests)
+}
+
+func TestConfiguredSelector(t *testing.T) {
+	oldSelector, oldLabels := objectSelector, ownershipLabels
+	defer func() { objectSelector, ownershipLabels = oldSelector, oldLabels }()
+
+	apply, err := applySettings(config.WebhookSettings{
+		Protected:  []string{"team"},
+		Parameters: map[string]string{objectSelectorParameter: "app=customer"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []ownershipLabelTestSuites{
+		{
+			testID:          "user-cant-remove-configured-label",
+			oldLabels:       customerMoreLabels,
+			newLabels:       customerLabels,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-remove-label-of-unselected-object",
+			oldLabels:       extraOwnedLabels,
+			newLabels:       strippedLabels,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runOwnershipLabelTests(t, tests)
+
+	if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{objectSelectorParameter: "app in (("}}); err == nil {
+		t.Fatalf("Expected an invalid selector to be rejected")
+	}
+}
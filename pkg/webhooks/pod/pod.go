@@ -7,8 +7,8 @@ import (
 	"sync"
 
 	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
-	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,7 +43,7 @@ var (
 
 type PodWebhook struct {
 	mu sync.Mutex
-	s  runtime.Scheme
+	s  *runtime.Scheme
 }
 
 // ObjectSelector implements Webhook interface
@@ -92,7 +92,7 @@ func (s *PodWebhook) Validate(req admissionctl.Request) bool {
 }
 
 func (s *PodWebhook) renderPod(req admissionctl.Request) (*corev1.Pod, error) {
-	decoder, err := admissionctl.NewDecoder(&s.s)
+	decoder, err := admissionctl.NewDecoder(s.s)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +103,7 @@ func (s *PodWebhook) renderPod(req admissionctl.Request) (*corev1.Pod, error) {
 		err = decoder.DecodeRaw(req.Object, pod)
 	}
 	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, req.Kind.Kind)
 		return nil, err
 	}
 	return pod, nil
@@ -171,11 +172,7 @@ func (s *PodWebhook) SyncSetLabelSelector() metav1.LabelSelector {
 
 // NewWebhook creates a new webhook
 func NewWebhook() *PodWebhook {
-	scheme := runtime.NewScheme()
-	admissionv1.AddToScheme(scheme)
-	corev1.AddToScheme(scheme)
-
 	return &PodWebhook{
-		s: *scheme,
+		s: utils.Scheme,
 	}
 }
@@ -0,0 +1,238 @@
+package poddisruptionbudget
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "poddisruptionbudget-validation"
+	docString   string = `Managed OpenShift Customers may not delete the following managed PodDisruptionBudgets: %s`
+	pdbKind     string = "PodDisruptionBudget"
+	pdbGroup    string = "policy"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups: []string{pdbGroup},
+				// Requests for other versions are converted to v1 by the API server
+				// thanks to the Equivalent MatchPolicy.
+				APIVersions: []string{"v1"},
+				Resources:   []string{"poddisruptionbudgets"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:kube-system:generic-garbage-collector",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-ingress-operator:ingress-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedPDBs is the inventory of managed PodDisruptionBudgets, in the form
+	// of namespace/name
+	managedPDBs = []string{
+		"openshift-monitoring/alertmanager-main",
+		"openshift-monitoring/prometheus-k8s",
+		"openshift-monitoring/thanos-querier-pdb",
+		"openshift-ingress/router-default",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedPDBs)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedPDBs = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedPDBs":   managedPDBs,
+		"allowedUsers":  allowedUsers,
+		"allowedGroups": allowedGroups,
+	}
+}
+
+// PodDisruptionBudgetWebhook protects managed PodDisruptionBudgets from deletion
+type PodDisruptionBudgetWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *PodDisruptionBudgetWebhook {
+	return &PodDisruptionBudgetWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *PodDisruptionBudgetWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	pdb, err := s.renderPDB(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a PodDisruptionBudget from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if request.Operation == admissionv1.Delete && isManagedPDB(pdb) && !isAllowedUserGroup(request) {
+		log.Info(fmt.Sprintf("Deleting operation detected on managed PodDisruptionBudget: %s/%s", pdb.Namespace, pdb.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("Deleting managed PodDisruptionBudget %s/%s is not allowed", pdb.Namespace, pdb.Name))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderPDB render the PodDisruptionBudget object from the requests
+func (s *PodDisruptionBudgetWebhook) renderPDB(request admissionctl.Request) (*policyv1.PodDisruptionBudget, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	pdb := &policyv1.PodDisruptionBudget{}
+
+	if len(request.OldObject.Raw) > 0 {
+		err = decoder.DecodeRaw(request.OldObject, pdb)
+	}
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+
+	return pdb, nil
+}
+
+// isManagedPDB checks if the PodDisruptionBudget is in the managed inventory
+func isManagedPDB(pdb *policyv1.PodDisruptionBudget) bool {
+	return utils.SliceContains(pdb.Namespace+"/"+pdb.Name, managedPDBs)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == pdbKind)
+	valid = valid && (request.Kind.Group == pdbGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *PodDisruptionBudgetWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedPDBs)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *PodDisruptionBudgetWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
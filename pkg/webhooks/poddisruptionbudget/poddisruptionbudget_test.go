@@ -0,0 +1,145 @@
+package poddisruptionbudget
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type pdbTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	username        string
+	operation       admissionv1.Operation
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "policy/v1",
+	"kind": "PodDisruptionBudget",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"minAvailable": 1
+	}
+}`
+
+func runPDBTests(t *testing.T, tests []pdbTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "policy",
+		Version: "v1",
+		Kind:    "PodDisruptionBudget",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "policy",
+		Version:  "v1",
+		Resource: "poddisruptionbudgets",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the PodDisruptionBudget %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []pdbTestSuites{
+		{
+			testID:          "user-cant-delete-managed-pdb",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runPDBTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []pdbTestSuites{
+		{
+			testID:          "user-can-delete-customer-pdb",
+			targetNamespace: "my-project",
+			targetName:      "my-app",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "operator-can-delete-managed-pdb",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+	}
+	runPDBTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedPDBs
+	defer func() { managedPDBs = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"my-project/my-app"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []pdbTestSuites{
+		{
+			testID:          "user-cant-delete-configured-pdb",
+			targetNamespace: "my-project",
+			targetName:      "my-app",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-pdb",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runPDBTests(t, tests)
+}
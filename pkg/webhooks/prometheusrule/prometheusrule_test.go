@@ -0,0 +1,173 @@
+package prometheusrule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type prometheusRuleTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "monitoring.coreos.com/v1",
+	"kind": "PrometheusRule",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"groups": [
+			{
+				"name": "%s.rules",
+				"rules": [
+					{
+						"alert": "TargetDown",
+						"expr": "up == 0"
+					}
+				]
+			}
+		]
+	}
+}`
+
+func runPrometheusRuleTests(t *testing.T, tests []prometheusRuleTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PrometheusRule",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "prometheusrules",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.targetName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the PrometheusRule %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []prometheusRuleTestSuites{
+		{
+			testID:          "user-cant-delete-managed-prometheusrule",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "node-exporter-rules",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-prometheusrule",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "kube-state-metrics-rules",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runPrometheusRuleTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []prometheusRuleTestSuites{
+		{
+			testID:          "user-can-delete-customer-prometheusrule",
+			targetNamespace: "my-project",
+			targetName:      "my-app-alerts",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-same-name-in-customer-namespace",
+			targetNamespace: "my-project",
+			targetName:      "node-exporter-rules",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "monitoring-operator-can-modify-managed-prometheusrule",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "node-exporter-rules",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+	}
+	runPrometheusRuleTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedPrometheusRules
+	defer func() { managedPrometheusRules = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"openshift-ingress/router-rules"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []prometheusRuleTestSuites{
+		{
+			testID:          "user-cant-delete-configured-prometheusrule",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-rules",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-prometheusrule",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "node-exporter-rules",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runPrometheusRuleTests(t, tests)
+}
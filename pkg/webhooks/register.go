@@ -1,6 +1,9 @@
 package webhooks
 
 import (
+	"fmt"
+	"sort"
+
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -8,9 +11,63 @@ import (
 
 type RegisteredWebhooks map[string]WebhookFactory
 
-// Webhooks are all registered webhooks mapping name to hook
+// Webhooks are all registered webhooks mapping name to hook. Each webhook
+// registers itself from the init function of its add_ file.
 var Webhooks = RegisteredWebhooks{}
 
+// Names returns the names of the webhooks in sorted order, so that whatever
+// is built from them is stable
+func (r RegisteredWebhooks) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Without returns the webhooks but the named ones, eg to turn some of them
+// off on a given cluster
+func (r RegisteredWebhooks) Without(names []string) RegisteredWebhooks {
+	hooks := make(RegisteredWebhooks, len(r))
+	for name, hook := range r {
+		hooks[name] = hook
+	}
+	for _, name := range names {
+		delete(hooks, name)
+	}
+	return hooks
+}
+
+// WithoutDisabledCapabilities returns the webhooks but the ones requiring any
+// of the disabled cluster capabilities, as there is nothing for them to
+// validate on a cluster without the capability
+func (r RegisteredWebhooks) WithoutDisabledCapabilities(disabled []string) RegisteredWebhooks {
+	hooks := make(RegisteredWebhooks, len(r))
+	for name, hook := range r {
+		if !requiresAny(hook(), disabled) {
+			hooks[name] = hook
+		}
+	}
+	return hooks
+}
+
+// requiresAny checks if the webhook requires any of the capabilities
+func requiresAny(hook Webhook, capabilities []string) bool {
+	dependent, ok := hook.(CapabilityDependent)
+	if !ok {
+		return false
+	}
+	for _, required := range dependent.RequiredCapabilities() {
+		for _, capability := range capabilities {
+			if required == capability {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Webhook interface
 type Webhook interface {
 	// Authorized will determine if the request is allowed
@@ -45,10 +102,53 @@ type Webhook interface {
 	SyncSetLabelSelector() metav1.LabelSelector
 }
 
+// TimeoutDecider may be implemented by a Webhook which needs a decision other
+// than the one of its FailurePolicy when Authorized doesn't decide within
+// utils.InternalDeadline, or panics. Eg a protection webhook with an Ignore
+// FailurePolicy denies such requests rather than allowing them.
+type TimeoutDecider interface {
+	// TimeoutResponse is the response to send for a request Authorized
+	// couldn't decide on
+	TimeoutResponse(request admissionctl.Request) admissionctl.Response
+}
+
+// CapabilityDependent may be implemented by a Webhook which only makes sense
+// on clusters with some optional capabilities enabled, eg a Route webhook
+// needs the Ingress capability. A Webhook which doesn't implement it, like
+// the SCC one, is required whatever the capabilities.
+type CapabilityDependent interface {
+	// RequiredCapabilities are the names of the cluster capabilities, as
+	// in the ClusterVersion, which the webhook requires
+	RequiredCapabilities() []string
+}
+
+// NamespaceSelective may be implemented by a Webhook which only validates
+// objects in some namespaces, so that the API server doesn't call it, nor
+// wait on it, for the requests in the others
+type NamespaceSelective interface {
+	// NamespaceSelector mirrors validatingwebhookconfiguration.webhooks[].namespaceSelector,
+	// matching the labels of the namespace of the object
+	NamespaceSelector() *metav1.LabelSelector
+}
+
+// namespaceSelector returns the NamespaceSelector of a NamespaceSelective
+// hook, nil for any other hook to be called whatever the namespace
+func namespaceSelector(hook Webhook) *metav1.LabelSelector {
+	if selective, ok := hook.(NamespaceSelective); ok {
+		return selective.NamespaceSelector()
+	}
+	return nil
+}
+
 // WebhookFactory return a kind of Webhook
 type WebhookFactory func() Webhook
 
-// Register webhooks
+// Register registers a webhook under its name. It panics when the name is
+// already taken, as one of the two webhooks would otherwise silently go
+// missing.
 func Register(name string, input WebhookFactory) {
+	if _, ok := Webhooks[name]; ok {
+		panic(fmt.Sprintf("Webhook %s is registered twice", name))
+	}
 	Webhooks[name] = input
 }
@@ -0,0 +1,85 @@
+package webhooks_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+)
+
+func TestSCCWebhookIsRegistered(t *testing.T) {
+	factory, ok := webhooks.Webhooks[scc.WebhookName]
+	if !ok {
+		t.Fatalf("Expected %s to be registered", scc.WebhookName)
+	}
+	if name := factory().Name(); name != scc.WebhookName {
+		t.Fatalf("Expected the %s factory to build %s, got %s", scc.WebhookName, scc.WebhookName, name)
+	}
+}
+
+func TestRegisteredNames(t *testing.T) {
+	names := webhooks.Webhooks.Names()
+	if len(names) != len(webhooks.Webhooks) {
+		t.Fatalf("Expected %d names, got %d", len(webhooks.Webhooks), len(names))
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Fatalf("Expected sorted names, got %v", names)
+	}
+
+	without := webhooks.Webhooks.Without([]string{scc.WebhookName, "no-such-validation"})
+	if _, ok := without[scc.WebhookName]; ok {
+		t.Fatalf("Expected %s to be left out", scc.WebhookName)
+	}
+	if len(without) != len(webhooks.Webhooks)-1 {
+		t.Fatalf("Expected %d webhooks, got %d", len(webhooks.Webhooks)-1, len(without))
+	}
+	if _, ok := webhooks.Webhooks[scc.WebhookName]; !ok {
+		t.Fatalf("Expected Without to leave the registry as is")
+	}
+}
+
+func TestDuplicateRegistration(t *testing.T) {
+	const name = "duplicate-validation"
+	factory := func() webhooks.Webhook { return scc.NewWebhook() }
+	webhooks.Register(name, factory)
+	defer delete(webhooks.Webhooks, name)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected registering %s twice to panic", name)
+		}
+	}()
+	webhooks.Register(name, factory)
+}
+
+// ingressWebhook is a webhook gated on the Ingress capability
+type ingressWebhook struct {
+	webhooks.Webhook
+}
+
+func (ingressWebhook) RequiredCapabilities() []string {
+	return []string{"Ingress"}
+}
+
+func TestWithoutDisabledCapabilities(t *testing.T) {
+	const name = "ingress-gated-validation"
+	webhooks.Register(name, func() webhooks.Webhook { return ingressWebhook{scc.NewWebhook()} })
+	defer delete(webhooks.Webhooks, name)
+
+	hooks := webhooks.Webhooks.WithoutDisabledCapabilities([]string{"Build", "Ingress"})
+	if _, ok := hooks[name]; ok {
+		t.Fatalf("Expected %s to be skipped with the Ingress capability off", name)
+	}
+	if _, ok := hooks[scc.WebhookName]; !ok {
+		t.Fatalf("Expected %s, which requires no capability, to be kept", scc.WebhookName)
+	}
+
+	hooks = webhooks.Webhooks.WithoutDisabledCapabilities([]string{"Build"})
+	if _, ok := hooks[name]; !ok {
+		t.Fatalf("Expected %s to be kept with the Ingress capability on", name)
+	}
+	if len(hooks) > len(webhooks.Webhooks) {
+		t.Fatalf("Expected at most %d webhooks, got %d", len(webhooks.Webhooks), len(hooks))
+	}
+}
@@ -6,11 +6,10 @@ import (
 
 	networkv1 "github.com/openshift/api/network/v1"
 	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/namespace"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
-	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -107,7 +106,7 @@ var (
 
 // RegularuserWebhook protects various objects from unauthorized manipulation
 type RegularuserWebhook struct {
-	s runtime.Scheme
+	s *runtime.Scheme
 }
 
 func (s *RegularuserWebhook) Doc() string {
@@ -254,7 +253,7 @@ func isNetNamespaceAuthorized(s *RegularuserWebhook, request admissionctl.Reques
 // isNetNamespaceValid check if the NetNamespace is valid
 func isNetNamespaceValid(s *RegularuserWebhook, request admissionctl.Request) bool {
 	// Decode object into a NetNamespace object
-	decoder, err := admissionctl.NewDecoder(&s.s)
+	decoder, err := admissionctl.NewDecoder(s.s)
 	if err != nil {
 		return false
 	}
@@ -264,6 +263,7 @@ func isNetNamespaceValid(s *RegularuserWebhook, request admissionctl.Request) bo
 	}
 	err = decoder.Decode(request, netNamespace)
 	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
 		return false
 	}
 	// Check if the name is bad or is privileged
@@ -281,12 +281,7 @@ func (s *RegularuserWebhook) SyncSetLabelSelector() metav1.LabelSelector {
 
 // NewWebhook creates a new webhook
 func NewWebhook() *RegularuserWebhook {
-	scheme := runtime.NewScheme()
-	admissionv1.AddToScheme(scheme)
-	corev1.AddToScheme(scheme)
-	networkv1.AddToScheme(scheme)
-
 	return &RegularuserWebhook{
-		s: *scheme,
+		s: utils.Scheme,
 	}
 }
@@ -0,0 +1,229 @@
+package rolebinding
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName            string = "rolebinding-validation"
+	docString              string = `Managed OpenShift Customers may not bind the ClusterRoles %s to non-allowlisted subjects, through a ClusterRoleBinding or a RoleBinding in a shared namespace.`
+	roleBindingKind        string = "RoleBinding"
+	clusterRoleBindingKind string = "ClusterRoleBinding"
+	clusterRoleKind        string = "ClusterRole"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.AllScopes
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{rbacv1.GroupName},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"clusterrolebindings", "rolebindings"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// powerfulClusterRoles are the ClusterRoles which may only be bound to
+	// allowedSubjects
+	powerfulClusterRoles = []string{
+		"cluster-admin",
+		"sudoer",
+	}
+	// allowedSubjects are the subjects powerfulClusterRoles may be bound to
+	allowedSubjects = []utils.AllowlistEntry{
+		{Type: utils.AllowlistGroup, Name: "osd-sre-admins"},
+		{Type: utils.AllowlistGroup, Name: "osd-sre-cluster-admins"},
+		{Type: utils.AllowlistServiceAccount, Namespace: "openshift-backplane-srep", Name: "backplane-srep"},
+	}
+	// clock tells the time the expiry of allowedSubjects is checked against
+	clock utils.Clock = utils.RealClock{}
+)
+
+// RoleBindingWebhook prevents powerful ClusterRoles from being granted to
+// customer identities
+type RoleBindingWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *RoleBindingWebhook {
+	return &RoleBindingWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *RoleBindingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *RoleBindingWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	namespace, roleRef, subjects, err := s.renderBinding(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a binding from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	// A RoleBinding only grants the ClusterRole within its own namespace,
+	// which is fine outside of the shared namespaces
+	if request.Kind.Kind == roleBindingKind && !config.IsPrivilegedNamespace(namespace) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if roleRef.Kind == clusterRoleKind && utils.SliceContains(roleRef.Name, powerfulClusterRoles) {
+		for _, subject := range subjects {
+			if !isAllowedSubject(subject) {
+				log.Info(fmt.Sprintf("Binding of ClusterRole %s to %s %s detected", roleRef.Name, subject.Kind, subject.Name))
+				ret = admissionctl.Denied(fmt.Sprintf("Binding the ClusterRole %s to %s %s is not allowed", roleRef.Name, subject.Kind, subject.Name))
+				ret.UID = request.AdmissionRequest.UID
+				return ret
+			}
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderBinding renders the RoleBinding or ClusterRoleBinding being created or
+// updated, and returns its namespace, RoleRef and Subjects. Only those fields
+// are extracted, see streamBinding.
+func (s *RoleBindingWebhook) renderBinding(request admissionctl.Request) (string, rbacv1.RoleRef, []rbacv1.Subject, error) {
+	namespace, roleRef, subjects, err := streamBinding(request.Object.Raw)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return "", rbacv1.RoleRef{}, nil, err
+	}
+	return namespace, roleRef, subjects, nil
+}
+
+// isAllowedSubject checks if the subject is in allowedSubjects, and its entry
+// hasn't expired
+func isAllowedSubject(subject rbacv1.Subject) bool {
+	now := clock.Now()
+	for _, entry := range allowedSubjects {
+		if !entry.Active(now) {
+			continue
+		}
+		switch {
+		case subject.Kind == rbacv1.UserKind && entry.Type == utils.AllowlistUser && subject.Name == entry.Name:
+			return true
+		case subject.Kind == rbacv1.GroupKind && entry.Type == utils.AllowlistGroup && subject.Name == entry.Name:
+			return true
+		case subject.Kind == rbacv1.ServiceAccountKind && entry.Type == utils.AllowlistServiceAccount &&
+			subject.Namespace == entry.Namespace && subject.Name == entry.Name:
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *RoleBindingWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *RoleBindingWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == roleBindingKind || request.Kind.Kind == clusterRoleBindingKind)
+	valid = valid && (request.Kind.Group == rbacv1.GroupName)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *RoleBindingWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *RoleBindingWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *RoleBindingWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *RoleBindingWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *RoleBindingWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *RoleBindingWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *RoleBindingWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *RoleBindingWebhook) Doc() string {
+	return fmt.Sprintf(docString, powerfulClusterRoles)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *RoleBindingWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,190 @@
+package rolebinding
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type roleBindingTestSuites struct {
+	testID          string
+	kind            string
+	namespace       string
+	clusterRole     string
+	subjectKind     string
+	subjectName     string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "rbac.authorization.k8s.io/v1",
+	"kind": "%s",
+	"metadata": {
+		"name": "test-binding",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"roleRef": {
+		"apiGroup": "rbac.authorization.k8s.io",
+		"kind": "ClusterRole",
+		"name": "%s"
+	},
+	"subjects": [
+		{
+			"kind": "%s",
+			"name": "%s"
+		}
+	]
+}`
+
+func runRoleBindingTests(t *testing.T, tests []roleBindingTestSuites) {
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   "rbac.authorization.k8s.io",
+			Version: "v1",
+			Kind:    test.kind,
+		}
+		resource := "rolebindings"
+		if test.kind == "ClusterRoleBinding" {
+			resource = "clusterrolebindings"
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    "rbac.authorization.k8s.io",
+			Version:  "v1",
+			Resource: resource,
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.kind, test.namespace, test.clusterRole, test.subjectKind, test.subjectName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: %s (groups=%s) %s bind %s to %s %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.clusterRole, test.subjectKind, test.subjectName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []roleBindingTestSuites{
+		{
+			testID:          "user-cant-bind-cluster-admin-to-user",
+			kind:            "ClusterRoleBinding",
+			clusterRole:     "cluster-admin",
+			subjectKind:     "User",
+			subjectName:     "customer-user",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-bind-cluster-admin-in-shared-namespace",
+			kind:            "RoleBinding",
+			namespace:       "openshift-logging",
+			clusterRole:     "cluster-admin",
+			subjectKind:     "User",
+			subjectName:     "customer-user",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runRoleBindingTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []roleBindingTestSuites{
+		{
+			testID:          "user-can-bind-view-to-user",
+			kind:            "ClusterRoleBinding",
+			clusterRole:     "view",
+			subjectKind:     "User",
+			subjectName:     "customer-user",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-bind-cluster-admin-to-sre-group",
+			kind:            "ClusterRoleBinding",
+			clusterRole:     "cluster-admin",
+			subjectKind:     "Group",
+			subjectName:     "osd-sre-admins",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-bind-cluster-admin-in-own-namespace",
+			kind:            "RoleBinding",
+			namespace:       "my-project",
+			clusterRole:     "cluster-admin",
+			subjectKind:     "User",
+			subjectName:     "customer-user",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "sre-can-bind-cluster-admin-to-user",
+			kind:            "ClusterRoleBinding",
+			clusterRole:     "cluster-admin",
+			subjectKind:     "User",
+			subjectName:     "customer-user",
+			username:        "backplane-cluster-admin",
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: true,
+		},
+	}
+	runRoleBindingTests(t, tests)
+}
+
+func TestExpiringAllowedSubject(t *testing.T) {
+	oldSubjects, oldClock := allowedSubjects, clock
+	defer func() { allowedSubjects, clock = oldSubjects, oldClock }()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := utils.NewFakeClock(now)
+	clock = fakeClock
+	allowedSubjects = []utils.AllowlistEntry{
+		{Type: utils.AllowlistUser, Name: "migration-user", Expires: now.Add(time.Hour)},
+	}
+
+	test := roleBindingTestSuites{
+		testID:          "user-can-bind-cluster-admin-to-allowlisted-user",
+		kind:            "ClusterRoleBinding",
+		clusterRole:     "cluster-admin",
+		subjectKind:     "User",
+		subjectName:     "migration-user",
+		username:        "user1",
+		userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+		shouldBeAllowed: true,
+	}
+	runRoleBindingTests(t, []roleBindingTestSuites{test})
+
+	fakeClock.Step(time.Hour)
+	test.testID = "user-cant-bind-cluster-admin-to-expired-user"
+	test.shouldBeAllowed = false
+	runRoleBindingTests(t, []roleBindingTestSuites{test})
+}
@@ -0,0 +1,129 @@
+package rolebinding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// streamBinding extracts the namespace, RoleRef and Subjects of a serialized
+// RoleBinding or ClusterRoleBinding, without materializing the rest of the
+// object. Bindings with thousands of subjects or large metadata, such as
+// managedFields, would otherwise be decoded in full on every request.
+func streamBinding(raw []byte) (string, rbacv1.RoleRef, []rbacv1.Subject, error) {
+	var namespace string
+	var roleRef rbacv1.RoleRef
+	var subjects []rbacv1.Subject
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", rbacv1.RoleRef{}, nil, err
+	}
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return "", rbacv1.RoleRef{}, nil, err
+		}
+		switch key {
+		case "metadata":
+			namespace, err = streamNamespace(dec)
+		case "roleRef":
+			roleRef = rbacv1.RoleRef{}
+			err = dec.Decode(&roleRef)
+		case "subjects":
+			subjects, err = streamSubjects(dec)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return "", rbacv1.RoleRef{}, nil, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return "", rbacv1.RoleRef{}, nil, err
+	}
+	return namespace, roleRef, subjects, nil
+}
+
+// streamNamespace reads the namespace out of the metadata object
+func streamNamespace(dec *json.Decoder) (string, error) {
+	var namespace string
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", err
+	}
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return "", err
+		}
+		if key == "namespace" {
+			err = dec.Decode(&namespace)
+		} else {
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return namespace, expectDelim(dec, '}')
+}
+
+// streamSubjects decodes the subjects array. A null array is returned as
+// nil, as with a full decode.
+func streamSubjects(dec *json.Decoder) ([]rbacv1.Subject, error) {
+	var subjects []rbacv1.Subject
+	if err := dec.Decode(&subjects); err != nil {
+		return nil, err
+	}
+	return subjects, nil
+}
+
+// nextKey reads the next key of the object being decoded
+func nextKey(dec *json.Decoder) (string, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := t.(string)
+	if !ok {
+		return "", fmt.Errorf("expected an object key, got %v", t)
+	}
+	return key, nil
+}
+
+// expectDelim reads the next token, which must be the given delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %v, got %v", delim, t)
+	}
+	return nil
+}
+
+// skipValue reads past the next value, however deeply nested, without
+// keeping it
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := t.(json.Delim); ok {
+			switch d {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
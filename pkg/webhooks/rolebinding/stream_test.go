@@ -0,0 +1,119 @@
+package rolebinding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestStreamBinding(t *testing.T) {
+	tests := []struct {
+		testID string
+		raw    string
+	}{
+		{
+			testID: "rolebinding",
+			raw:    `{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "RoleBinding", "metadata": {"name": "rb", "namespace": "openshift-monitoring", "labels": {"a": "b"}}, "roleRef": {"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "cluster-admin"}, "subjects": [{"kind": "Group", "name": "system:authenticated"}, {"kind": "ServiceAccount", "name": "default", "namespace": "customer"}]}`,
+		},
+		{
+			testID: "clusterrolebinding-fields-out-of-order",
+			raw:    `{"subjects": [{"kind": "User", "name": "user1"}], "roleRef": {"kind": "ClusterRole", "name": "sudoer"}, "metadata": {"managedFields": [{"fieldsV1": {"f:subjects": {}}}], "name": "crb"}, "kind": "ClusterRoleBinding"}`,
+		},
+		{
+			testID: "no-subjects",
+			raw:    `{"kind": "ClusterRoleBinding", "metadata": {"name": "crb"}, "roleRef": {"kind": "ClusterRole", "name": "cluster-admin"}}`,
+		},
+		{
+			testID: "null-subjects",
+			raw:    `{"kind": "ClusterRoleBinding", "metadata": {"name": "crb"}, "roleRef": {"kind": "ClusterRole", "name": "cluster-admin"}, "subjects": null}`,
+		},
+		{
+			testID: "duplicate-subjects-key",
+			raw:    `{"kind": "ClusterRoleBinding", "roleRef": {"kind": "ClusterRole", "name": "cluster-admin"}, "subjects": [{"kind": "Group", "name": "osd-sre-admins"}], "subjects": [{"kind": "Group", "name": "system:authenticated"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		expected := &rbacv1.RoleBinding{}
+		if err := json.Unmarshal([]byte(test.raw), expected); err != nil {
+			t.Fatalf("%s: Expected no error, got %s", test.testID, err.Error())
+		}
+		namespace, roleRef, subjects, err := streamBinding([]byte(test.raw))
+		if err != nil {
+			t.Fatalf("%s: Expected no error, got %s", test.testID, err.Error())
+		}
+		if namespace != expected.Namespace {
+			t.Fatalf("%s: Expected namespace %q, got %q", test.testID, expected.Namespace, namespace)
+		}
+		if !reflect.DeepEqual(roleRef, expected.RoleRef) {
+			t.Fatalf("%s: Expected roleRef %v, got %v", test.testID, expected.RoleRef, roleRef)
+		}
+		if !reflect.DeepEqual(subjects, expected.Subjects) {
+			t.Fatalf("%s: Expected subjects %v, got %v", test.testID, expected.Subjects, subjects)
+		}
+	}
+}
+
+func TestStreamBindingMalformed(t *testing.T) {
+	for _, raw := range []string{
+		``,
+		`[]`,
+		`{"roleRef": {"kind": "ClusterRole", "name": "cluster-admin"}`,
+		`{"subjects": {"kind": "Group"}}`,
+		`{"subjects": [{"kind": 1}]}`,
+		`{"metadata": "not an object"}`,
+	} {
+		if _, _, _, err := streamBinding([]byte(raw)); err == nil {
+			t.Fatalf("Expected an error for %q", raw)
+		}
+	}
+}
+
+// largeBinding is a ClusterRoleBinding with many subjects, and even larger
+// metadata, as with a binding edited by many field managers
+func largeBinding(subjectCount, managedFieldCount int) []byte {
+	subjects := make([]string, 0, subjectCount)
+	for i := 0; i < subjectCount; i++ {
+		subjects = append(subjects, fmt.Sprintf(`{"kind": "User", "apiGroup": "rbac.authorization.k8s.io", "name": "user-%d"}`, i))
+	}
+	managedFields := make([]string, 0, managedFieldCount)
+	for i := 0; i < managedFieldCount; i++ {
+		managedFields = append(managedFields, fmt.Sprintf(`{"manager": "manager-%d", "operation": "Update", "apiVersion": "rbac.authorization.k8s.io/v1", "fieldsType": "FieldsV1", "fieldsV1": {"f:metadata": {"f:labels": {"f:label-%d": {}}}}}`, i, i))
+	}
+	return []byte(fmt.Sprintf(`{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRoleBinding", "metadata": {"name": "large", "managedFields": [%s]}, "roleRef": {"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "view"}, "subjects": [%s]}`,
+		strings.Join(managedFields, ","), strings.Join(subjects, ",")))
+}
+
+func BenchmarkStreamBinding(b *testing.B) {
+	raw := largeBinding(1000, 3000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := streamBinding(raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBinding(b *testing.B) {
+	raw := largeBinding(1000, 3000)
+	hook := NewWebhook()
+	decoder, err := admissionctl.NewDecoder(hook.s)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		crb := &rbacv1.ClusterRoleBinding{}
+		if err := decoder.DecodeRaw(runtime.RawExtension{Raw: raw}, crb); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
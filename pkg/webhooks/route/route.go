@@ -0,0 +1,201 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "route-validation"
+	docString   string = `Managed OpenShift Customers may not create or update Routes whose host matches any of the following reserved platform hostnames: %s`
+	routeKind   string = "Route"
+	routeGroup  string = "route.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE", "UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{routeGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"routes"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-console-operator:console-operator",
+		"system:serviceaccount:openshift-authentication-operator:authentication-operator",
+		"system:serviceaccount:openshift-ingress-operator:ingress-operator",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// reservedHostPatterns are regular expressions matching the hostnames of
+	// platform Routes, which customer Routes must not claim
+	reservedHostPatterns = []string{
+		`^console-openshift-console\.`,
+		`^downloads-openshift-console\.`,
+		`^oauth-openshift\.`,
+		`^api\.`,
+		`^canary-openshift-ingress-canary\.`,
+	}
+)
+
+// RouteWebhook keeps customer Routes from claiming platform hostnames
+type RouteWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *RouteWebhook {
+	return &RouteWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *RouteWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *RouteWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	route, err := s.renderRoute(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Route from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	// Hostnames are case insensitive, and the router matches them as such
+	host := strings.ToLower(route.Spec.Host)
+	if host != "" && utils.RegexSliceContains(host, reservedHostPatterns) {
+		log.Info(fmt.Sprintf("Route %s/%s claiming reserved host %s detected", route.Namespace, route.Name, host))
+		ret = admissionctl.Denied(fmt.Sprintf("The host %s is reserved for the platform", host))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderRoute renders the Route being created or updated
+func (s *RouteWebhook) renderRoute(request admissionctl.Request) (*routev1.Route, error) {
+	newObj, _, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &routev1.Route{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if newObj == nil {
+		return nil, fmt.Errorf("Route %s request is missing an object", request.Operation)
+	}
+	return newObj.(*routev1.Route), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *RouteWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *RouteWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == routeKind)
+	valid = valid && (request.Kind.Group == routeGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *RouteWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *RouteWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *RouteWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *RouteWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *RouteWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *RouteWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *RouteWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *RouteWebhook) Doc() string {
+	return fmt.Sprintf(docString, reservedHostPatterns)
+}
+
+// RequiredCapabilities implements CapabilityDependent interface. Routes are only served with the Ingress capability.
+func (s *RouteWebhook) RequiredCapabilities() []string {
+	return []string{"Ingress"}
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *RouteWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,136 @@
+package route
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type routeTestSuites struct {
+	testID          string
+	host            string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "route.openshift.io/v1",
+	"kind": "Route",
+	"metadata": {
+		"name": "frontend",
+		"namespace": "customer-ns",
+		"uid": "1234"
+	},
+	"spec": {
+		"host": "%s",
+		"to": {
+			"kind": "Service",
+			"name": "frontend"
+		}
+	}
+}`
+
+func runRouteTests(t *testing.T, tests []routeTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "route.openshift.io",
+		Version: "v1",
+		Kind:    "Route",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "route.openshift.io",
+		Version:  "v1",
+		Resource: "routes",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.host)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s a Route for host %q. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.host, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []routeTestSuites{
+		{
+			testID:          "user-cant-claim-console-host",
+			host:            "console-openshift-console.apps.example.com",
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-claim-oauth-host-in-upper-case",
+			host:            "OAUTH-OPENSHIFT.apps.example.com",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runRouteTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []routeTestSuites{
+		{
+			testID:          "user-can-use-own-host",
+			host:            "frontend-customer-ns.apps.example.com",
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-use-host-containing-reserved-name",
+			host:            "my-console-openshift-console.apps.example.com",
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-leave-host-to-be-generated",
+			host:            "",
+			operation:       admissionv1.Create,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "console-operator-can-claim-console-host",
+			host:            "console-openshift-console.apps.example.com",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-console-operator:console-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-console-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runRouteTests(t, tests)
+}
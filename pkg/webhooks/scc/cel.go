@@ -0,0 +1,120 @@
+package scc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// celEvaluator is the PolicyEvaluator of the CEL expressions set in the
+// configuration file. Like in a ValidatingAdmissionPolicy, the expressions see
+// the request, the object and the oldObject, the objects being null when the
+// request carries none, eg oldObject on CREATE.
+type celEvaluator struct {
+	// AllowExpression allows the requests it is true for, without the
+	// built-in checks
+	AllowExpression string `json:"allowExpression,omitempty"`
+	// DenyExpression denies the requests it is true for. It takes precedence
+	// over AllowExpression.
+	DenyExpression string `json:"denyExpression,omitempty"`
+
+	allow cel.Program
+	deny  cel.Program
+}
+
+// newCELEvaluator compiles the expressions, either of which may be empty
+func newCELEvaluator(allowExpression, denyExpression string) (*celEvaluator, error) {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("request", decls.Dyn),
+		decls.NewVar("object", decls.Dyn),
+		decls.NewVar("oldObject", decls.Dyn),
+	))
+	if err != nil {
+		return nil, err
+	}
+	evaluator := &celEvaluator{AllowExpression: allowExpression, DenyExpression: denyExpression}
+	if evaluator.allow, err = compile(env, allowExpression); err != nil {
+		return nil, fmt.Errorf("%s: %v", allowExpressionParameter, err)
+	}
+	if evaluator.deny, err = compile(env, denyExpression); err != nil {
+		return nil, fmt.Errorf("%s: %v", denyExpressionParameter, err)
+	}
+	return evaluator, nil
+}
+
+// compile compiles an expression, returning a nil Program for an empty one
+func compile(env *cel.Env, expression string) (cel.Program, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return env.Program(ast)
+}
+
+// Evaluate implements PolicyEvaluator
+func (c *celEvaluator) Evaluate(request admissionctl.Request) (PolicyDecision, error) {
+	activation, err := celActivation(request)
+	if err != nil {
+		return PolicyDecision{}, err
+	}
+	denied, err := evaluate(c.deny, activation)
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("%s: %v", denyExpressionParameter, err)
+	}
+	if denied {
+		return PolicyDecision{Decided: true, Allowed: false, Reason: fmt.Sprintf("denied by the configured policy %s", c.DenyExpression)}, nil
+	}
+	allowed, err := evaluate(c.allow, activation)
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("%s: %v", allowExpressionParameter, err)
+	}
+	if allowed {
+		return PolicyDecision{Decided: true, Allowed: true, Reason: fmt.Sprintf("allowed by the configured policy %s", c.AllowExpression)}, nil
+	}
+	return PolicyDecision{}, nil
+}
+
+// evaluate evaluates a compiled expression, which must be a boolean. A nil
+// Program is false.
+func evaluate(program cel.Program, activation map[string]interface{}) (bool, error) {
+	if program == nil {
+		return false, nil
+	}
+	out, _, err := program.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("evaluated to %v rather than a boolean", out.Value())
+	}
+	return result, nil
+}
+
+// celActivation binds the variables of the expressions to the request, as
+// the JSON the API server sent
+func celActivation(request admissionctl.Request) (map[string]interface{}, error) {
+	activation := map[string]interface{}{}
+	for name, value := range map[string]interface{}{
+		"request":   request.AdmissionRequest,
+		"object":    request.Object,
+		"oldObject": request.OldObject,
+	} {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, err
+		}
+		activation[name] = decoded
+	}
+	return activation, nil
+}
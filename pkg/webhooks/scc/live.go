@@ -0,0 +1,76 @@
+package scc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// liveReadTimeout bounds the read of a live SCC, well within the timeout of
+// the webhook
+const liveReadTimeout = 500 * time.Millisecond
+
+// LiveReader is the subset of client.Reader used to read the live version of
+// a default SCC
+type LiveReader interface {
+	Get(ctx context.Context, key client.ObjectKey, obj client.Object) error
+}
+
+var (
+	liveMu     sync.RWMutex
+	liveReader LiveReader
+)
+
+// SetLiveReader installs the LiveReader the live version of default SCCs is
+// read with on UPDATE and DELETE. Passing nil disables live reads, leaving
+// the OldObject of the request as the only existing version.
+func SetLiveReader(reader LiveReader) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	liveReader = reader
+}
+
+// liveDrift reads the live version of the default SCC the request targets
+// and returns how it differs from the OldObject of the request, which
+// reveals an out-of-band change, eg written straight to etcd or racing the
+// request. The read is bounded by liveReadTimeout, and any failure is logged
+// and reported as no drift, so a slow or unreachable API server can't hold
+// up or change the decision. Synthetic webhooks never read.
+func (s *SCCWebHook) liveDrift(request admissionctl.Request, scc *securityv1.SecurityContextConstraints) []utils.FieldChange {
+	liveMu.RLock()
+	reader := liveReader
+	liveMu.RUnlock()
+
+	if reader == nil || s.synthetic || !isDefaultSCC(scc) {
+		return nil
+	}
+	if request.Operation != admissionv1.Update && request.Operation != admissionv1.Delete {
+		return nil
+	}
+	if len(request.OldObject.Raw) == 0 {
+		return nil
+	}
+	_, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &securityv1.SecurityContextConstraints{} })
+	if err != nil || oldObj == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), liveReadTimeout)
+	defer cancel()
+	live := &securityv1.SecurityContextConstraints{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: scc.Name}, live); err != nil {
+		log.Error(err, fmt.Sprintf("Couldn't read the live version of default SCC %v, evaluating against the request alone", scc.Name))
+		return nil
+	}
+	// The type is not part of the drift, and the reader may leave it unset
+	live.TypeMeta = oldObj.(*securityv1.SecurityContextConstraints).TypeMeta
+	return utils.DiffObjects(oldObj, live)
+}
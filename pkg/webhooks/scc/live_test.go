@@ -0,0 +1,109 @@
+package scc
+
+import (
+	"testing"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestLiveDrift(t *testing.T) {
+	defer SetLiveReader(nil)
+
+	old := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted", "resourceVersion": "1"}, "allowPrivilegedContainer": false, "users": ["a"]}`
+	new := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted", "resourceVersion": "1"}, "allowPrivilegedContainer": true, "users": ["a"]}`
+
+	tests := []struct {
+		testID          string
+		live            []client.Object
+		username        string
+		synthetic       bool
+		expectedDrift   string
+		shouldBeAllowed bool
+	}{
+		{
+			testID: "live-scc-differs-from-old-object",
+			live: []client.Object{&securityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+				Users:      []string{"a", "b"},
+			}},
+			username:        "user1",
+			expectedDrift:   "users",
+			shouldBeAllowed: false,
+		},
+		{
+			testID: "live-scc-matches-old-object",
+			live: []client.Object{&securityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+				Users:      []string{"a"},
+			}},
+			username:        "user1",
+			expectedDrift:   "",
+			shouldBeAllowed: false,
+		},
+		{
+			testID: "drift-doesnt-change-allowed-decision",
+			live: []client.Object{&securityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+				Users:      []string{"a", "b"},
+			}},
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			expectedDrift:   "users",
+			shouldBeAllowed: true,
+		},
+		{
+			testID: "synthetic-webhook-doesnt-read",
+			live: []client.Object{&securityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+				Users:      []string{"a", "b"},
+			}},
+			username:        "user1",
+			synthetic:       true,
+			expectedDrift:   "",
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "unreadable-live-scc-is-ignored",
+			live:            nil,
+			username:        "user1",
+			expectedDrift:   "",
+			shouldBeAllowed: false,
+		},
+	}
+
+	for _, test := range tests {
+		SetLiveReader(fake.NewClientBuilder().WithScheme(utils.Scheme).WithObjects(test.live...).Build())
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       "live-drift",
+				Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+				Operation: admissionv1.Update,
+				UserInfo: authenticationv1.UserInfo{
+					Username: test.username,
+					Groups:   []string{"system:authenticated"},
+				},
+				Object:    runtime.RawExtension{Raw: []byte(new)},
+				OldObject: runtime.RawExtension{Raw: []byte(old)},
+			},
+		}
+
+		hook := NewWebhook()
+		if test.synthetic {
+			hook = NewSyntheticWebhook()
+		}
+		response := hook.Authorized(request)
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("%s: expected allowed %t, got %t", test.testID, test.shouldBeAllowed, response.Allowed)
+		}
+		if drift := response.AuditAnnotations[auditDriftKey]; drift != test.expectedDrift {
+			t.Fatalf("%s: expected the drift to be audited as %q, got %q", test.testID, test.expectedDrift, drift)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package scc
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// DefaultLocale is the locale of the built-in messages, which the other
+// locales fall back to
+const DefaultLocale string = "en"
+
+// MessageTemplates are the text/template sources of the customer-facing
+// messages returned by the webhook, and of its Doc. Each is rendered with a
+// messageData. A template left empty falls back to DefaultMessageTemplates.
+type MessageTemplates struct {
+	Allowed      string
+	DeleteDenied string
+	UpdateDenied string
+	Doc          string
+}
+
+// MessageCatalog maps a locale, eg "fr", to the MessageTemplates in its
+// language
+type MessageCatalog map[string]MessageTemplates
+
+// messageData is what the message templates are rendered with, eg
+// {{.SCC}}, {{.Operation}}, {{.User}}, {{.DefaultSCCs}} and {{.Changes}}
+type messageData struct {
+	SCC         string
+	Operation   admissionv1.Operation
+	User        string
+	DefaultSCCs []string
+	// Changes are the paths of the fields an UPDATE changes, empty otherwise
+	Changes []string
+}
+
+// messages holds the parsed MessageTemplates
+type messages struct {
+	allowed      *template.Template
+	deleteDenied *template.Template
+	updateDenied *template.Template
+	doc          *template.Template
+}
+
+var (
+	// DefaultMessageTemplates match the historical, hardcoded messages
+	DefaultMessageTemplates = MessageTemplates{
+		Allowed:      "Request is allowed",
+		DeleteDenied: "Deleting default SCCs {{.DefaultSCCs}} is not allowed",
+		UpdateDenied: "Modifying default SCCs {{.DefaultSCCs}} is not allowed",
+		Doc:          "Managed OpenShift Customers may not modify the following default SCCs: {{.DefaultSCCs}}",
+	}
+	// DefaultMessageCatalog holds the built-in messages, in every locale the
+	// webhook can be configured with
+	DefaultMessageCatalog = MessageCatalog{
+		DefaultLocale: DefaultMessageTemplates,
+		"es": {
+			Allowed:      "La solicitud está permitida",
+			DeleteDenied: "No se permite eliminar las SCC predeterminadas {{.DefaultSCCs}}",
+			UpdateDenied: "No se permite modificar las SCC predeterminadas {{.DefaultSCCs}}",
+			Doc:          "Los clientes de Managed OpenShift no pueden modificar las siguientes SCC predeterminadas: {{.DefaultSCCs}}",
+		},
+		"fr": {
+			Allowed:      "La requête est autorisée",
+			DeleteDenied: "La suppression des SCC par défaut {{.DefaultSCCs}} n'est pas autorisée",
+			UpdateDenied: "La modification des SCC par défaut {{.DefaultSCCs}} n'est pas autorisée",
+			Doc:          "Les clients de Managed OpenShift ne peuvent pas modifier les SCC par défaut suivantes : {{.DefaultSCCs}}",
+		},
+	}
+	defaultMessages = mustParseCatalog(DefaultMessageCatalog)
+)
+
+// parseCatalog parses and validates the templates of every locale. The
+// catalog needs the DefaultLocale, which the other locales fall back to.
+func parseCatalog(catalog MessageCatalog) (map[string]*messages, error) {
+	if _, ok := catalog[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("the message catalog lacks the %s locale", DefaultLocale)
+	}
+	parsed := map[string]*messages{}
+	for locale, templates := range catalog {
+		m, err := parseMessages(templates)
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %s", locale, err.Error())
+		}
+		parsed[locale] = m
+	}
+	return parsed, nil
+}
+
+func mustParseCatalog(catalog MessageCatalog) map[string]*messages {
+	m, err := parseCatalog(catalog)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// parseMessages parses and validates the templates. Every template is
+// rendered once with sample data, so that a reference to an unknown field is
+// reported here rather than when a request is being handled.
+func parseMessages(t MessageTemplates) (*messages, error) {
+	m := &messages{}
+	for _, entry := range []struct {
+		name     string
+		source   string
+		fallback string
+		dest     **template.Template
+	}{
+		{"Allowed", t.Allowed, DefaultMessageTemplates.Allowed, &m.allowed},
+		{"DeleteDenied", t.DeleteDenied, DefaultMessageTemplates.DeleteDenied, &m.deleteDenied},
+		{"UpdateDenied", t.UpdateDenied, DefaultMessageTemplates.UpdateDenied, &m.updateDenied},
+		{"Doc", t.Doc, DefaultMessageTemplates.Doc, &m.doc},
+	} {
+		if entry.source == "" {
+			entry.source = entry.fallback
+		}
+		tmpl, err := template.New(entry.name).Option("missingkey=error").Parse(entry.source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s message template: %s", entry.name, err.Error())
+		}
+		sample := messageData{SCC: "restricted", Operation: admissionv1.Update, User: "user", DefaultSCCs: defaultSCCs, Changes: []string{"allowPrivilegedContainer"}}
+		if err := tmpl.Execute(&bytes.Buffer{}, sample); err != nil {
+			return nil, fmt.Errorf("invalid %s message template: %s", entry.name, err.Error())
+		}
+		*entry.dest = tmpl
+	}
+	return m, nil
+}
+
+// render renders the template, falling back to its source should it fail
+func render(tmpl *template.Template, data messageData) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Error(err, "Couldn't render message template", "template", tmpl.Name())
+		return tmpl.Root.String()
+	}
+	return buf.String()
+}
@@ -0,0 +1,170 @@
+package scc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDefaultMessages(t *testing.T) {
+	data := messageData{SCC: "hostaccess", Operation: admissionv1.Delete, User: "user1", DefaultSCCs: []string{"anyuid", "hostaccess"}}
+	tests := []struct {
+		got      string
+		expected string
+	}{
+		{render(defaultMessages[DefaultLocale].allowed, data), "Request is allowed"},
+		{render(defaultMessages[DefaultLocale].deleteDenied, data), "Deleting default SCCs [anyuid hostaccess] is not allowed"},
+		{render(defaultMessages[DefaultLocale].updateDenied, data), "Modifying default SCCs [anyuid hostaccess] is not allowed"},
+	}
+	for _, test := range tests {
+		if test.got != test.expected {
+			t.Fatalf("Expected message %q, got %q", test.expected, test.got)
+		}
+	}
+}
+
+func TestCustomMessageTemplates(t *testing.T) {
+	hook, err := NewWebhookWithMessageTemplates(MessageTemplates{
+		Allowed:      "ok",
+		DeleteDenied: "{{.User}} may not {{.Operation}} the {{.SCC}} SCC",
+		UpdateDenied: "{{.User}} may not {{.Operation}} the {{.SCC}} SCC",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "custom-message",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Delete,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user1",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("hostaccess"))},
+		},
+	}
+	response := hook.Authorized(request)
+	if response.Allowed {
+		t.Fatalf("Expected deletion of a default SCC to be denied")
+	}
+	expected := "user1 may not DELETE the hostaccess SCC"
+	if response.Result.Reason != metav1.StatusReason(expected) {
+		t.Fatalf("Expected message %q, got %q", expected, response.Result.Reason)
+	}
+}
+
+func TestInvalidMessageTemplates(t *testing.T) {
+	tests := []struct {
+		testID    string
+		templates MessageTemplates
+	}{
+		{
+			testID: "unparsable-template",
+			templates: MessageTemplates{
+				Allowed:      "ok",
+				DeleteDenied: "{{.SCC",
+				UpdateDenied: "denied",
+			},
+		},
+		{
+			testID: "unknown-field",
+			templates: MessageTemplates{
+				Allowed:      "ok",
+				DeleteDenied: "denied",
+				UpdateDenied: "{{.Namespace}} is protected",
+			},
+		},
+	}
+	for _, test := range tests {
+		if _, err := NewWebhookWithMessageTemplates(test.templates); err == nil {
+			t.Fatalf("Expected an error for %s", test.testID)
+		}
+	}
+}
+
+func TestLocalizedMessages(t *testing.T) {
+	oldLocale := locale
+	defer func() { locale = oldLocale }()
+
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "localized-message",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Delete,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user1",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("hostaccess"))},
+		},
+	}
+	hook, err := NewWebhookWithMessageCatalog(MessageCatalog{
+		DefaultLocale: {DeleteDenied: "Deleting {{.SCC}} is not allowed"},
+		"fr":          {DeleteDenied: "La suppression de {{.SCC}} n'est pas autorisée"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{DefaultLocale, "Deleting hostaccess is not allowed"},
+		{"fr", "La suppression de hostaccess n'est pas autorisée"},
+		// A locale missing from the catalog falls back to the default one
+		{"es", "Deleting hostaccess is not allowed"},
+	}
+	for _, test := range tests {
+		locale = test.locale
+		response := hook.Authorized(request)
+		if response.Allowed {
+			t.Fatalf("Expected deletion of a default SCC to be denied in locale %s", test.locale)
+		}
+		if response.Result.Reason != metav1.StatusReason(test.expected) {
+			t.Fatalf("Expected message %q in locale %s, got %q", test.expected, test.locale, response.Result.Reason)
+		}
+	}
+
+	// Templates missing from a locale fall back to the default ones
+	locale = "fr"
+	if doc := hook.Doc(); !strings.HasPrefix(doc, "Managed OpenShift Customers may not modify the following default SCCs: [") {
+		t.Fatalf("Expected the Doc to fall back to the default template, got %q", doc)
+	}
+	if doc := NewWebhook().Doc(); !strings.HasPrefix(doc, "Les clients de Managed OpenShift ne peuvent pas modifier") {
+		t.Fatalf("Expected the built-in Doc in locale fr, got %q", doc)
+	}
+}
+
+func TestLocaleSettings(t *testing.T) {
+	oldLocale := locale
+	defer func() { locale = oldLocale }()
+
+	apply, err := applySettings(config.WebhookSettings{Parameters: map[string]string{"locale": "es"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	if locale != "es" {
+		t.Fatalf("Expected locale es, got %s", locale)
+	}
+	for _, parameters := range []map[string]string{
+		{"locale": "tlh"},
+		{"language": "fr"},
+	} {
+		if _, err := applySettings(config.WebhookSettings{Parameters: parameters}); err == nil {
+			t.Fatalf("Expected an error for parameters %v", parameters)
+		}
+	}
+	if _, err := NewWebhookWithMessageCatalog(MessageCatalog{"fr": {}}); err == nil {
+		t.Fatalf("Expected an error for a catalog without the %s locale", DefaultLocale)
+	}
+}
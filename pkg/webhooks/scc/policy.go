@@ -0,0 +1,55 @@
+package scc
+
+import (
+	"sync"
+
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PolicyDecision is the outcome of a PolicyEvaluator. When Decided is false
+// the built-in checks make the decision instead.
+type PolicyDecision struct {
+	Decided bool
+	Allowed bool
+	Reason  string
+}
+
+// PolicyEvaluator evaluates an externally configured policy, such as an
+// expression, against the request, before the built-in checks. The request
+// carries both the old and the new object.
+type PolicyEvaluator interface {
+	Evaluate(request admissionctl.Request) (PolicyDecision, error)
+}
+
+var (
+	policyMu        sync.RWMutex
+	policyEvaluator PolicyEvaluator
+)
+
+// SetPolicyEvaluator installs the PolicyEvaluator consulted on every request.
+// Passing nil restores the built-in checks alone. The allowExpression and
+// denyExpression parameters of the configuration file install a celEvaluator.
+func SetPolicyEvaluator(evaluator PolicyEvaluator) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policyEvaluator = evaluator
+}
+
+// evaluatePolicy runs the installed PolicyEvaluator, if any. An evaluation
+// error is logged and left to the built-in checks, so a broken policy can't
+// weaken the protection of default SCCs.
+func evaluatePolicy(request admissionctl.Request) PolicyDecision {
+	policyMu.RLock()
+	evaluator := policyEvaluator
+	policyMu.RUnlock()
+
+	if evaluator == nil {
+		return PolicyDecision{}
+	}
+	decision, err := evaluator.Evaluate(request)
+	if err != nil {
+		log.Error(err, "Couldn't evaluate the configured policy, falling back to the built-in checks")
+		return PolicyDecision{}
+	}
+	return decision
+}
@@ -0,0 +1,163 @@
+package scc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// fakeEvaluator decides based on the username of the request
+type fakeEvaluator struct {
+	err error
+}
+
+func (f *fakeEvaluator) Evaluate(request admissionctl.Request) (PolicyDecision, error) {
+	if f.err != nil {
+		return PolicyDecision{}, f.err
+	}
+	switch {
+	case strings.HasPrefix(request.UserInfo.Username, "trusted-"):
+		return PolicyDecision{Decided: true, Allowed: true, Reason: "trusted by policy"}, nil
+	case request.Operation == admissionv1.Update && request.UserInfo.Username == "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator":
+		return PolicyDecision{Decided: true, Allowed: false, Reason: "denied by policy"}, nil
+	}
+	return PolicyDecision{}, nil
+}
+
+func TestPolicyEvaluator(t *testing.T) {
+	defer SetPolicyEvaluator(nil)
+
+	tests := []struct {
+		testID          string
+		evaluator       PolicyEvaluator
+		suite           sccTestSuites
+		shouldBeAllowed bool
+	}{
+		{
+			testID:    "policy-allows-otherwise-denied",
+			evaluator: &fakeEvaluator{},
+			suite: sccTestSuites{
+				targetSCC:  "hostaccess",
+				testID:     "policy-allows-otherwise-denied",
+				username:   "trusted-user",
+				operation:  admissionv1.Update,
+				userGroups: []string{"system:authenticated"},
+			},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:    "policy-denies-otherwise-allowed",
+			evaluator: &fakeEvaluator{},
+			suite: sccTestSuites{
+				targetSCC:  "hostaccess",
+				testID:     "policy-denies-otherwise-allowed",
+				username:   "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+				operation:  admissionv1.Update,
+				userGroups: []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:    "undecided-policy-falls-back",
+			evaluator: &fakeEvaluator{},
+			suite: sccTestSuites{
+				targetSCC:  "hostaccess",
+				testID:     "undecided-policy-falls-back",
+				username:   "user1",
+				operation:  admissionv1.Delete,
+				userGroups: []string{"system:authenticated"},
+			},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:    "failing-policy-falls-back",
+			evaluator: &fakeEvaluator{err: fmt.Errorf("bad expression")},
+			suite: sccTestSuites{
+				targetSCC:  "hostaccess",
+				testID:     "failing-policy-falls-back",
+				username:   "trusted-user",
+				operation:  admissionv1.Update,
+				userGroups: []string{"system:authenticated"},
+			},
+			shouldBeAllowed: false,
+		},
+	}
+	for _, test := range tests {
+		SetPolicyEvaluator(test.evaluator)
+		test.suite.shouldBeAllowed = test.shouldBeAllowed
+		runSCCTests(t, []sccTestSuites{test.suite})
+	}
+}
+
+func TestCELEvaluator(t *testing.T) {
+	defer SetPolicyEvaluator(nil)
+
+	apply, err := applySettings(config.WebhookSettings{
+		Parameters: map[string]string{
+			allowExpressionParameter: "request.userInfo.username == 'trusted-user' && object.metadata.name == 'hostaccess'",
+			denyExpressionParameter:  "request.operation == 'UPDATE' && oldObject.metadata.name == 'hostaccess' && request.userInfo.username.startsWith('system:serviceaccount:openshift-monitoring:')",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "expression-allows-otherwise-denied",
+			username:        "trusted-user",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "expression-denies-otherwise-allowed",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "hostnetwork",
+			testID:          "unmatched-expressions-fall-back",
+			username:        "trusted-user",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: false,
+		},
+	})
+}
+
+func TestCELEvaluatorErrors(t *testing.T) {
+	defer SetPolicyEvaluator(nil)
+
+	if _, err := applySettings(config.WebhookSettings{
+		Parameters: map[string]string{denyExpressionParameter: "request.operation =="},
+	}); err == nil {
+		t.Fatalf("Expected an expression which doesn't compile to be rejected")
+	}
+
+	apply, err := applySettings(config.WebhookSettings{
+		Parameters: map[string]string{allowExpressionParameter: "request.userInfo.username"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "non-boolean-expression-falls-back",
+			username:        "trusted-user",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: false,
+		},
+	})
+}
@@ -1,14 +1,20 @@
 package scc
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -17,29 +23,104 @@ import (
 
 const (
 	WebhookName string = "scc-validation"
-	docString   string = `Managed OpenShift Customers may not modify the following default SCCs: %s`
+	sccKind     string = "SecurityContextConstraints"
+	sccGroup    string = "security.openshift.io"
+
+	auditDecisionKey   string = "decision"
+	auditReasonKey     string = "reason"
+	auditDecisionAllow string = "allow"
+	auditDecisionDeny  string = "deny"
+	auditModeKey       string = "mode"
+	auditChangesKey    string = "changes"
+	auditDriftKey      string = "drift"
+
+	// recentDenialsSize is how many denials RecentDenials keeps
+	recentDenialsSize int = 50
+
+	// localeParameter is the parameter of the configuration file setting the
+	// locale of the messages
+	localeParameter string = "locale"
+	// apiVersionsParameter is the parameter of the configuration file pinning
+	// the comma-separated API versions of SCCs the webhook is called for
+	apiVersionsParameter string = "apiVersions"
+	// verifyObjectKindParameter is the parameter of the configuration file
+	// turning the cross-check of request.Kind against the objects off or on
+	verifyObjectKindParameter string = "verifyObjectKind"
+	// forbiddenRequesterGroupsParameter is the parameter of the configuration
+	// file setting the comma-separated forbiddenRequesterGroups
+	forbiddenRequesterGroupsParameter string = "forbiddenRequesterGroups"
+	// allowExpressionParameter is the parameter of the configuration file
+	// setting the CEL expression of the requests to allow, see celEvaluator
+	allowExpressionParameter string = "allowExpression"
+	// denyExpressionParameter is the parameter of the configuration file
+	// setting the CEL expression of the requests to deny, see celEvaluator
+	denyExpressionParameter string = "denyExpression"
 )
 
 var (
 	timeout int32 = 2
 	log           = logf.Log.WithName(WebhookName)
 	scope         = admissionregv1.ClusterScope
-	rules         = []admissionregv1.RuleWithOperations{
-		{
-			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
-			Rule: admissionregv1.Rule{
-				APIGroups:   []string{"security.openshift.io"},
-				APIVersions: []string{"*"},
-				Resources:   []string{"securitycontextconstraints"},
-				Scope:       &scope,
-			},
+	// apiVersions are the API versions of SCCs the webhook is called for.
+	// Pinning them keeps the webhook from being called for a version it
+	// can't decode.
+	apiVersions = []string{"*"}
+	// verifyObjectKind denies the requests whose objects aren't of the kind
+	// request.Kind says, see utils.VerifyObjectKind
+	verifyObjectKind = true
+	// allowedUsers maps each operation on a default SCC to the users allowed
+	// to perform it
+	allowedUsers = map[admissionv1.Operation][]string{
+		admissionv1.Update: {
+			"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		},
+		admissionv1.Delete: {
+			"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
 		},
 	}
-	allowedUsers = []string{
-		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+	// allowedGroups maps each operation on a default SCC to the groups allowed
+	// to perform it. An entry ending with "*" matches a family of groups, see
+	// utils.GroupMatches
+	allowedGroups = map[admissionv1.Operation][]string{
+		admissionv1.Update: {},
+		admissionv1.Delete: {},
 	}
-	allowedGroups = []string{}
-	defaultSCCs   = []string{
+	// allowlist maps each operation on a default SCC to the explicitly typed
+	// identities allowed to perform it, on top of allowedUsers and
+	// allowedGroups
+	allowlist = map[admissionv1.Operation][]utils.AllowlistEntry{}
+	// allowedExtra maps a request.UserInfo.Extra key to the values trusted for
+	// it, for identities whose username is not stable, eg
+	// "authentication.kubernetes.io/pod-name". Matching any entry allows the
+	// request, regardless of the operation.
+	allowedExtra = map[string][]string{}
+	// orphanOnlyDeleteUsers are allowed identities which may only delete a
+	// default SCC with the Orphan propagation policy, so that an accidental
+	// cascading delete doesn't take dependent objects with it
+	orphanOnlyDeleteUsers = []string{}
+	// maintenanceGroups are the managed automation groups allowed to modify
+	// and delete default SCCs during a maintenance window, see
+	// config.InMaintenance
+	maintenanceGroups = []string{
+		"system:serviceaccounts:openshift-backplane-managed-scripts",
+	}
+	// forbiddenRequesterGroups are the groups whose members may not modify or
+	// delete default SCCs, even through the configured policy, an allow-once
+	// token or a maintenance window, unless they are allowed users or groups.
+	// Eg "system:authenticated" restricts default SCCs to allowed identities.
+	// An entry ending with "*" matches a family of groups.
+	forbiddenRequesterGroups = []string{}
+	// mode is how the webhook acts on the requests it would deny
+	mode = config.ModeEnforce
+	// locale is the locale of the messages and Doc, see MessageCatalog
+	locale = DefaultLocale
+	// clock tells the time maintenance windows, allow-once tokens and the
+	// expiry of allowlist entries are checked against
+	clock utils.Clock = utils.RealClock{}
+	// allowOnce verifies the allow-once tokens presented for default SCCs,
+	// against whichever clock is current
+	allowOnce   = utils.NewAllowOnceVerifier(config.AllowOnceSecret, utils.ClockFunc(func() time.Time { return clock.Now() }))
+	defaultSCCs = []string{
 		"anyuid",
 		"hostaccess",
 		"hostmount-anyuid",
@@ -52,28 +133,284 @@ var (
 	}
 )
 
+// recentDenials keeps the last denials, for triage without the logs
+var recentDenials = utils.NewDenialLog(recentDenialsSize)
+
+// RecentDenials returns the last denials of the webhook, oldest first
+func RecentDenials() []utils.Denial {
+	return recentDenials.Recent()
+}
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &mode, &defaultSCCs, &locale, &apiVersions, &verifyObjectKind,
+		&forbiddenRequesterGroups, &allowedUsers, &allowedGroups)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it. The allowed users and groups apply to
+// every operation. The maps are replaced rather than written into, as
+// requests may be reading them.
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	var versions []string
+	var verify *bool
+	var forbidden []string
+	for name, value := range settings.Parameters {
+		switch name {
+		case localeParameter:
+			if _, ok := DefaultMessageCatalog[value]; !ok {
+				return nil, fmt.Errorf("%s %q has no built-in messages", localeParameter, value)
+			}
+		case apiVersionsParameter:
+			parsed, err := parseAPIVersions(value)
+			if err != nil {
+				return nil, err
+			}
+			versions = parsed
+		case verifyObjectKindParameter:
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s %q is not a boolean", verifyObjectKindParameter, value)
+			}
+			verify = &parsed
+		case forbiddenRequesterGroupsParameter:
+			for _, group := range strings.Split(value, ",") {
+				group = strings.TrimSpace(group)
+				if group == "" {
+					return nil, fmt.Errorf("%s %q has an empty group", forbiddenRequesterGroupsParameter, value)
+				}
+				forbidden = append(forbidden, group)
+			}
+		case allowExpressionParameter, denyExpressionParameter:
+			// Compiled together below
+		default:
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+	var evaluator PolicyEvaluator
+	allowExpression, allowSet := settings.Parameters[allowExpressionParameter]
+	denyExpression, denySet := settings.Parameters[denyExpressionParameter]
+	if allowSet || denySet {
+		compiled, err := newCELEvaluator(allowExpression, denyExpression)
+		if err != nil {
+			return nil, err
+		}
+		evaluator = compiled
+	}
+	return func() {
+		if settings.Mode != "" {
+			mode = settings.Mode
+		}
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			defaultSCCs = settings.Protected
+		}
+		if value, ok := settings.Parameters[localeParameter]; ok {
+			locale = value
+		}
+		if versions != nil {
+			apiVersions = versions
+		}
+		if verify != nil {
+			verifyObjectKind = *verify
+		}
+		if forbidden != nil {
+			forbiddenRequesterGroups = forbidden
+		}
+		SetPolicyEvaluator(evaluator)
+		if settings.AllowedUsers != nil {
+			allowedUsers = map[admissionv1.Operation][]string{
+				admissionv1.Update: settings.AllowedUsers,
+				admissionv1.Delete: settings.AllowedUsers,
+			}
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = map[admissionv1.Operation][]string{
+				admissionv1.Update: settings.AllowedGroups,
+				admissionv1.Delete: settings.AllowedGroups,
+			}
+		}
+	}, nil
+}
+
+// parseAPIVersions parses the value of apiVersionsParameter. "*" matches
+// every version, so it can't be combined with specific ones.
+func parseAPIVersions(value string) ([]string, error) {
+	versions := []string{}
+	for _, version := range strings.Split(value, ",") {
+		version = strings.TrimSpace(version)
+		if version == "" {
+			return nil, fmt.Errorf("%s %q has an empty version", apiVersionsParameter, value)
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) > 1 && utils.SliceContains("*", versions) {
+		return nil, fmt.Errorf("%s %q combines * with specific versions", apiVersionsParameter, value)
+	}
+	return versions, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	policyMu.RLock()
+	var evaluator interface{}
+	switch installed := policyEvaluator.(type) {
+	case nil:
+	case *celEvaluator:
+		evaluator = installed
+	default:
+		evaluator = fmt.Sprintf("%T", installed)
+	}
+	policyMu.RUnlock()
+
+	return map[string]interface{}{
+		"defaultSCCs":              sortedCopy(defaultSCCs),
+		"allowedUsers":             allowedUsers,
+		"allowedGroups":            allowedGroups,
+		"allowlist":                allowlist,
+		"allowedExtra":             allowedExtra,
+		"platformIdentities":       utils.PlatformAllowedIdentities(),
+		"orphanOnlyDeleteUsers":    orphanOnlyDeleteUsers,
+		"maintenanceGroups":        maintenanceGroups,
+		"policyEvaluator":          evaluator,
+		"mode":                     mode,
+		"apiVersions":              apiVersions,
+		"verifyObjectKind":         verifyObjectKind,
+		"forbiddenRequesterGroups": forbiddenRequesterGroups,
+	}
+}
+
 type SCCWebHook struct {
-	s runtime.Scheme
+	s *runtime.Scheme
+	// catalog holds the parsed messages, by locale
+	catalog map[string]*messages
+	// protectedSCCs is the sorted defaultSCCs, so that messages and Doc are
+	// stable regardless of how the list was assembled
+	protectedSCCs []string
+	// synthetic is set when the webhook only evaluates synthetic requests,
+	// whose decisions are kept out of recentDenials and which aren't checked
+	// against the live SCCs
+	synthetic bool
 }
 
 // NewWebhook creates the new webhook
 func NewWebhook() *SCCWebHook {
-	scheme := runtime.NewScheme()
-	admissionv1.AddToScheme(scheme)
-	corev1.AddToScheme(scheme)
-
 	return &SCCWebHook{
-		s: *scheme,
+		s:             utils.Scheme,
+		catalog:       defaultMessages,
+		protectedSCCs: sortedCopy(defaultSCCs),
 	}
 }
 
+// NewSyntheticWebhook creates a webhook evaluating synthetic requests, eg
+// the self-test, without side effects on the recent denials nor live reads
+func NewSyntheticWebhook() *SCCWebHook {
+	hook := NewWebhook()
+	hook.synthetic = true
+	return hook
+}
+
+// DeniedInDryRun checks if the response allows a request only because the
+// webhook is in DryRun mode
+func DeniedInDryRun(response admissionctl.Response) bool {
+	return response.Allowed &&
+		response.AuditAnnotations[auditModeKey] == string(config.ModeDryRun) &&
+		response.AuditAnnotations[auditDecisionKey] == auditDecisionDeny
+}
+
+// NewWebhookWithMessageTemplates creates the new webhook with custom
+// customer-facing messages. The templates are validated here, once.
+func NewWebhookWithMessageTemplates(templates MessageTemplates) (*SCCWebHook, error) {
+	return NewWebhookWithMessageCatalog(MessageCatalog{DefaultLocale: templates})
+}
+
+// NewWebhookWithMessageCatalog creates the new webhook with custom
+// customer-facing messages in several locales, the configured one being
+// used. The templates are validated here, once.
+func NewWebhookWithMessageCatalog(catalog MessageCatalog) (*SCCWebHook, error) {
+	m, err := parseCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+	hook := NewWebhook()
+	hook.catalog = m
+	return hook, nil
+}
+
+// localized returns the messages in the configured locale, falling back to
+// DefaultLocale when the catalog lacks it
+func (s *SCCWebHook) localized() *messages {
+	if m, ok := s.catalog[locale]; ok {
+		return m
+	}
+	return s.catalog[DefaultLocale]
+}
+
 // Authorized implements Webhook interface
 func (s *SCCWebHook) Authorized(request admissionctl.Request) admissionctl.Response {
-	return s.authorized(request)
+	ret := s.authorized(request)
+	if mode == config.ModeDryRun && !ret.Allowed && ret.Result != nil && ret.Result.Code == http.StatusForbidden {
+		return dryRun(ret)
+	}
+	return ret
+}
+
+// dryRun turns a denial into an allowed response warning about it. The audit
+// annotations still record the denial, along with the mode.
+func dryRun(denied admissionctl.Response) admissionctl.Response {
+	warning := fmt.Sprintf("%s would deny this request in %s mode: %s", WebhookName, config.ModeEnforce, denied.Result.Reason)
+	ret := admissionctl.Allowed(warning)
+	ret.UID = denied.UID
+	ret.Warnings = []string{warning}
+	ret.AuditAnnotations = denied.AuditAnnotations
+	if ret.AuditAnnotations == nil {
+		ret.AuditAnnotations = map[string]string{}
+	}
+	ret.AuditAnnotations[auditModeKey] = string(config.ModeDryRun)
+	return ret
 }
 
 func (s *SCCWebHook) authorized(request admissionctl.Request) admissionctl.Response {
-	var ret admissionctl.Response
+	// A request is about one kind of object, so only the decoder and checks
+	// of that kind run. Only SCCs have any: the rules don't send anything
+	// else, and any other kind is refused before being decoded as an empty
+	// SCC would be evaluated.
+	if !utils.KindMatches(request, sccGroup, sccKind) {
+		log.Info(fmt.Sprintf("Unhandled kind %s in %s request %s", request.Kind, request.Operation, request.AdmissionRequest.UID))
+		ret := admissionctl.Errored(http.StatusBadRequest, fmt.Errorf("%s doesn't evaluate %s objects", WebhookName, request.Kind.Kind))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+	return s.authorizedSCC(request)
+}
+
+// authorizedSCC evaluates a request about a SCC
+func (s *SCCWebHook) authorizedSCC(request admissionctl.Request) (ret admissionctl.Response) {
+	// Some subresource operations carry neither an Object nor an OldObject,
+	// so there is no SCC to evaluate
+	if len(request.Object.Raw) == 0 && len(request.OldObject.Raw) == 0 {
+		log.Info(fmt.Sprintf("No object to evaluate in %s request %s", request.Operation, request.AdmissionRequest.UID))
+		ret = admissionctl.Allowed(render(s.localized().allowed, s.templateData(request, "")))
+		ret.UID = request.AdmissionRequest.UID
+		s.recordDecision(&ret, request, "", "no object to evaluate")
+		return ret
+	}
+
+	// Whoever sent it, a request whose objects aren't what request.Kind
+	// says can't be trusted to be evaluated as a SCC
+	if verifyObjectKind {
+		if err := utils.VerifyObjectKind(request); err != nil {
+			log.Info(fmt.Sprintf("Object kind mismatch detected in %s request %s: %v", request.Operation, request.AdmissionRequest.UID, err))
+			ret = admissionctl.Denied(fmt.Sprintf("The request doesn't match its object: %v", err))
+			ret.UID = request.AdmissionRequest.UID
+			s.recordDecision(&ret, request, request.Name, "object kind mismatch")
+			return ret
+		}
+	}
 
 	scc, err := s.renderSCC(request)
 	if err != nil {
@@ -81,57 +418,281 @@ func (s *SCCWebHook) authorized(request admissionctl.Request) admissionctl.Respo
 		return admissionctl.Errored(http.StatusBadRequest, err)
 	}
 
+	// The live version of a default SCC is compared with the OldObject of
+	// the request, and any drift recorded alongside whichever decision is made
+	if drift := utils.ChangedPaths(s.liveDrift(request, scc)); len(drift) > 0 {
+		log.Info(fmt.Sprintf("Default SCC %v differs from its live version", scc.Name), "drift", drift)
+		defer func() {
+			if ret.AuditAnnotations == nil {
+				ret.AuditAnnotations = map[string]string{}
+			}
+			ret.AuditAnnotations[auditDriftKey] = strings.Join(drift, ",")
+		}()
+	}
+
+	// Members of a forbidden group are denied before anything could allow
+	// them but being an allowed user or group
+	if isDefaultSCC(scc) && isForbiddenRequester(request) {
+		log.Info(fmt.Sprintf("%s operation detected on default SCC %v by a member of a forbidden group", request.Operation, scc.Name), "groups", request.UserInfo.Groups)
+		message := s.localized().updateDenied
+		if request.Operation == admissionv1.Delete {
+			message = s.localized().deleteDenied
+		}
+		ret = admissionctl.Denied(render(message, s.templateData(request, scc.Name)))
+		ret.UID = request.AdmissionRequest.UID
+		s.recordDecision(&ret, request, scc.Name, "forbidden requester group")
+		return ret
+	}
+
+	if decision := evaluatePolicy(request); decision.Decided {
+		if decision.Allowed {
+			ret = admissionctl.Allowed(decision.Reason)
+		} else {
+			ret = admissionctl.Denied(decision.Reason)
+		}
+		ret.UID = request.AdmissionRequest.UID
+		s.recordDecision(&ret, request, scc.Name, "configured policy")
+		return ret
+	}
+
+	if request.Operation == admissionv1.Delete && isDefaultSCC(scc) && utils.SliceContains(utils.Identity(request).Username, orphanOnlyDeleteUsers) {
+		policy, err := deletePropagationPolicy(request)
+		if err != nil {
+			log.Error(err, "Couldn't render the DeleteOptions from the incoming request")
+			metrics.IncrementDecodeErrors(WebhookName, "DeleteOptions")
+			return admissionctl.Errored(http.StatusBadRequest, err)
+		}
+		if policy != metav1.DeletePropagationOrphan {
+			log.Info(fmt.Sprintf("Cascading (%s) delete detected on default SCC: %v", policy, scc.Name))
+			ret = admissionctl.Denied(render(s.localized().deleteDenied, s.templateData(request, scc.Name)))
+			ret.UID = request.AdmissionRequest.UID
+			s.recordDecision(&ret, request, scc.Name, "cascading default SCC deletion")
+			return ret
+		}
+	}
+
+	if isDefaultSCC(scc) && !isAllowedUserGroup(request) && isAllowedOnce(request, scc.Name) {
+		log.Info(fmt.Sprintf("%s operation on default SCC %v allowed by an allow-once token", request.Operation, scc.Name))
+		ret = admissionctl.Allowed(render(s.localized().allowed, s.templateData(request, scc.Name)))
+		ret.UID = request.AdmissionRequest.UID
+		s.recordDecision(&ret, request, scc.Name, "allow-once token")
+		return ret
+	}
+
+	if isDefaultSCC(scc) && !isAllowedUserGroup(request) && isMaintenance(request) {
+		log.Info(fmt.Sprintf("%s operation on default SCC %v allowed by the maintenance window", request.Operation, scc.Name))
+		ret = admissionctl.Allowed(render(s.localized().allowed, s.templateData(request, scc.Name)))
+		ret.UID = request.AdmissionRequest.UID
+		s.recordDecision(&ret, request, scc.Name, "maintenance window")
+		return ret
+	}
+
 	if isDefaultSCC(scc) && !isAllowedUserGroup(request) {
 		switch request.Operation {
 		case admissionv1.Delete:
 			log.Info(fmt.Sprintf("Deleting operation detected on default SCC: %v", scc.Name))
-			ret = admissionctl.Denied(fmt.Sprintf("Deleting default SCCs %v is not allowed", defaultSCCs))
+			ret = admissionctl.Denied(render(s.localized().deleteDenied, s.templateData(request, scc.Name)))
 			ret.UID = request.AdmissionRequest.UID
+			s.recordDecision(&ret, request, scc.Name, "default SCC deletion")
 			return ret
 		case admissionv1.Update:
-			log.Info(fmt.Sprintf("Updating operation detected on default SCC: %v", scc.Name))
-			ret = admissionctl.Denied(fmt.Sprintf("Modifying default SCCs %v is not allowed", defaultSCCs))
+			changes := utils.ChangedPaths(s.sccChanges(request))
+			log.Info(fmt.Sprintf("Updating operation detected on default SCC: %v", scc.Name), "changes", changes)
+			data := s.templateData(request, scc.Name)
+			data.Changes = changes
+			ret = admissionctl.Denied(render(s.localized().updateDenied, data))
 			ret.UID = request.AdmissionRequest.UID
+			s.recordDecision(&ret, request, scc.Name, "default SCC modification")
+			ret.AuditAnnotations[auditChangesKey] = strings.Join(changes, ",")
+			ret.Result.Details.Causes = changeCauses(changes)
 			return ret
 		}
 	}
 
-	ret = admissionctl.Allowed("Request is allowed")
+	ret = admissionctl.Allowed(render(s.localized().allowed, s.templateData(request, scc.Name)))
 	ret.UID = request.AdmissionRequest.UID
+	if isDefaultSCC(scc) {
+		s.recordDecision(&ret, request, scc.Name, "allowed user or group")
+	} else {
+		s.recordDecision(&ret, request, scc.Name, "not a default SCC")
+	}
 	return ret
 }
 
-// renderSCC render the SCC object from the requests
-func (s *SCCWebHook) renderSCC(request admissionctl.Request) (*securityv1.SecurityContextConstraints, error) {
-	decoder, err := admissionctl.NewDecoder(&s.s)
-	if err != nil {
-		return nil, err
+// deletePropagationPolicy returns the propagation policy of a DELETE request.
+// The API server defaults an unset policy to a background cascading delete.
+func deletePropagationPolicy(request admissionctl.Request) (metav1.DeletionPropagation, error) {
+	if len(request.Options.Raw) == 0 {
+		return metav1.DeletePropagationBackground, nil
+	}
+	options := &metav1.DeleteOptions{}
+	if err := json.Unmarshal(request.Options.Raw, options); err != nil {
+		return "", err
 	}
-	scc := &securityv1.SecurityContextConstraints{}
+	if options.PropagationPolicy == nil {
+		return metav1.DeletePropagationBackground, nil
+	}
+	return *options.PropagationPolicy, nil
+}
+
+// templateData returns the data the message templates are rendered with
+func (s *SCCWebHook) templateData(request admissionctl.Request, sccName string) messageData {
+	return messageData{
+		SCC:         sccName,
+		Operation:   request.Operation,
+		User:        utils.Identity(request).Username,
+		DefaultSCCs: s.protectedSCCs,
+	}
+}
 
-	if len(request.OldObject.Raw) > 0 {
-		err = decoder.DecodeRaw(request.OldObject, scc)
+// sortedCopy returns a sorted copy of the list, leaving the original as is
+func sortedCopy(list []string) []string {
+	sorted := make([]string, len(list))
+	copy(sorted, list)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// recordDecision traces the decision at verbosity 2 and records it in the
+// audit annotations of the response, and in recentDenials when denied unless
+// the webhook is synthetic
+func (s *SCCWebHook) recordDecision(ret *admissionctl.Response, request admissionctl.Request, sccName string, reason string) {
+	setAuditAnnotations(ret, reason)
+	if !ret.Allowed {
+		setDeniedDetails(ret, sccName)
+	}
+	if !ret.Allowed && !s.synthetic {
+		recentDenials.Record(utils.Denial{
+			Time:     clock.Now(),
+			User:     utils.Identity(request).Username,
+			Resource: fmt.Sprintf("%s %s", request.Operation, sccName),
+			Reason:   reason,
+		})
+	}
+	log.V(2).Info("Decision", "uid", request.AdmissionRequest.UID, "scc", sccName, "operation", request.Operation, "user", request.UserInfo.Username, "decision", ret.AuditAnnotations[auditDecisionKey], "reason", reason)
+}
+
+// setDeniedDetails identifies the denied SCC in the Details of the result,
+// which clients such as oc surface along with the message
+func setDeniedDetails(ret *admissionctl.Response, sccName string) {
+	if ret.Result == nil {
+		return
+	}
+	ret.Result.Details = &metav1.StatusDetails{
+		Group: sccGroup,
+		Kind:  sccKind,
+		Name:  sccName,
+	}
+}
+
+// changeCauses returns a cause for each changed field of a default SCC
+func changeCauses(changes []string) []metav1.StatusCause {
+	causes := make([]metav1.StatusCause, 0, len(changes))
+	for _, change := range changes {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Field:   change,
+			Message: "field of a default SCC may not be changed",
+		})
+	}
+	return causes
+}
+
+// setAuditAnnotations records the decision and its reason on the response so
+// it can be queried from the API server audit log. The API server prefixes
+// each key with the name of the webhook, eg
+// scc-validation.managed.openshift.io/decision
+func setAuditAnnotations(ret *admissionctl.Response, reason string) {
+	decision := auditDecisionDeny
+	if ret.Allowed {
+		decision = auditDecisionAllow
+	}
+	if ret.AuditAnnotations == nil {
+		ret.AuditAnnotations = map[string]string{}
 	}
+	ret.AuditAnnotations[auditDecisionKey] = decision
+	ret.AuditAnnotations[auditReasonKey] = reason
+}
+
+// renderSCC render the SCC object from the requests
+func (s *SCCWebHook) renderSCC(request admissionctl.Request) (*securityv1.SecurityContextConstraints, error) {
+	log.V(4).Info("Decoding SCC", "uid", request.AdmissionRequest.UID, "kind", request.Kind, "objectBytes", len(request.Object.Raw), "oldObjectBytes", len(request.OldObject.Raw))
+	_, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &securityv1.SecurityContextConstraints{} })
 	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
 		return nil, err
 	}
+	if oldObj == nil {
+		log.V(4).Info("No existing SCC in the request", "uid", request.AdmissionRequest.UID)
+		return &securityv1.SecurityContextConstraints{}, nil
+	}
 
+	scc := oldObj.(*securityv1.SecurityContextConstraints)
+	log.V(4).Info("Decoded SCC", "uid", request.AdmissionRequest.UID, "scc", scc.Name)
 	return scc, nil
 }
 
-// isAllowedUserGroup checks if the user or group is allowed to perform the action
+// sccChanges returns the fields an UPDATE changes on the SCC
+func (s *SCCWebHook) sccChanges(request admissionctl.Request) []utils.FieldChange {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &securityv1.SecurityContextConstraints{} })
+	if err != nil {
+		log.Error(err, "Couldn't render the SCCs to compare from the incoming request")
+		return nil
+	}
+	return utils.DiffObjects(oldObj, newObj)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// requested operation. The platform identities shared by every webhook are
+// allowed any operation.
 func isAllowedUserGroup(request admissionctl.Request) bool {
-	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+	if utils.IsPlatformIdentity(request.UserInfo) {
 		return true
 	}
 
-	for _, group := range allowedGroups {
-		if utils.SliceContains(group, request.UserInfo.Groups) {
-			return true
-		}
+	identity := utils.Identity(request)
+	if utils.SliceContains(identity.Username, allowedUsers[request.Operation]) {
+		return true
 	}
 
-	return false
+	if utils.GroupsMatch(allowedGroups[request.Operation], identity.Groups) {
+		return true
+	}
+
+	if utils.AllowlistContains(request.UserInfo, allowlist[request.Operation], clock.Now()) {
+		return true
+	}
+
+	return utils.ExtraContains(request.UserInfo.Extra, allowedExtra)
+}
+
+// isForbiddenRequester checks if the requester belongs to any of the
+// forbiddenRequesterGroups without being an allowed user or group
+func isForbiddenRequester(request admissionctl.Request) bool {
+	return utils.GroupsMatch(forbiddenRequesterGroups, utils.Identity(request).Groups) && !isAllowedUserGroup(request)
+}
+
+// isMaintenance checks if the request comes from the managed automation
+// during a maintenance window of the webhook
+func isMaintenance(request admissionctl.Request) bool {
+	return utils.GroupsMatch(maintenanceGroups, utils.Identity(request).Groups) && config.InMaintenance(WebhookName, clock.Now())
+}
+
+// isAllowedOnce checks if the request carries a valid allow-once token for the
+// SCC, which it consumes. Every token presented is logged and counted.
+func isAllowedOnce(request admissionctl.Request, sccName string) bool {
+	token, err := allowOnce.Verify(request, WebhookName, "", sccName)
+	if err == utils.ErrNoAllowOnceToken {
+		return false
+	}
+	if err != nil {
+		log.Info("Rejected allow-once token", "uid", request.AdmissionRequest.UID, "user", request.UserInfo.Username, "scc", sccName, "reason", err.Error())
+		metrics.IncrementAllowOnceTokens(WebhookName, "rejected")
+		return false
+	}
+	log.Info("Accepted allow-once token", "uid", request.AdmissionRequest.UID, "user", request.UserInfo.Username, "scc", sccName, "operation", request.Operation, "token", token.ID)
+	metrics.IncrementAllowOnceTokens(WebhookName, "accepted")
+	return true
 }
 
 // isDefaultSCC checks if the request is going to operate on the SCC in the
@@ -153,8 +714,11 @@ func (s *SCCWebHook) GetURI() string {
 // Validate implements Webhook interface
 func (s *SCCWebHook) Validate(request admissionctl.Request) bool {
 	valid := true
-	valid = valid && (request.UserInfo.Username != "")
-	valid = valid && (request.Kind.Kind == "SecurityContextConstraints")
+	valid = valid && (utils.Identity(request).Username != "")
+	// A CRD in another API group may share the SCC kind name, so the group
+	// must match as well. SCCs submitted through another API path are
+	// recognized by their RequestKind.
+	valid = valid && utils.KindMatches(request, sccGroup, sccKind)
 
 	return valid
 }
@@ -169,6 +733,13 @@ func (s *SCCWebHook) FailurePolicy() admissionregv1.FailurePolicyType {
 	return admissionregv1.Ignore
 }
 
+// TimeoutResponse implements webhooks.TimeoutDecider. The default SCCs are
+// protected, so a request the webhook couldn't decide on is denied rather
+// than let through as the Ignore FailurePolicy would.
+func (s *SCCWebHook) TimeoutResponse(request admissionctl.Request) admissionctl.Response {
+	return admissionctl.Denied("The webhook could not decide on the request")
+}
+
 // MatchPolicy implements Webhook interface
 func (s *SCCWebHook) MatchPolicy() admissionregv1.MatchPolicyType {
 	return admissionregv1.Equivalent
@@ -176,7 +747,17 @@ func (s *SCCWebHook) MatchPolicy() admissionregv1.MatchPolicyType {
 
 // Rules implements Webhook interface
 func (s *SCCWebHook) Rules() []admissionregv1.RuleWithOperations {
-	return rules
+	return []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{sccGroup},
+				APIVersions: apiVersions,
+				Resources:   []string{"securitycontextconstraints"},
+				Scope:       &scope,
+			},
+		},
+	}
 }
 
 // ObjectSelector implements Webhook interface
@@ -196,7 +777,7 @@ func (s *SCCWebHook) TimeoutSeconds() int32 {
 
 // Doc implements Webhook interface
 func (s *SCCWebHook) Doc() string {
-	return fmt.Sprintf(docString, defaultSCCs)
+	return render(s.localized().doc, messageData{DefaultSCCs: s.protectedSCCs})
 }
 
 // SyncSetLabelSelector returns the label selector to use in the SyncSet.
@@ -2,11 +2,24 @@ package scc
 
 import (
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
 	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -153,3 +166,1204 @@ func TestUserPositive(t *testing.T) {
 	}
 	runSCCTests(t, tests)
 }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		testID       string
+		group        string
+		kind         string
+		requestGroup string
+		requestKind  string
+		shouldValid  bool
+	}{
+		{
+			testID:      "scc-is-valid",
+			group:       "security.openshift.io",
+			kind:        "SecurityContextConstraints",
+			shouldValid: true,
+		},
+		{
+			testID:      "look-alike-kind-other-group",
+			group:       "security.example.com",
+			kind:        "SecurityContextConstraints",
+			shouldValid: false,
+		},
+		{
+			testID:      "other-kind-same-group",
+			group:       "security.openshift.io",
+			kind:        "RangeAllocation",
+			shouldValid: false,
+		},
+		{
+			testID:       "scc-submitted-through-another-api",
+			group:        "security.example.com",
+			kind:         "ContainerConstraints",
+			requestGroup: "security.openshift.io",
+			requestKind:  "SecurityContextConstraints",
+			shouldValid:  true,
+		},
+		{
+			testID:       "other-kind-submitted-through-another-api",
+			group:        "security.example.com",
+			kind:         "ContainerConstraints",
+			requestGroup: "security.example.com",
+			requestKind:  "ContainerConstraints",
+			shouldValid:  false,
+		},
+	}
+	for _, test := range tests {
+		gvk := metav1.GroupVersionKind{
+			Group:   test.group,
+			Version: "v1",
+			Kind:    test.kind,
+		}
+		gvr := metav1.GroupVersionResource{
+			Group:    test.group,
+			Version:  "v1",
+			Resource: "securitycontextconstraints",
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(createRawJSONString("anyuid")),
+		}
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, "user1", []string{"system:authenticated"}, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		request, _, err := utils.ParseHTTPRequest(httprequest)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if test.requestKind != "" {
+			request.RequestKind = &metav1.GroupVersionKind{Group: test.requestGroup, Version: "v1", Kind: test.requestKind}
+		}
+		if hook.Validate(request) != test.shouldValid {
+			t.Fatalf("%s: Mismatch: expected Validate to be %t for %s.%s", test.testID, test.shouldValid, test.kind, test.group)
+		}
+	}
+}
+
+func TestAuditAnnotations(t *testing.T) {
+	tests := []struct {
+		sccTestSuites
+		expectedDecision string
+		expectedReason   string
+	}{
+		{
+			sccTestSuites: sccTestSuites{
+				targetSCC:       "privileged",
+				testID:          "deny-is-annotated",
+				username:        "user1",
+				operation:       admissionv1.Delete,
+				userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+				shouldBeAllowed: false,
+			},
+			expectedDecision: "deny",
+			expectedReason:   "default SCC deletion",
+		},
+		{
+			sccTestSuites: sccTestSuites{
+				targetSCC:       "testscc",
+				testID:          "allow-is-annotated",
+				username:        "user1",
+				operation:       admissionv1.Update,
+				userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+				shouldBeAllowed: true,
+			},
+			expectedDecision: "allow",
+			expectedReason:   "not a default SCC",
+		},
+	}
+	gvk := metav1.GroupVersionKind{
+		Group:   "security.openshift.io",
+		Version: "v1",
+		Kind:    "SecurityContextConstraints",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "security.openshift.io",
+		Version:  "v1",
+		Resource: "securitycontextcontraints",
+	}
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(createRawJSONString(test.targetSCC)),
+		}
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &obj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("%s: Mismatch: expected allowed to be %t", test.testID, test.shouldBeAllowed)
+		}
+		if response.AuditAnnotations["decision"] != test.expectedDecision {
+			t.Fatalf("%s: Expected decision audit annotation %q, got %q", test.testID, test.expectedDecision, response.AuditAnnotations["decision"])
+		}
+		if response.AuditAnnotations["reason"] != test.expectedReason {
+			t.Fatalf("%s: Expected reason audit annotation %q, got %q", test.testID, test.expectedReason, response.AuditAnnotations["reason"])
+		}
+		// The ownership annotation must survive alongside the decision
+		if response.AuditAnnotations["owner"] != "srep-managed-webhook" {
+			t.Fatalf("%s: Expected owner audit annotation to be preserved, got %v", test.testID, response.AuditAnnotations)
+		}
+	}
+}
+
+func TestDecodeErrorMetric(t *testing.T) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "security.openshift.io",
+		Version: "v1",
+		Kind:    "SecurityContextConstraints",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "security.openshift.io",
+		Version:  "v1",
+		Resource: "securitycontextcontraints",
+	}
+	// The name is a number, which can't be decoded into ObjectMeta
+	obj := runtime.RawExtension{
+		Raw: []byte(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": 1234}}`),
+	}
+	counter := metrics.DecodeErrors.WithLabelValues(WebhookName, "SecurityContextConstraints")
+	before := promtestutil.ToFloat64(counter)
+
+	hook := NewWebhook()
+	httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+		"malformed-scc", gvk, gvr, admissionv1.Update, "user1", []string{"system:authenticated"}, &obj, &obj)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	response, err := testutils.SendHTTPRequest(httprequest, hook)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if response.Allowed {
+		t.Fatalf("Expected a malformed SCC to not be allowed")
+	}
+	if after := promtestutil.ToFloat64(counter); after != before+1 {
+		t.Fatalf("Expected webhook_decode_errors_total to increment from %v, got %v", before, after)
+	}
+}
+
+func TestPerOperationAllowlist(t *testing.T) {
+	oldUsers, oldGroups := allowedUsers, allowedGroups
+	defer func() { allowedUsers, allowedGroups = oldUsers, oldGroups }()
+	allowedUsers = map[admissionv1.Operation][]string{
+		admissionv1.Update: {"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator"},
+	}
+	allowedGroups = map[admissionv1.Operation][]string{
+		admissionv1.Delete: {"sre-delete-group"},
+	}
+
+	tests := []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "update-only-user-can-modify-default",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "update-only-user-cant-delete-default",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "delete-only-group-can-delete-default",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "sre-delete-group"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "delete-only-group-cant-modify-default",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "sre-delete-group"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCTests(t, tests)
+}
+
+func TestExtraAttributeAllowlist(t *testing.T) {
+	oldExtra := allowedExtra
+	defer func() { allowedExtra = oldExtra }()
+	allowedExtra = map[string][]string{
+		"authentication.kubernetes.io/pod-name": {"managed-operator-0"},
+	}
+
+	tests := []struct {
+		testID          string
+		extra           map[string]authenticationv1.ExtraValue
+		shouldBeAllowed bool
+	}{
+		{
+			testID: "matching-extra-can-modify-default",
+			extra: map[string]authenticationv1.ExtraValue{
+				"authentication.kubernetes.io/pod-name": {"managed-operator-0"},
+			},
+			shouldBeAllowed: true,
+		},
+		{
+			testID: "non-matching-extra-cant-modify-default",
+			extra: map[string]authenticationv1.ExtraValue{
+				"authentication.kubernetes.io/pod-name": {"customer-pod"},
+			},
+			shouldBeAllowed: false,
+		},
+		{
+			testID: "matching-value-under-other-key-cant-modify-default",
+			extra: map[string]authenticationv1.ExtraValue{
+				"scopes.authorization.openshift.io": {"managed-operator-0"},
+			},
+			shouldBeAllowed: false,
+		},
+	}
+
+	hook := NewWebhook()
+	for _, test := range tests {
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       types.UID(test.testID),
+				Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+				Operation: admissionv1.Update,
+				UserInfo: authenticationv1.UserInfo{
+					Username: "system:serviceaccount:some-namespace:some-operator",
+					Groups:   []string{"system:serviceaccounts"},
+					Extra:    test.extra,
+				},
+				Object:    runtime.RawExtension{Raw: []byte(createRawJSONString("hostaccess"))},
+				OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("hostaccess"))},
+			},
+		}
+		response := hook.Authorized(request)
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: response allowed is %t, expected %t", test.testID, response.Allowed, test.shouldBeAllowed)
+		}
+	}
+}
+
+// TestFailurePolicy documents that, because the webhook is declared with
+// FailurePolicy Ignore, default SCCs are unprotected while it is unavailable.
+// Changing the policy must be a deliberate decision, so this test has to be
+// updated alongside it.
+func TestFailurePolicy(t *testing.T) {
+	hook := NewWebhook()
+	if hook.FailurePolicy() != admissionregv1.Ignore {
+		t.Fatalf("Expected FailurePolicy %s, got %s", admissionregv1.Ignore, hook.FailurePolicy())
+	}
+
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "failure-policy",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Delete,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user1",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("hostaccess"))},
+		},
+	}
+	for _, callErr := range []error{testutils.ErrUnreachable, testutils.ErrCallTimeout} {
+		if !testutils.SimulateAdmission(hook, request, callErr) {
+			t.Fatalf("Expected deletion of a default SCC to go through when the call fails with %v", callErr)
+		}
+	}
+	if testutils.SimulateAdmission(hook, request, nil) {
+		t.Fatalf("Expected deletion of a default SCC to be denied while the webhook is available")
+	}
+}
+
+func TestNoObjectToEvaluate(t *testing.T) {
+	hook := NewWebhook()
+	for _, operation := range []admissionv1.Operation{admissionv1.Update, admissionv1.Delete} {
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       types.UID(fmt.Sprintf("no-object-%s", operation)),
+				Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+				Operation: operation,
+				UserInfo: authenticationv1.UserInfo{
+					Username: "user1",
+					Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+				},
+			},
+		}
+		response := hook.Authorized(request)
+		if !response.Allowed {
+			t.Fatalf("Expected %s request without any object to be allowed", operation)
+		}
+		if response.UID != request.UID {
+			t.Fatalf("Expected response UID %s, got %s", request.UID, response.UID)
+		}
+		if reason := response.AuditAnnotations[auditReasonKey]; reason != "no object to evaluate" {
+			t.Fatalf("Expected reason %q, got %q", "no object to evaluate", reason)
+		}
+	}
+}
+
+func TestServerSideApply(t *testing.T) {
+	// The API server reviews a server-side apply as the UPDATE of the
+	// existing object it amounts to, with the PatchOptions of the apply
+	options := runtime.RawExtension{Raw: []byte(`{"kind": "PatchOptions", "apiVersion": "meta.k8s.io/v1", "fieldManager": "kubectl", "force": true}`)}
+	tests := []struct {
+		testID          string
+		targetSCC       string
+		username        string
+		shouldBeAllowed bool
+	}{
+		{"user-cant-apply-privileged", "privileged", "user1", false},
+		{"user-can-apply-own-scc", "my-scc", "user1", true},
+		{"cmo-can-apply-privileged", "privileged", "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator", true},
+	}
+	hook := NewWebhook()
+	for _, test := range tests {
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       types.UID(test.testID),
+				Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+				Resource:  metav1.GroupVersionResource{Group: sccGroup, Version: "v1", Resource: "securitycontextconstraints"},
+				Name:      test.targetSCC,
+				Operation: admissionv1.Update,
+				UserInfo: authenticationv1.UserInfo{
+					Username: test.username,
+					Groups:   []string{"system:authenticated"},
+				},
+				Object:    runtime.RawExtension{Raw: []byte(createRawJSONString(test.targetSCC))},
+				OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString(test.targetSCC))},
+				Options:   options,
+			},
+		}
+		response := hook.Authorized(request)
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s %s apply the %s SCC in test %s. Test's expectation is that the user %s", test.username, testutils.CanCanNot(response.Allowed), test.targetSCC, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+		if !test.shouldBeAllowed && response.AuditAnnotations[auditReasonKey] != "default SCC modification" {
+			t.Fatalf("Expected the apply to be denied as a default SCC modification in test %s, got %q", test.testID, response.AuditAnnotations[auditReasonKey])
+		}
+	}
+}
+
+func TestUpdateChanges(t *testing.T) {
+	hook, err := NewWebhookWithMessageTemplates(MessageTemplates{
+		Allowed:      DefaultMessageTemplates.Allowed,
+		DeleteDenied: DefaultMessageTemplates.DeleteDenied,
+		UpdateDenied: "Changing {{.Changes}} of {{.SCC}} is not allowed",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	old := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted", "resourceVersion": "1"}, "allowPrivilegedContainer": false, "users": ["a"]}`
+	new := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted", "resourceVersion": "2"}, "allowPrivilegedContainer": true, "users": ["a", "b"]}`
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "update-changes",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Update,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user1",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			Object:    runtime.RawExtension{Raw: []byte(new)},
+			OldObject: runtime.RawExtension{Raw: []byte(old)},
+		},
+	}
+
+	response := hook.Authorized(request)
+	if response.Allowed {
+		t.Fatalf("Expected the update of a default SCC to be denied")
+	}
+	if changes := response.AuditAnnotations[auditChangesKey]; changes != "allowPrivilegedContainer,users" {
+		t.Fatalf("Expected the changes to be audited as %q, got %q", "allowPrivilegedContainer,users", changes)
+	}
+	if expected := "Changing [allowPrivilegedContainer users] of restricted is not allowed"; response.Result.Reason != metav1.StatusReason(expected) {
+		t.Fatalf("Expected message %q, got %q", expected, response.Result.Reason)
+	}
+}
+
+func TestDeniedDetails(t *testing.T) {
+	old := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted"}, "allowHostNetwork": false, "users": ["a"]}`
+	new := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "restricted"}, "allowHostNetwork": true, "users": ["a", "b"]}`
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "denied-details",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Update,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user1",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			Object:    runtime.RawExtension{Raw: []byte(new)},
+			OldObject: runtime.RawExtension{Raw: []byte(old)},
+		},
+	}
+
+	response := NewWebhook().Authorized(request)
+	if response.Allowed {
+		t.Fatalf("Expected the update of a default SCC to be denied")
+	}
+	details := response.Result.Details
+	if details == nil {
+		t.Fatalf("Expected the denial to carry details")
+	}
+	if details.Group != sccGroup || details.Kind != sccKind || details.Name != "restricted" {
+		t.Fatalf("Expected the details to identify SCC restricted, got %+v", details)
+	}
+	expectedFields := []string{"allowHostNetwork", "users"}
+	if len(details.Causes) != len(expectedFields) {
+		t.Fatalf("Expected causes for %v, got %+v", expectedFields, details.Causes)
+	}
+	for i, field := range expectedFields {
+		if details.Causes[i].Field != field || details.Causes[i].Type != metav1.CauseTypeFieldValueInvalid {
+			t.Fatalf("Expected an invalid value cause for %s, got %+v", field, details.Causes[i])
+		}
+	}
+}
+
+func TestStructuredAllowlist(t *testing.T) {
+	oldUsers, oldAllowlist := allowedUsers, allowlist
+	defer func() { allowedUsers, allowlist = oldUsers, oldAllowlist }()
+	allowedUsers = map[admissionv1.Operation][]string{}
+	allowlist = map[admissionv1.Operation][]utils.AllowlistEntry{
+		admissionv1.Update: {
+			{Type: utils.AllowlistServiceAccount, Namespace: "openshift-monitoring", Name: "cluster-monitoring-operator"},
+		},
+	}
+
+	tests := []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "service-account-can-modify-default",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "user-named-like-service-account-cant-modify-default",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCTests(t, tests)
+}
+
+func TestPlatformAllowedIdentities(t *testing.T) {
+	tests := []sccTestSuites{
+		{
+			targetSCC:       "privileged",
+			testID:          "cluster-version-operator-can-modify-default",
+			username:        "system:serviceaccount:openshift-cluster-version:default",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-version"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "privileged",
+			testID:          "cluster-version-operator-can-delete-default",
+			username:        "system:serviceaccount:openshift-cluster-version:default",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-version"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "privileged",
+			testID:          "user-named-like-platform-identity-cant-modify-default",
+			username:        "system:serviceaccount:openshift-cluster-version:default",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCTests(t, tests)
+}
+
+func TestPinnedAPIVersions(t *testing.T) {
+	oldVersions := apiVersions
+	defer func() { apiVersions = oldVersions }()
+
+	if versions := NewWebhook().Rules()[0].APIVersions; !reflect.DeepEqual(versions, []string{"*"}) {
+		t.Fatalf("Expected the rule to match every version by default, got %v", versions)
+	}
+
+	apply, err := applySettings(config.WebhookSettings{Parameters: map[string]string{apiVersionsParameter: "v1"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	if versions := NewWebhook().Rules()[0].APIVersions; !reflect.DeepEqual(versions, []string{"v1"}) {
+		t.Fatalf("Expected the rule to be pinned to v1, got %v", versions)
+	}
+
+	for _, value := range []string{"", "v1,", "*,v1"} {
+		if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{apiVersionsParameter: value}}); err == nil {
+			t.Fatalf("Expected an error for %s %q", apiVersionsParameter, value)
+		}
+	}
+}
+
+func TestObjectKindMismatch(t *testing.T) {
+	oldVerify := verifyObjectKind
+	defer func() { verifyObjectKind = oldVerify }()
+
+	// request.Kind says SCC, but the object is a ClusterRoleBinding
+	crb := `{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRoleBinding", "metadata": {"name": "my-scc", "uid": "1234"}}`
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "kind-mismatch",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Name:      "my-scc",
+			Operation: admissionv1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "user1", Groups: []string{"system:authenticated"}},
+			Object:    runtime.RawExtension{Raw: []byte(crb)},
+			OldObject: runtime.RawExtension{Raw: []byte(crb)},
+		},
+	}
+
+	response := NewWebhook().Authorized(request)
+	if response.Allowed {
+		t.Fatalf("Expected a request whose object isn't a SCC to be denied")
+	}
+	if response.AuditAnnotations[auditReasonKey] != "object kind mismatch" {
+		t.Fatalf("Expected the object kind mismatch to be the audited reason, got %q", response.AuditAnnotations[auditReasonKey])
+	}
+
+	apply, err := applySettings(config.WebhookSettings{Parameters: map[string]string{verifyObjectKindParameter: "false"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	// Without the cross-check, the object only fails to decode as a SCC
+	if response := NewWebhook().Authorized(request); response.Result.Code != http.StatusBadRequest {
+		t.Fatalf("Expected the object to fail decoding as a SCC without the cross-check, got %v", response.Result)
+	}
+
+	if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{verifyObjectKindParameter: "sometimes"}}); err == nil {
+		t.Fatalf("Expected an error for %s %q", verifyObjectKindParameter, "sometimes")
+	}
+}
+
+func TestKindBranching(t *testing.T) {
+	crb := `{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRoleBinding", "metadata": {"name": "my-crb", "uid": "1234"}}`
+	crbRequest := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "crb-kind",
+			Kind:      metav1.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+			Resource:  metav1.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+			Name:      "my-crb",
+			Operation: admissionv1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "user1", Groups: []string{"system:authenticated"}},
+			Object:    runtime.RawExtension{Raw: []byte(crb)},
+			OldObject: runtime.RawExtension{Raw: []byte(crb)},
+		},
+	}
+	decodeErrors := []float64{
+		promtestutil.ToFloat64(metrics.DecodeErrors.WithLabelValues(WebhookName, "ClusterRoleBinding")),
+		promtestutil.ToFloat64(metrics.DecodeErrors.WithLabelValues(WebhookName, sccKind)),
+	}
+
+	// A ClusterRoleBinding runs neither the SCC decoder nor the SCC checks
+	response := NewWebhook().Authorized(crbRequest)
+	if response.Allowed || response.Result.Code != http.StatusBadRequest {
+		t.Fatalf("Expected a ClusterRoleBinding to be refused as unhandled, got %v", response.Result)
+	}
+	if response.AuditAnnotations[auditReasonKey] != "" {
+		t.Fatalf("Expected no SCC check to run on a ClusterRoleBinding, got reason %q", response.AuditAnnotations[auditReasonKey])
+	}
+	if after := []float64{
+		promtestutil.ToFloat64(metrics.DecodeErrors.WithLabelValues(WebhookName, "ClusterRoleBinding")),
+		promtestutil.ToFloat64(metrics.DecodeErrors.WithLabelValues(WebhookName, sccKind)),
+	}; !reflect.DeepEqual(after, decodeErrors) {
+		t.Fatalf("Expected no decoding of a ClusterRoleBinding, webhook_decode_errors_total went from %v to %v", decodeErrors, after)
+	}
+
+	// A SCC runs the SCC checks
+	scc := createRawJSONString("privileged")
+	sccRequest := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "scc-kind",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Resource:  metav1.GroupVersionResource{Group: sccGroup, Version: "v1", Resource: "securitycontextconstraints"},
+			Name:      "privileged",
+			Operation: admissionv1.Delete,
+			UserInfo:  authenticationv1.UserInfo{Username: "user1", Groups: []string{"system:authenticated"}},
+			OldObject: runtime.RawExtension{Raw: []byte(scc)},
+		},
+	}
+	response = NewWebhook().Authorized(sccRequest)
+	if response.Allowed {
+		t.Fatalf("Expected the deletion of a default SCC to be denied")
+	}
+	if response.AuditAnnotations[auditReasonKey] != "default SCC deletion" {
+		t.Fatalf("Expected the SCC checks to run on a SCC, got reason %q", response.AuditAnnotations[auditReasonKey])
+	}
+}
+
+func TestExpiringAllowlist(t *testing.T) {
+	oldUsers, oldAllowlist, oldClock := allowedUsers, allowlist, clock
+	defer func() { allowedUsers, allowlist, clock = oldUsers, oldAllowlist, oldClock }()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock = utils.NewFakeClock(now)
+	allowedUsers = map[admissionv1.Operation][]string{}
+	allowlist = map[admissionv1.Operation][]utils.AllowlistEntry{
+		admissionv1.Update: {
+			{Type: utils.AllowlistUser, Name: "migration-user", Expires: now.Add(24 * time.Hour)},
+			{Type: utils.AllowlistUser, Name: "former-migration-user", Expires: now.Add(-time.Hour)},
+		},
+	}
+
+	tests := []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "active-exception-can-modify-default",
+			username:        "migration-user",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "expired-exception-cant-modify-default",
+			username:        "former-migration-user",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCTests(t, tests)
+}
+
+// capturingLogger records the messages of the enabled verbosity levels
+type capturingLogger struct {
+	level     int
+	verbosity int
+	messages  *[]string
+}
+
+func (c *capturingLogger) Enabled() bool { return c.level <= c.verbosity }
+
+func (c *capturingLogger) Info(msg string, keysAndValues ...interface{}) {
+	if c.Enabled() {
+		*c.messages = append(*c.messages, msg)
+	}
+}
+
+func (c *capturingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	*c.messages = append(*c.messages, msg)
+}
+
+func (c *capturingLogger) V(level int) logr.Logger {
+	return &capturingLogger{level: c.level + level, verbosity: c.verbosity, messages: c.messages}
+}
+
+func (c *capturingLogger) WithValues(keysAndValues ...interface{}) logr.Logger { return c }
+
+func (c *capturingLogger) WithName(name string) logr.Logger { return c }
+
+func TestVerbosity(t *testing.T) {
+	oldLog := log
+	defer func() { log = oldLog }()
+
+	tests := []struct {
+		verbosity       int
+		expectDecision  bool
+		expectDecodeLog bool
+	}{
+		{verbosity: 0, expectDecision: false, expectDecodeLog: false},
+		{verbosity: 2, expectDecision: true, expectDecodeLog: false},
+		{verbosity: 4, expectDecision: true, expectDecodeLog: true},
+	}
+	for _, test := range tests {
+		messages := []string{}
+		log = &capturingLogger{verbosity: test.verbosity, messages: &messages}
+
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       "verbosity",
+				Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+				Operation: admissionv1.Update,
+				UserInfo: authenticationv1.UserInfo{
+					Username: "user1",
+					Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+				},
+				Object:    runtime.RawExtension{Raw: []byte(createRawJSONString("my-scc"))},
+				OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("my-scc"))},
+			},
+		}
+		NewWebhook().Authorized(request)
+
+		if got := utils.SliceContains("Decision", messages); got != test.expectDecision {
+			t.Fatalf("At verbosity %d expected decision logged to be %t, got messages %v", test.verbosity, test.expectDecision, messages)
+		}
+		if got := utils.SliceContains("Decoded SCC", messages); got != test.expectDecodeLog {
+			t.Fatalf("At verbosity %d expected decode details logged to be %t, got messages %v", test.verbosity, test.expectDecodeLog, messages)
+		}
+	}
+}
+
+func TestProtectedSCCOrdering(t *testing.T) {
+	oldSCCs := defaultSCCs
+	defer func() { defaultSCCs = oldSCCs }()
+
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "ordering",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Delete,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "user1",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("hostaccess"))},
+		},
+	}
+
+	var docs, reasons []string
+	for _, order := range [][]string{
+		{"restricted", "anyuid", "hostaccess"},
+		{"hostaccess", "restricted", "anyuid"},
+		{"anyuid", "hostaccess", "restricted"},
+	} {
+		defaultSCCs = order
+		hook := NewWebhook()
+		docs = append(docs, hook.Doc())
+		reasons = append(reasons, string(hook.Authorized(request).Result.Reason))
+	}
+
+	expectedReason := "Deleting default SCCs [anyuid hostaccess restricted] is not allowed"
+	for i := range docs {
+		if docs[i] != docs[0] {
+			t.Fatalf("Expected a stable Doc, got %q and %q", docs[0], docs[i])
+		}
+		if reasons[i] != expectedReason {
+			t.Fatalf("Expected reason %q, got %q", expectedReason, reasons[i])
+		}
+	}
+}
+
+func TestDeletePropagationPolicy(t *testing.T) {
+	oldOrphanOnly := orphanOnlyDeleteUsers
+	defer func() { orphanOnlyDeleteUsers = oldOrphanOnly }()
+	orphanOnlyDeleteUsers = []string{"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator"}
+
+	tests := []struct {
+		testID          string
+		username        string
+		options         string
+		shouldBeAllowed bool
+	}{
+		{
+			testID:          "foreground-delete-denied",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			options:         `{"kind":"DeleteOptions","apiVersion":"meta.k8s.io/v1","propagationPolicy":"Foreground"}`,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "background-delete-denied",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			options:         `{"kind":"DeleteOptions","apiVersion":"meta.k8s.io/v1","propagationPolicy":"Background"}`,
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "unset-policy-delete-denied",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			options:         "",
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "orphan-delete-allowed",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			options:         `{"kind":"DeleteOptions","apiVersion":"meta.k8s.io/v1","propagationPolicy":"Orphan"}`,
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "orphan-delete-by-customer-denied",
+			username:        "user1",
+			options:         `{"kind":"DeleteOptions","apiVersion":"meta.k8s.io/v1","propagationPolicy":"Orphan"}`,
+			shouldBeAllowed: false,
+		},
+	}
+
+	hook := NewWebhook()
+	for _, test := range tests {
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UID:       types.UID(test.testID),
+				Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+				Operation: admissionv1.Delete,
+				UserInfo: authenticationv1.UserInfo{
+					Username: test.username,
+					Groups:   []string{"system:authenticated"},
+				},
+				OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("privileged"))},
+				Options:   runtime.RawExtension{Raw: []byte(test.options)},
+			},
+		}
+		response := hook.Authorized(request)
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s %s delete the privileged SCC. Test's expectation is that the user %s", test.testID, test.username, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestWildcardGroupAllowlist(t *testing.T) {
+	oldGroups := allowedGroups
+	defer func() { allowedGroups = oldGroups }()
+	allowedGroups = map[admissionv1.Operation][]string{
+		admissionv1.Update: {"system:serviceaccounts:openshift-*"},
+	}
+
+	tests := []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "openshift-service-account-can-modify-default",
+			username:        "system:serviceaccount:openshift-monitoring:prometheus-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "customer-service-account-cant-modify-default",
+			username:        "system:serviceaccount:openshiftcustomer:builder",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshiftcustomer"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "hostaccess",
+			testID:          "wildcard-doesnt-apply-to-other-operations",
+			username:        "system:serviceaccount:openshift-monitoring:prometheus-operator",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCTests(t, tests)
+}
+
+func TestRecentDenials(t *testing.T) {
+	oldDenials := recentDenials
+	defer func() { recentDenials = oldDenials }()
+	recentDenials = utils.NewDenialLog(2)
+
+	tests := []sccTestSuites{
+		{
+			targetSCC:       "hostaccess",
+			testID:          "denied-update",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "customer-scc",
+			testID:          "allowed-update",
+			username:        "user2",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "privileged",
+			testID:          "denied-delete",
+			username:        "user3",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCTests(t, tests)
+
+	denials := RecentDenials()
+	if len(denials) != 2 {
+		t.Fatalf("Expected 2 recent denials, got %v", denials)
+	}
+	if denials[0].User != "user1" || denials[0].Resource != "UPDATE hostaccess" || denials[0].Reason != "default SCC modification" {
+		t.Fatalf("Unexpected first denial %+v", denials[0])
+	}
+	if denials[1].User != "user3" || denials[1].Resource != "DELETE privileged" || denials[1].Reason != "default SCC deletion" {
+		t.Fatalf("Unexpected second denial %+v", denials[1])
+	}
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2021-06-01T02:00:00Z")
+	config.SetMaintenanceWindow(&config.MaintenanceWindow{
+		Start:    start,
+		End:      start.Add(2 * time.Hour),
+		Webhooks: []string{WebhookName},
+	})
+	defer config.SetMaintenanceWindow(nil)
+	oldClock := clock
+	defer func() { clock = oldClock }()
+
+	automationGroups := []string{"system:serviceaccounts", "system:serviceaccounts:openshift-backplane-managed-scripts"}
+	fakeClock := utils.NewFakeClock(start.Add(time.Hour))
+	clock = fakeClock
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "hostnetwork",
+			testID:          "automation-can-update-in-window",
+			username:        "system:serviceaccount:openshift-backplane-managed-scripts:script-runner",
+			operation:       admissionv1.Update,
+			userGroups:      automationGroups,
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostnetwork",
+			testID:          "user-cant-update-in-window",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	})
+
+	fakeClock.Set(start.Add(3 * time.Hour))
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "hostnetwork",
+			testID:          "automation-cant-update-after-window",
+			username:        "system:serviceaccount:openshift-backplane-managed-scripts:script-runner",
+			operation:       admissionv1.Update,
+			userGroups:      automationGroups,
+			shouldBeAllowed: false,
+		},
+	})
+}
+
+func TestConfigFileSettings(t *testing.T) {
+	oldMode, oldTimeout, oldDefaultSCCs := mode, timeout, defaultSCCs
+	oldAllowedUsers := map[admissionv1.Operation][]string{}
+	oldAllowedGroups := map[admissionv1.Operation][]string{}
+	for operation := range allowedUsers {
+		oldAllowedUsers[operation] = allowedUsers[operation]
+	}
+	for operation := range allowedGroups {
+		oldAllowedGroups[operation] = allowedGroups[operation]
+	}
+	defer func() {
+		mode, timeout, defaultSCCs = oldMode, oldTimeout, oldDefaultSCCs
+		allowedUsers, allowedGroups = oldAllowedUsers, oldAllowedGroups
+	}()
+
+	apply, err := applySettings(config.WebhookSettings{
+		TimeoutSeconds: 5,
+		Protected:      []string{"custom-scc"},
+		AllowedUsers:   []string{"user2"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	if hook := NewWebhook(); hook.TimeoutSeconds() != 5 {
+		t.Fatalf("Expected a timeout of %d, got %d", 5, hook.TimeoutSeconds())
+	}
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "custom-scc",
+			testID:          "user-cant-update-configured-scc",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "custom-scc",
+			testID:          "configured-user-can-delete-configured-scc",
+			username:        "user2",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "hostnetwork",
+			testID:          "user-can-update-unconfigured-scc",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "custom-scc",
+			testID:          "monitoring-operator-no-longer-allowed",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: false,
+		},
+	})
+
+	apply, err = applySettings(config.WebhookSettings{Mode: config.ModeDryRun})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "custom-scc",
+			testID:          "user-can-update-configured-scc-in-dry-run",
+			username:        "user1",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	denied := admissionctl.Denied("Modifying default SCC custom-scc is not allowed")
+	denied.UID = "1234"
+	setAuditAnnotations(&denied, "default SCC modification")
+
+	ret := dryRun(denied)
+	if !ret.Allowed || ret.UID != "1234" {
+		t.Fatalf("Expected request %s to be allowed, got %+v", "1234", ret)
+	}
+	if len(ret.Warnings) != 1 || !strings.Contains(ret.Warnings[0], "Modifying default SCC custom-scc is not allowed") {
+		t.Fatalf("Expected a warning about the denial, got %v", ret.Warnings)
+	}
+	if ret.AuditAnnotations[auditDecisionKey] != auditDecisionDeny || ret.AuditAnnotations[auditModeKey] != string(config.ModeDryRun) {
+		t.Fatalf("Expected the audit annotations to record a dry run denial, got %v", ret.AuditAnnotations)
+	}
+}
+
+func TestAllowOnceToken(t *testing.T) {
+	secret := []byte("cluster-secret")
+	config.SetAllowOnceSecret(secret)
+	defer config.SetAllowOnceSecret(nil)
+	now := time.Date(2021, 6, 1, 2, 0, 0, 0, time.UTC)
+	oldClock := clock
+	defer func() { clock = oldClock }()
+	clock = utils.NewFakeClock(now)
+
+	token, err := utils.SignAllowOnceToken(secret, utils.AllowOnceToken{
+		ID:        "scc-allow-once-test",
+		Webhook:   WebhookName,
+		Operation: string(admissionv1.Delete),
+		Name:      "privileged",
+		Expires:   now.Add(2 * time.Minute).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	acceptedBefore := promtestutil.ToFloat64(metrics.AllowOnceTokens.WithLabelValues(WebhookName, "accepted"))
+	rejectedBefore := promtestutil.ToFloat64(metrics.AllowOnceTokens.WithLabelValues(WebhookName, "rejected"))
+
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "allow-once",
+			Kind:      metav1.GroupVersionKind{Group: sccGroup, Version: "v1", Kind: sccKind},
+			Operation: admissionv1.Delete,
+			UserInfo: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:openshift-backplane-managed-scripts:script-runner",
+				Groups:   []string{"system:serviceaccounts"},
+				Extra: map[string]authenticationv1.ExtraValue{
+					utils.AllowOnceKey: {token},
+				},
+			},
+			OldObject: runtime.RawExtension{Raw: []byte(createRawJSONString("privileged"))},
+		},
+	}
+	hook := NewWebhook()
+	if response := hook.Authorized(request); !response.Allowed {
+		t.Fatalf("Expected the allow-once token to allow deleting the SCC, got %v", response.Result)
+	}
+	if response := hook.Authorized(request); !response.Allowed {
+		t.Fatalf("Expected the allow-once token to allow a retry of the same request, got %v", response.Result)
+	}
+	request.UID = "allow-once-again"
+	if response := hook.Authorized(request); response.Allowed {
+		t.Fatalf("Expected the allow-once token not to be usable for two requests")
+	}
+
+	if accepted := promtestutil.ToFloat64(metrics.AllowOnceTokens.WithLabelValues(WebhookName, "accepted")); accepted != acceptedBefore+2 {
+		t.Fatalf("Expected 2 accepted tokens, got %v", accepted-acceptedBefore)
+	}
+	if rejected := promtestutil.ToFloat64(metrics.AllowOnceTokens.WithLabelValues(WebhookName, "rejected")); rejected != rejectedBefore+1 {
+		t.Fatalf("Expected 1 rejected token, got %v", rejected-rejectedBefore)
+	}
+}
+
+func TestForbiddenRequesterGroups(t *testing.T) {
+	oldForbidden := forbiddenRequesterGroups
+	defer func() { forbiddenRequesterGroups = oldForbidden }()
+	apply, err := applySettings(config.WebhookSettings{Parameters: map[string]string{forbiddenRequesterGroupsParameter: "system:serviceaccounts, system:authenticated:oauth"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	// Being forbidden takes precedence over the maintenance window
+	start, _ := time.Parse(time.RFC3339, "2021-06-01T02:00:00Z")
+	config.SetMaintenanceWindow(&config.MaintenanceWindow{
+		Start:    start,
+		End:      start.Add(2 * time.Hour),
+		Webhooks: []string{WebhookName},
+	})
+	defer config.SetMaintenanceWindow(nil)
+	oldClock := clock
+	defer func() { clock = oldClock }()
+	clock = utils.NewFakeClock(start.Add(time.Hour))
+
+	runSCCTests(t, []sccTestSuites{
+		{
+			targetSCC:       "hostnetwork",
+			testID:          "forbidden-automation-cant-update-in-window",
+			username:        "system:serviceaccount:openshift-backplane-managed-scripts:script-runner",
+			operation:       admissionv1.Update,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-backplane-managed-scripts"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "privileged",
+			testID:          "forbidden-user-cant-delete-default-scc",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			targetSCC:       "privileged",
+			testID:          "privileged-requester-can-delete-default-scc",
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			targetSCC:       "my-scc",
+			testID:          "forbidden-user-can-delete-custom-scc",
+			username:        "user1",
+			operation:       admissionv1.Delete,
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	})
+
+	denials := RecentDenials()
+	if last := denials[len(denials)-1]; last.User != "user1" || last.Reason != "forbidden requester group" {
+		t.Fatalf("Expected the last denial to be of a forbidden requester, got %+v", last)
+	}
+
+	if _, err := applySettings(config.WebhookSettings{Parameters: map[string]string{forbiddenRequesterGroupsParameter: "system:authenticated,"}}); err == nil {
+		t.Fatalf("Expected an error for an empty group in %s", forbiddenRequesterGroupsParameter)
+	}
+}
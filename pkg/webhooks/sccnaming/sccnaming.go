@@ -0,0 +1,212 @@
+package sccnaming
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "scc-naming-validation"
+	docString   string = `Managed OpenShift Customers may not create SCCs whose name starts with any of the following reserved prefixes: %s, or which doesn't follow the SCC naming convention of the cluster`
+	sccKind     string = "SecurityContextConstraints"
+	sccGroup    string = "security.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{sccGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"securitycontextconstraints"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	// allowedGroups are the groups allowed to create SCCs with any name. An
+	// entry ending with "*" matches a family of groups, see utils.GroupMatches
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+		// Platform operators ship their own SCCs
+		"system:serviceaccounts:openshift-*",
+	}
+	// reservedPrefixes are the name prefixes of platform SCCs, which customer
+	// SCCs must not use
+	reservedPrefixes = []string{
+		"openshift-",
+		"managed-",
+		"kube-",
+		"system:",
+	}
+	// namingConventions are regular expressions, one of which the name of a
+	// customer SCC must match, eg a tenant prefix. No convention is enforced
+	// when empty.
+	namingConventions = []string{}
+)
+
+// SCCNamingWebhook enforces naming conventions on customer-created SCCs
+type SCCNamingWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *SCCNamingWebhook {
+	return &SCCNamingWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *SCCNamingWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *SCCNamingWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	scc, err := s.renderSCC(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a SCC from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if prefix := reservedPrefix(scc.Name); prefix != "" {
+		log.Info(fmt.Sprintf("Creation of SCC %s with reserved prefix %s detected", scc.Name, prefix))
+		ret = admissionctl.Denied(fmt.Sprintf("The SCC name %s starts with %s, which is reserved for the platform. Choose a name which doesn't start with any of %v", scc.Name, prefix, reservedPrefixes))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if len(namingConventions) > 0 && !utils.RegexSliceContains(scc.Name, namingConventions) {
+		log.Info(fmt.Sprintf("Creation of SCC %s not following the naming convention detected", scc.Name))
+		ret = admissionctl.Denied(fmt.Sprintf("The SCC name %s doesn't follow the naming convention of this cluster. Choose a name matching any of %v", scc.Name, namingConventions))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderSCC renders the SCC being created
+func (s *SCCNamingWebhook) renderSCC(request admissionctl.Request) (*securityv1.SecurityContextConstraints, error) {
+	newObj, _, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &securityv1.SecurityContextConstraints{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if newObj == nil {
+		return nil, fmt.Errorf("SCC %s request is missing an object", request.Operation)
+	}
+	return newObj.(*securityv1.SecurityContextConstraints), nil
+}
+
+// reservedPrefix returns the reserved prefix the name starts with, if any
+func reservedPrefix(name string) string {
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	if utils.GroupsMatch(allowedGroups, request.UserInfo.Groups) {
+		return true
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *SCCNamingWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *SCCNamingWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == sccKind)
+	valid = valid && (request.Kind.Group == sccGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *SCCNamingWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *SCCNamingWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *SCCNamingWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *SCCNamingWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *SCCNamingWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *SCCNamingWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *SCCNamingWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *SCCNamingWebhook) Doc() string {
+	return fmt.Sprintf(docString, reservedPrefixes)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *SCCNamingWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,146 @@
+package sccnaming
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type sccNamingTestSuites struct {
+	testID          string
+	targetSCC       string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "security.openshift.io/v1",
+	"kind": "SecurityContextConstraints",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	},
+	"allowPrivilegedContainer": false
+}`
+
+func runSCCNamingTests(t *testing.T, tests []sccNamingTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "security.openshift.io",
+		Version: "v1",
+		Kind:    "SecurityContextConstraints",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "security.openshift.io",
+		Version:  "v1",
+		Resource: "securitycontextconstraints",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetSCC)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s create the %s SCC. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetSCC, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []sccNamingTestSuites{
+		{
+			testID:          "user-cant-create-openshift-prefixed-scc",
+			targetSCC:       "openshift-privileged",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-create-managed-prefixed-scc",
+			targetSCC:       "managed-hostnetwork",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCNamingTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []sccNamingTestSuites{
+		{
+			testID:          "user-can-create-customer-scc",
+			targetSCC:       "my-app-scc",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "platform-operator-can-create-openshift-prefixed-scc",
+			targetSCC:       "openshift-logging-collector",
+			username:        "system:serviceaccount:openshift-logging:cluster-logging-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-logging"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-create-managed-prefixed-scc",
+			targetSCC:       "managed-hostnetwork",
+			username:        "srep-user",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runSCCNamingTests(t, tests)
+}
+
+func TestNamingConvention(t *testing.T) {
+	oldConventions := namingConventions
+	defer func() { namingConventions = oldConventions }()
+	namingConventions = []string{`^tenant-[a-z0-9]+-`}
+
+	tests := []sccNamingTestSuites{
+		{
+			testID:          "user-can-create-conventionally-named-scc",
+			targetSCC:       "tenant-acme-restricted",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-cant-create-unconventionally-named-scc",
+			targetSCC:       "my-app-scc",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "reserved-prefix-wins-over-convention",
+			targetSCC:       "openshift-tenant-acme-restricted",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSCCNamingTests(t, tests)
+}
@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"fmt"
+	"net/http"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName   string = "scheduler-validation"
+	docString     string = `Managed OpenShift Customers may not change the mastersSchedulable setting or the scheduling profile of the cluster Scheduler.`
+	schedulerKind string = "Scheduler"
+	configGroup   string = "config.openshift.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{configGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"schedulers"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-kube-scheduler-operator:openshift-kube-scheduler-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+)
+
+// SchedulerWebhook protects the scheduling settings of the cluster Scheduler
+type SchedulerWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *SchedulerWebhook {
+	return &SchedulerWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *SchedulerWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *SchedulerWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newScheduler, oldScheduler, err := s.renderOldAndNewSchedulers(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Scheduler from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if newScheduler.Spec.MastersSchedulable != oldScheduler.Spec.MastersSchedulable {
+		log.Info(fmt.Sprintf("Change of mastersSchedulable to %t detected on Scheduler %s", newScheduler.Spec.MastersSchedulable, newScheduler.Name))
+		ret = admissionctl.Denied("Changing the mastersSchedulable setting of the cluster Scheduler is not allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if newScheduler.Spec.Profile != oldScheduler.Spec.Profile {
+		log.Info(fmt.Sprintf("Change of scheduling profile from %q to %q detected on Scheduler %s", oldScheduler.Spec.Profile, newScheduler.Spec.Profile, newScheduler.Name))
+		ret = admissionctl.Denied("Changing the scheduling profile of the cluster Scheduler is not allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewSchedulers decodes both the Object and OldObject of the
+// request. Return order is: new, old, error.
+func (s *SchedulerWebhook) renderOldAndNewSchedulers(request admissionctl.Request) (*configv1.Scheduler, *configv1.Scheduler, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &configv1.Scheduler{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("Scheduler UPDATE request is missing an object")
+	}
+
+	return newObj.(*configv1.Scheduler), oldObj.(*configv1.Scheduler), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *SchedulerWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *SchedulerWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == schedulerKind)
+	valid = valid && (request.Kind.Group == configGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *SchedulerWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *SchedulerWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *SchedulerWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *SchedulerWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *SchedulerWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *SchedulerWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *SchedulerWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *SchedulerWebhook) Doc() string {
+	return docString
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *SchedulerWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
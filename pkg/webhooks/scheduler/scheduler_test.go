@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type schedulerTestSuites struct {
+	testID            string
+	oldSchedulable    bool
+	newSchedulable    bool
+	oldProfile        string
+	newProfile        string
+	oldDefaultNodeSel string
+	newDefaultNodeSel string
+	username          string
+	userGroups        []string
+	shouldBeAllowed   bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "config.openshift.io/v1",
+	"kind": "Scheduler",
+	"metadata": {
+		"name": "cluster",
+		"uid": "1234"
+	},
+	"spec": {
+		"mastersSchedulable": %t,
+		"profile": "%s",
+		"defaultNodeSelector": "%s"
+	}
+}`
+
+func runSchedulerTests(t *testing.T, tests []schedulerTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "config.openshift.io",
+		Version: "v1",
+		Kind:    "Scheduler",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "schedulers",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.newSchedulable, test.newProfile, test.newDefaultNodeSel)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.oldSchedulable, test.oldProfile, test.oldDefaultNodeSel)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s update the Scheduler. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []schedulerTestSuites{
+		{
+			testID:          "user-cant-enable-masters-schedulable",
+			oldSchedulable:  false,
+			newSchedulable:  true,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-change-profile",
+			oldProfile:      "LowNodeUtilization",
+			newProfile:      "HighNodeUtilization",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSchedulerTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []schedulerTestSuites{
+		{
+			testID:            "user-can-make-benign-change",
+			oldProfile:        "LowNodeUtilization",
+			newProfile:        "LowNodeUtilization",
+			oldDefaultNodeSel: "",
+			newDefaultNodeSel: "type=user-node",
+			username:          "user1",
+			userGroups:        []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed:   true,
+		},
+		{
+			testID:          "backplane-can-enable-masters-schedulable",
+			oldSchedulable:  false,
+			newSchedulable:  true,
+			username:        "backplane-cluster-admin",
+			userGroups:      []string{"system:authenticated"},
+			shouldBeAllowed: true,
+		},
+	}
+	runSchedulerTests(t, tests)
+}
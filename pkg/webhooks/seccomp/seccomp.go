@@ -0,0 +1,267 @@
+package seccomp
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "seccomp-validation"
+	docString   string = `Managed OpenShift Customers may not create Pods in namespaces matching any of %s unless every container runs with a seccompProfile other than Unconfined.`
+	podKind     string = "Pod"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"*"},
+				Resources:   []string{"pods"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// enforcedNamespaces are regular expressions matching the namespaces in
+	// which Pods must run with a seccompProfile. None is enforced unless the
+	// configuration file says so, as the hardening is opt-in. Platform
+	// namespaces are never enforced, see hookconfig.IsPrivilegedNamespace.
+	enforcedNamespaces = []string{}
+)
+
+func init() {
+	hookconfig.RegisterPolicySource(WebhookName, effectivePolicy)
+	hookconfig.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &enforcedNamespaces)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it. The protected list sets the
+// enforcedNamespaces.
+func applySettings(settings hookconfig.WebhookSettings) (func(), error) {
+	if settings.Mode == hookconfig.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	for _, namespace := range settings.Protected {
+		if _, err := regexp.Compile(namespace); err != nil {
+			return nil, fmt.Errorf("protected namespace %q is not a valid regular expression: %v", namespace, err)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			enforcedNamespaces = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for hookconfig.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"enforcedNamespaces": enforcedNamespaces,
+		"allowedUsers":       allowedUsers,
+		"allowedGroups":      allowedGroups,
+	}
+}
+
+// SeccompWebhook requires Pods in hardened namespaces to set a seccompProfile
+type SeccompWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *SeccompWebhook {
+	return &SeccompWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *SeccompWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *SeccompWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	pod, err := s.renderPod(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Pod from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if !isEnforced(pod.Namespace) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	if container, profile := unconfinedContainer(pod); container != "" {
+		log.Info(fmt.Sprintf("Pod in %s with container %s lacking a seccompProfile detected", pod.Namespace, container))
+		ret = admissionctl.Denied(fmt.Sprintf("Container %s must run with a seccompProfile such as RuntimeDefault, set on the Pod or the container securityContext, got %s", container, profile))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderPod renders the Pod being created
+func (s *SeccompWebhook) renderPod(request admissionctl.Request) (*corev1.Pod, error) {
+	newObj, _, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.Pod{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if newObj == nil {
+		return nil, fmt.Errorf("Pod %s request is missing an object", request.Operation)
+	}
+	return newObj.(*corev1.Pod), nil
+}
+
+// isEnforced checks if Pods in the namespace must set a seccompProfile
+func isEnforced(namespace string) bool {
+	return !hookconfig.IsPrivilegedNamespace(namespace) && utils.RegexSliceContains(namespace, enforcedNamespaces)
+}
+
+// unconfinedContainer returns the first container, init containers
+// included, whose effective seccompProfile is missing or Unconfined, along
+// with that profile. A container inherits the profile of the Pod unless it
+// sets its own.
+func unconfinedContainer(pod *corev1.Pod) (string, string) {
+	var podProfile *corev1.SeccompProfile
+	if pod.Spec.SecurityContext != nil {
+		podProfile = pod.Spec.SecurityContext.SeccompProfile
+	}
+
+	containers := append([]corev1.Container{}, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		profile := podProfile
+		if container.SecurityContext != nil && container.SecurityContext.SeccompProfile != nil {
+			profile = container.SecurityContext.SeccompProfile
+		}
+		if profile == nil {
+			return container.Name, "none"
+		}
+		if profile.Type == corev1.SeccompProfileTypeUnconfined {
+			return container.Name, string(profile.Type)
+		}
+	}
+	return "", ""
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *SeccompWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *SeccompWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == podKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *SeccompWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *SeccompWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *SeccompWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *SeccompWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *SeccompWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *SeccompWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *SeccompWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *SeccompWebhook) Doc() string {
+	return fmt.Sprintf(docString, enforcedNamespaces)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *SeccompWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
@@ -0,0 +1,203 @@
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	hookconfig "github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type seccompTestSuites struct {
+	testID          string
+	namespace       string
+	podProfile      *corev1.SeccompProfile
+	appProfile      *corev1.SeccompProfile
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {
+		"name": "app",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": %s
+}`
+
+var (
+	runtimeDefault = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	unconfined     = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+)
+
+func createRawPodJSON(namespace string, podProfile, appProfile *corev1.SeccompProfile) (string, error) {
+	spec := corev1.PodSpec{
+		SecurityContext: &corev1.PodSecurityContext{SeccompProfile: podProfile},
+		InitContainers:  []corev1.Container{{Name: "init", Image: "init"}},
+		Containers: []corev1.Container{
+			{Name: "app", Image: "app", SecurityContext: &corev1.SecurityContext{SeccompProfile: appProfile}},
+			{Name: "sidecar", Image: "sidecar"},
+		},
+	}
+	partial, err := json.Marshal(spec)
+	return fmt.Sprintf(testObjectRaw, namespace, string(partial)), err
+}
+
+func runSeccompTests(t *testing.T, tests []seccompTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Pod",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "pods",
+	}
+
+	oldEnforced := enforcedNamespaces
+	defer func() { enforcedNamespaces = oldEnforced }()
+	enforcedNamespaces = []string{`^hardened-.*`, `^openshift-.*`}
+
+	for _, test := range tests {
+		rawObjString, err := createRawPodJSON(test.namespace, test.podProfile, test.appProfile)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(rawObjString),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s create the Pod in %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.namespace, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []seccompTestSuites{
+		{
+			testID:          "unconfined-container-denied",
+			namespace:       "hardened-app",
+			podProfile:      runtimeDefault,
+			appProfile:      unconfined,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "unconfined-pod-denied",
+			namespace:       "hardened-app",
+			podProfile:      unconfined,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "missing-profile-denied",
+			namespace:       "hardened-app",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "container-only-profile-denied-for-other-containers",
+			namespace:       "hardened-app",
+			appProfile:      runtimeDefault,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSeccompTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []seccompTestSuites{
+		{
+			testID:          "runtime-default-allowed",
+			namespace:       "hardened-app",
+			podProfile:      runtimeDefault,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "localhost-profile-allowed",
+			namespace:       "hardened-app",
+			podProfile:      &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost},
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "unenforced-namespace-allowed",
+			namespace:       "customer-ns",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "platform-namespace-allowed",
+			namespace:       "openshift-monitoring",
+			podProfile:      unconfined,
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-allowed",
+			namespace:       "hardened-app",
+			username:        "srep-user",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runSeccompTests(t, tests)
+}
+
+func TestConfiguredNamespaces(t *testing.T) {
+	oldEnforced := enforcedNamespaces
+	defer func() { enforcedNamespaces = oldEnforced }()
+
+	if isEnforced("payments") {
+		t.Fatalf("Expected no namespace to be enforced without configuration")
+	}
+	apply, err := applySettings(hookconfig.WebhookSettings{Protected: []string{"^payments$"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+	if !isEnforced("payments") {
+		t.Fatalf("Expected the configured namespace payments to be enforced")
+	}
+
+	if _, err := applySettings(hookconfig.WebhookSettings{Protected: []string{"^payments($"}}); err == nil {
+		t.Fatalf("Expected an invalid regular expression to be rejected")
+	}
+}
@@ -0,0 +1,244 @@
+package secret
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "secret-validation"
+	docString   string = `Managed OpenShift Customers may not delete the following managed Secrets, which platform workloads depend on: %s`
+	secretKind  string = "Secret"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"*"},
+				Resources:   []string{"secrets"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:kube-system:namespace-controller",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-ingress-operator:ingress-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedSecrets is the inventory of managed Secrets, in the form of
+	// namespace/name
+	managedSecrets = []string{
+		"openshift-config/pull-secret",
+		"openshift-ingress/router-certs-default",
+		"openshift-monitoring/alertmanager-main",
+	}
+	// exemptSelector selects the managed Secrets which platform automation
+	// opted out of the protection, eg managed.openshift.io/ignore=true. It
+	// must only select labels customers can't set. Empty exempts nothing.
+	exemptSelector = metav1.LabelSelector{}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedSecrets)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedSecrets = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedSecrets": managedSecrets,
+		"exemptSelector": exemptSelector,
+		"allowedUsers":   allowedUsers,
+		"allowedGroups":  allowedGroups,
+	}
+}
+
+// SecretWebhook protects the Secrets platform workloads mount from deletion
+type SecretWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *SecretWebhook {
+	return &SecretWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *SecretWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *SecretWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	secret, err := s.renderSecret(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Secret from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	exempt, err := utils.HasExemptLabel(secret, exemptSelector)
+	if err != nil {
+		log.Error(err, "Couldn't evaluate the exempt label selector")
+		return admissionctl.Errored(http.StatusInternalServerError, err)
+	}
+
+	if request.Operation == admissionv1.Delete && isManagedSecret(secret) && !exempt && !isAllowedUserGroup(request) {
+		name := secret.Namespace + "/" + secret.Name
+		log.Info(fmt.Sprintf("Deleting operation detected on managed Secret: %v", name))
+		ret = admissionctl.Denied(fmt.Sprintf("Deleting managed Secret %v is not allowed", name))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderSecret decodes the OldObject of the request, which is the Secret being
+// deleted
+func (s *SecretWebhook) renderSecret(request admissionctl.Request) (*corev1.Secret, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{}
+
+	err = decoder.DecodeRaw(request.OldObject, secret)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// isManagedSecret checks if the Secret is in the managed inventory
+func isManagedSecret(secret *corev1.Secret) bool {
+	return utils.SliceContains(secret.Namespace+"/"+secret.Name, managedSecrets)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *SecretWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *SecretWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == secretKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *SecretWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *SecretWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *SecretWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *SecretWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *SecretWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *SecretWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *SecretWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *SecretWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedSecrets)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *SecretWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
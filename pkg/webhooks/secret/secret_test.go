@@ -0,0 +1,207 @@
+package secret
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type secretTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	labels          string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "Secret",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234",
+		"labels": {%s}
+	},
+	"type": "Opaque",
+	"data": {
+		"key": "dmFsdWU="
+	}
+}`
+
+func runSecretTests(t *testing.T, tests []secretTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "Secret",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "secrets",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.labels)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Delete, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s delete the %s/%s Secret. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []secretTestSuites{
+		{
+			testID:          "user-cant-delete-pull-secret",
+			targetNamespace: "openshift-config",
+			targetName:      "pull-secret",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-router-certs",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-certs-default",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSecretTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []secretTestSuites{
+		{
+			testID:          "user-can-delete-customer-secret",
+			targetNamespace: "customer-ns",
+			targetName:      "my-secret",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-unmanaged-secret-in-platform-namespace",
+			targetNamespace: "openshift-config",
+			targetName:      "my-secret",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "ingress-operator-can-delete-router-certs",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-certs-default",
+			username:        "system:serviceaccount:openshift-ingress-operator:ingress-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-ingress-operator"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "srep-can-delete-pull-secret",
+			targetNamespace: "openshift-config",
+			targetName:      "pull-secret",
+			username:        "srep-user",
+			userGroups:      []string{"system:serviceaccounts:openshift-backplane-srep"},
+			shouldBeAllowed: true,
+		},
+	}
+	runSecretTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedSecrets
+	defer func() { managedSecrets = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"openshift-logging/fluentd"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []secretTestSuites{
+		{
+			testID:          "user-cant-delete-configured-secret",
+			targetNamespace: "openshift-logging",
+			targetName:      "fluentd",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-secret",
+			targetNamespace: "openshift-config",
+			targetName:      "pull-secret",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runSecretTests(t, tests)
+}
+
+func TestExemptLabel(t *testing.T) {
+	oldSelector := exemptSelector
+	defer func() { exemptSelector = oldSelector }()
+
+	tests := []secretTestSuites{
+		{
+			testID:          "user-cant-delete-labelled-secret-without-selector",
+			targetNamespace: "openshift-config",
+			targetName:      "pull-secret",
+			labels:          `"managed.openshift.io/ignore": "true"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSecretTests(t, tests)
+
+	exemptSelector = metav1.LabelSelector{MatchLabels: map[string]string{"managed.openshift.io/ignore": "true"}}
+	tests = []secretTestSuites{
+		{
+			testID:          "user-can-delete-exempt-secret",
+			targetNamespace: "openshift-config",
+			targetName:      "pull-secret",
+			labels:          `"managed.openshift.io/ignore": "true"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-cant-delete-secret-with-other-label-value",
+			targetNamespace: "openshift-config",
+			targetName:      "pull-secret",
+			labels:          `"managed.openshift.io/ignore": "false"`,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSecretTests(t, tests)
+}
@@ -0,0 +1,172 @@
+package servicemonitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type serviceMonitorTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "monitoring.coreos.com/v1",
+	"kind": "ServiceMonitor",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"endpoints": [
+			{
+				"port": "metrics"
+			}
+		],
+		"selector": {
+			"matchLabels": {
+				"app": "%s"
+			}
+		}
+	}
+}`
+
+func runServiceMonitorTests(t *testing.T, tests []serviceMonitorTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "ServiceMonitor",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "servicemonitors",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.targetName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the ServiceMonitor %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []serviceMonitorTestSuites{
+		{
+			testID:          "user-cant-delete-managed-servicemonitor",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "node-exporter",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-modify-managed-servicemonitor",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "kube-state-metrics",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runServiceMonitorTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []serviceMonitorTestSuites{
+		{
+			testID:          "user-can-delete-customer-servicemonitor",
+			targetNamespace: "my-project",
+			targetName:      "my-app",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-same-name-in-customer-namespace",
+			targetNamespace: "my-project",
+			targetName:      "node-exporter",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "monitoring-operator-can-modify-managed-servicemonitor",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "node-exporter",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+	}
+	runServiceMonitorTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedServiceMonitors
+	defer func() { managedServiceMonitors = oldInventory }()
+	apply, err := applySettings(config.WebhookSettings{Protected: []string{"openshift-ingress/router-default"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []serviceMonitorTestSuites{
+		{
+			testID:          "user-cant-delete-configured-servicemonitor",
+			targetNamespace: "openshift-ingress",
+			targetName:      "router-default",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-servicemonitor",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "node-exporter",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runServiceMonitorTests(t, tests)
+}
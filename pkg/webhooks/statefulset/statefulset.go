@@ -0,0 +1,281 @@
+package statefulset
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName     string = "statefulset-validation"
+	docString       string = `Managed OpenShift Customers may not remove the pod anti-affinity or topology spread constraints of the following managed StatefulSets: %s`
+	statefulSetKind string = "StatefulSet"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"statefulsets"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+		"system:serviceaccount:openshift-monitoring:prometheus-operator",
+		"system:serviceaccount:openshift-user-workload-monitoring:prometheus-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedStatefulSets is the inventory of managed StatefulSets, in the
+	// form of namespace/name
+	managedStatefulSets = []string{
+		"openshift-monitoring/alertmanager-main",
+		"openshift-monitoring/prometheus-k8s",
+		"openshift-user-workload-monitoring/prometheus-user-workload",
+		"openshift-user-workload-monitoring/thanos-ruler-user-workload",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedStatefulSets)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedStatefulSets = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedStatefulSets": managedStatefulSets,
+		"allowedUsers":        allowedUsers,
+		"allowedGroups":       allowedGroups,
+	}
+}
+
+// StatefulSetWebhook keeps managed StatefulSets highly available, by
+// protecting the constraints spreading their pods
+type StatefulSetWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *StatefulSetWebhook {
+	return &StatefulSetWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *StatefulSetWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *StatefulSetWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newStatefulSet, oldStatefulSet, err := s.renderOldAndNewStatefulSets(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a StatefulSet from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	name := oldStatefulSet.Namespace + "/" + oldStatefulSet.Name
+	if utils.SliceContains(name, managedStatefulSets) {
+		oldSpec, newSpec := &oldStatefulSet.Spec.Template.Spec, &newStatefulSet.Spec.Template.Spec
+		if removed := removedKeys(antiAffinityKeys(oldSpec), antiAffinityKeys(newSpec)); len(removed) > 0 {
+			log.Info(fmt.Sprintf("Removal of pod anti-affinity %v detected on managed StatefulSet: %s", removed, name))
+			ret = admissionctl.Denied(fmt.Sprintf("Removing the pod anti-affinity %v of managed StatefulSet %s is not allowed", removed, name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+		if removed := removedKeys(topologySpreadKeys(oldSpec), topologySpreadKeys(newSpec)); len(removed) > 0 {
+			log.Info(fmt.Sprintf("Removal of topology spread constraints %v detected on managed StatefulSet: %s", removed, name))
+			ret = admissionctl.Denied(fmt.Sprintf("Removing the topology spread constraints %v of managed StatefulSet %s is not allowed", removed, name))
+			ret.UID = request.AdmissionRequest.UID
+			return ret
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// antiAffinityKeys returns the topology keys of the pod anti-affinity terms,
+// prefixed with whether they are required or preferred, so that weakening a
+// required term to a preferred one counts as a removal
+func antiAffinityKeys(spec *corev1.PodSpec) []string {
+	if spec.Affinity == nil || spec.Affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	keys := []string{}
+	for _, term := range spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		keys = append(keys, "required:"+term.TopologyKey)
+	}
+	for _, term := range spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		keys = append(keys, "preferred:"+term.PodAffinityTerm.TopologyKey)
+	}
+	return keys
+}
+
+// topologySpreadKeys returns the topology keys of the topology spread
+// constraints, prefixed with what happens when they are unsatisfiable
+func topologySpreadKeys(spec *corev1.PodSpec) []string {
+	keys := []string{}
+	for _, constraint := range spec.TopologySpreadConstraints {
+		keys = append(keys, string(constraint.WhenUnsatisfiable)+":"+constraint.TopologyKey)
+	}
+	return keys
+}
+
+// removedKeys returns the sorted keys of before which are missing from after
+func removedKeys(before, after []string) []string {
+	removed := []string{}
+	for _, key := range before {
+		if !utils.SliceContains(key, after) && !utils.SliceContains(key, removed) {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// renderOldAndNewStatefulSets decodes both the Object and OldObject of the
+// UPDATE request
+func (s *StatefulSetWebhook) renderOldAndNewStatefulSets(request admissionctl.Request) (*appsv1.StatefulSet, *appsv1.StatefulSet, error) {
+	newObj, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &appsv1.StatefulSet{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	if newObj == nil || oldObj == nil {
+		return nil, nil, fmt.Errorf("StatefulSet UPDATE request is missing an object")
+	}
+	return newObj.(*appsv1.StatefulSet), oldObj.(*appsv1.StatefulSet), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *StatefulSetWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *StatefulSetWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == statefulSetKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *StatefulSetWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *StatefulSetWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *StatefulSetWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *StatefulSetWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *StatefulSetWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *StatefulSetWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *StatefulSetWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *StatefulSetWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedStatefulSets)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *StatefulSetWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
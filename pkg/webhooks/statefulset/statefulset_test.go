@@ -0,0 +1,240 @@
+package statefulset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type statefulSetTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldSpreading    string
+	newSpreading    string
+	newImage        string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "apps/v1",
+	"kind": "StatefulSet",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"template": {
+			"spec": {
+				%s
+				"containers": [
+					{
+						"name": "prometheus",
+						"image": "%s"
+					}
+				]
+			}
+		}
+	}
+}`
+
+const (
+	antiAffinityRaw string = `"affinity": {
+					"podAntiAffinity": {
+						"requiredDuringSchedulingIgnoredDuringExecution": [
+							{
+								"labelSelector": {"matchLabels": {"app": "prometheus"}},
+								"topologyKey": "kubernetes.io/hostname"
+							}
+						]
+					}
+				},`
+	preferredAntiAffinityRaw string = `"affinity": {
+					"podAntiAffinity": {
+						"preferredDuringSchedulingIgnoredDuringExecution": [
+							{
+								"weight": 100,
+								"podAffinityTerm": {
+									"labelSelector": {"matchLabels": {"app": "prometheus"}},
+									"topologyKey": "kubernetes.io/hostname"
+								}
+							}
+						]
+					}
+				},`
+	topologySpreadRaw string = `"topologySpreadConstraints": [
+					{
+						"maxSkew": 1,
+						"topologyKey": "topology.kubernetes.io/zone",
+						"whenUnsatisfiable": "DoNotSchedule",
+						"labelSelector": {"matchLabels": {"app": "prometheus"}}
+					}
+				],`
+	oldImage string = "quay.io/openshift/prometheus:v2.26.0"
+)
+
+func runStatefulSetTests(t *testing.T, tests []statefulSetTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "apps",
+		Version: "v1",
+		Kind:    "StatefulSet",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "statefulsets",
+	}
+
+	for _, test := range tests {
+		newImage := test.newImage
+		if newImage == "" {
+			newImage = oldImage
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.newSpreading, newImage)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.oldSpreading, oldImage)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s update the StatefulSet %s/%s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []statefulSetTestSuites{
+		{
+			testID:          "user-cant-remove-anti-affinity",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    antiAffinityRaw + topologySpreadRaw,
+			newSpreading:    topologySpreadRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-weaken-anti-affinity",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    antiAffinityRaw,
+			newSpreading:    preferredAntiAffinityRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-remove-topology-spread",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "alertmanager-main",
+			oldSpreading:    antiAffinityRaw + topologySpreadRaw,
+			newSpreading:    antiAffinityRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runStatefulSetTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []statefulSetTestSuites{
+		{
+			testID:          "user-can-update-image",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    antiAffinityRaw + topologySpreadRaw,
+			newSpreading:    antiAffinityRaw + topologySpreadRaw,
+			newImage:        "quay.io/openshift/prometheus:v2.26.1",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-add-anti-affinity",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    topologySpreadRaw,
+			newSpreading:    antiAffinityRaw + topologySpreadRaw,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-remove-anti-affinity-of-customer-statefulset",
+			targetNamespace: "my-project",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    antiAffinityRaw,
+			newSpreading:    "",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "prometheus-operator-can-remove-anti-affinity",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    antiAffinityRaw,
+			newSpreading:    "",
+			username:        "system:serviceaccount:openshift-monitoring:prometheus-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+	}
+	runStatefulSetTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedStatefulSets
+	defer func() { managedStatefulSets = oldInventory }()
+	managedStatefulSets = []string{"openshift-logging/elasticsearch-cdm"}
+
+	tests := []statefulSetTestSuites{
+		{
+			testID:          "user-cant-remove-anti-affinity-of-configured-statefulset",
+			targetNamespace: "openshift-logging",
+			targetName:      "elasticsearch-cdm",
+			oldSpreading:    antiAffinityRaw,
+			newSpreading:    "",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-remove-anti-affinity-of-unconfigured-statefulset",
+			targetNamespace: "openshift-monitoring",
+			targetName:      "prometheus-k8s",
+			oldSpreading:    antiAffinityRaw,
+			newSpreading:    "",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runStatefulSetTests(t, tests)
+}
@@ -0,0 +1,337 @@
+package storageclass
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName string = "storageclass-validation"
+	docString   string = `Managed OpenShift Customers may only create PersistentVolumeClaims using the following StorageClasses, unless configured otherwise for the namespace: %s`
+	pvcKind     string = "PersistentVolumeClaim"
+	// betaStorageClassAnnotation is the deprecated way of selecting a
+	// StorageClass, which still takes precedence over spec.storageClassName
+	betaStorageClassAnnotation string = "volume.beta.kubernetes.io/storage-class"
+	// exemptNamespacesParameter is the parameter of the configuration file
+	// setting the comma-separated exemptNamespaces
+	exemptNamespacesParameter string = "exemptNamespaces"
+	// namespaceStorageClassesPrefix prefixes the parameters of the
+	// configuration file setting the comma-separated namespaceStorageClasses
+	// of a namespace, eg storageClasses.database-ns
+	namespaceStorageClassesPrefix string = "storageClasses."
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"CREATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"persistentvolumeclaims"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// allowedStorageClasses are the StorageClasses PersistentVolumeClaims may
+	// use, in namespaces without an override
+	allowedStorageClasses = []string{
+		"gp2",
+		"gp2-csi",
+		"gp3-csi",
+	}
+	// namespaceStorageClasses overrides allowedStorageClasses for the given
+	// namespaces
+	namespaceStorageClasses = map[string][]string{}
+	// exemptNamespaces are regular expressions matching the namespaces where
+	// any StorageClass may be used, on top of the privileged namespaces
+	exemptNamespaces = []string{}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &allowedStorageClasses, &namespaceStorageClasses, &exemptNamespaces)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it. The protected list sets the
+// allowedStorageClasses.
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	var exempt []string
+	var overrides map[string][]string
+	for name, value := range settings.Parameters {
+		entries, err := splitParameter(name, value)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case name == exemptNamespacesParameter:
+			for _, namespace := range entries {
+				if _, err := regexp.Compile(namespace); err != nil {
+					return nil, fmt.Errorf("%s %q is not a valid regular expression: %v", exemptNamespacesParameter, namespace, err)
+				}
+			}
+			exempt = entries
+		case strings.HasPrefix(name, namespaceStorageClassesPrefix) && name != namespaceStorageClassesPrefix:
+			if overrides == nil {
+				overrides = map[string][]string{}
+			}
+			overrides[strings.TrimPrefix(name, namespaceStorageClassesPrefix)] = entries
+		default:
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			allowedStorageClasses = settings.Protected
+		}
+		if overrides != nil {
+			namespaceStorageClasses = overrides
+		}
+		if exempt != nil {
+			exemptNamespaces = exempt
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// splitParameter splits the comma-separated value of a parameter, rejecting
+// empty entries
+func splitParameter(name, value string) ([]string, error) {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			return nil, fmt.Errorf("%s %q has an empty entry", name, value)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"allowedStorageClasses":   allowedStorageClasses,
+		"namespaceStorageClasses": namespaceStorageClasses,
+		"exemptNamespaces":        exemptNamespaces,
+		"allowedUsers":            allowedUsers,
+		"allowedGroups":           allowedGroups,
+	}
+}
+
+// StorageClassWebhook restricts the StorageClasses customer
+// PersistentVolumeClaims may use
+type StorageClassWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *StorageClassWebhook {
+	return &StorageClassWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *StorageClassWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *StorageClassWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Create || isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	pvc, err := s.renderPVC(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a PersistentVolumeClaim from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isExemptNamespace(pvc.Namespace) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	storageClass, set := storageClassName(pvc)
+	// An unset StorageClass resolves to the cluster default one, and an empty
+	// one disables dynamic provisioning altogether, so neither is restricted
+	if !set || storageClass == "" {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	allowed := allowedStorageClassesFor(pvc.Namespace)
+	if !utils.SliceContains(storageClass, allowed) {
+		log.Info(fmt.Sprintf("PersistentVolumeClaim %s/%s requests disallowed StorageClass %s", pvc.Namespace, pvc.Name, storageClass))
+		ret = admissionctl.Denied(fmt.Sprintf("StorageClass %s is not allowed in namespace %s, use one of %v", storageClass, pvc.Namespace, allowed))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderPVC renders the PersistentVolumeClaim from the request
+func (s *StorageClassWebhook) renderPVC(request admissionctl.Request) (*corev1.PersistentVolumeClaim, error) {
+	newObj, _, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &corev1.PersistentVolumeClaim{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if newObj == nil {
+		return nil, fmt.Errorf("PersistentVolumeClaim CREATE request is missing an object")
+	}
+	pvc := newObj.(*corev1.PersistentVolumeClaim)
+	// The namespace is not always set in the object of a CREATE
+	if pvc.Namespace == "" {
+		pvc.Namespace = request.Namespace
+	}
+	return pvc, nil
+}
+
+// storageClassName returns the StorageClass the PersistentVolumeClaim
+// requests, and whether it requests one at all
+func storageClassName(pvc *corev1.PersistentVolumeClaim) (string, bool) {
+	if name, ok := pvc.Annotations[betaStorageClassAnnotation]; ok {
+		return name, true
+	}
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName, true
+	}
+	return "", false
+}
+
+// allowedStorageClassesFor returns the StorageClasses allowed in the namespace
+func allowedStorageClassesFor(namespace string) []string {
+	if override, ok := namespaceStorageClasses[namespace]; ok {
+		return override
+	}
+	return allowedStorageClasses
+}
+
+// isExemptNamespace checks if any StorageClass may be used in the namespace
+func isExemptNamespace(namespace string) bool {
+	return config.IsPrivilegedNamespace(namespace) || utils.RegexSliceContains(namespace, exemptNamespaces)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *StorageClassWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *StorageClassWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == pvcKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *StorageClassWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *StorageClassWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *StorageClassWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *StorageClassWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *StorageClassWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *StorageClassWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *StorageClassWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *StorageClassWebhook) Doc() string {
+	return fmt.Sprintf(docString, allowedStorageClasses)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *StorageClassWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
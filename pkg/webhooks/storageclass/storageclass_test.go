@@ -0,0 +1,195 @@
+package storageclass
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type storageClassTestSuites struct {
+	testID          string
+	namespace       string
+	storageClass    *string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "v1",
+	"kind": "PersistentVolumeClaim",
+	"metadata": {
+		"name": "data",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		%s
+		"accessModes": ["ReadWriteOnce"],
+		"resources": {
+			"requests": {
+				"storage": "1Gi"
+			}
+		}
+	}
+}`
+
+func storageClass(name string) *string {
+	return &name
+}
+
+func runStorageClassTests(t *testing.T, tests []storageClassTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    "PersistentVolumeClaim",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "persistentvolumeclaims",
+	}
+
+	for _, test := range tests {
+		storageClassField := ""
+		if test.storageClass != nil {
+			storageClassField = fmt.Sprintf(`"storageClassName": "%s",`, *test.storageClass)
+		}
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.namespace, storageClassField)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Create, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch in %s: %s (groups=%s) %s create the PersistentVolumeClaim in %s. Test's expectation is that the user %s", test.testID, test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.namespace, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []storageClassTestSuites{
+		{
+			testID:          "user-cant-use-disallowed-class",
+			namespace:       "customer-ns",
+			storageClass:    storageClass("io1-premium"),
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runStorageClassTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []storageClassTestSuites{
+		{
+			testID:          "user-can-use-allowed-class",
+			namespace:       "customer-ns",
+			storageClass:    storageClass("gp3-csi"),
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-use-default-class",
+			namespace:       "customer-ns",
+			storageClass:    nil,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-opt-out-of-dynamic-provisioning",
+			namespace:       "customer-ns",
+			storageClass:    storageClass(""),
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "any-class-in-privileged-namespace",
+			namespace:       "openshift-monitoring",
+			storageClass:    storageClass("io1-premium"),
+			username:        "system:serviceaccount:openshift-monitoring:prometheus-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring"},
+			shouldBeAllowed: true,
+		},
+	}
+	runStorageClassTests(t, tests)
+}
+
+func TestNamespaceOverridesAndExemptions(t *testing.T) {
+	oldOverrides, oldExempt := namespaceStorageClasses, exemptNamespaces
+	defer func() { namespaceStorageClasses, exemptNamespaces = oldOverrides, oldExempt }()
+	apply, err := applySettings(config.WebhookSettings{
+		Parameters: map[string]string{
+			namespaceStorageClassesPrefix + "database-ns": "io1-premium",
+			exemptNamespacesParameter:                     `^lab-.*`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	apply()
+
+	tests := []storageClassTestSuites{
+		{
+			testID:          "override-allows-class",
+			namespace:       "database-ns",
+			storageClass:    storageClass("io1-premium"),
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "override-replaces-default-allowlist",
+			namespace:       "database-ns",
+			storageClass:    storageClass("gp3-csi"),
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "exempt-namespace-allows-any-class",
+			namespace:       "lab-1",
+			storageClass:    storageClass("io1-premium"),
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runStorageClassTests(t, tests)
+}
+
+func TestConfigFileSettingsRejected(t *testing.T) {
+	for _, parameters := range []map[string]string{
+		{exemptNamespacesParameter: `^lab-(`},
+		{exemptNamespacesParameter: "lab-1,"},
+		{namespaceStorageClassesPrefix: "io1-premium"},
+		{"allowedStorageClasses": "io1-premium"},
+	} {
+		if _, err := applySettings(config.WebhookSettings{Parameters: parameters}); err == nil {
+			t.Fatalf("Expected parameters %v to be rejected", parameters)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package subscription
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName       string = "subscription-validation"
+	docString         string = `Managed OpenShift Customers may not change the channel or install plan approval of the following managed Subscriptions: %s`
+	subscriptionKind  string = "Subscription"
+	subscriptionGroup string = "operators.coreos.com"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.NamespacedScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{subscriptionGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"subscriptions"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-operator-lifecycle-manager:olm-operator-serviceaccount",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedSubscriptions is the inventory of managed Subscriptions, in the
+	// form of namespace/name
+	managedSubscriptions = []string{
+		"openshift-managed-upgrade-operator/managed-upgrade-operator",
+		"openshift-must-gather-operator/must-gather-operator",
+		"openshift-rbac-permissions/rbac-permissions-operator",
+		"openshift-route-monitor-operator/route-monitor-operator",
+		"openshift-splunk-forwarder-operator/openshift-splunk-forwarder-operator",
+	}
+	// protectedFields are the .spec fields of a managed Subscription which may
+	// not change
+	protectedFields = []string{
+		"channel",
+		"installPlanApproval",
+	}
+)
+
+// SubscriptionWebhook protects managed OLM Subscriptions from tampering
+type SubscriptionWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *SubscriptionWebhook {
+	return &SubscriptionWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *SubscriptionWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *SubscriptionWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if request.Operation != admissionv1.Update {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	newSub, oldSub, err := s.renderOldAndNewSubscriptions(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a Subscription from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if isManagedSubscription(oldSub) && !isAllowedUserGroup(request) {
+		for _, field := range protectedFields {
+			oldValue, _, _ := unstructured.NestedString(oldSub.Object, "spec", field)
+			newValue, _, _ := unstructured.NestedString(newSub.Object, "spec", field)
+			if oldValue != newValue {
+				log.Info(fmt.Sprintf("Change of spec.%s detected on managed Subscription: %s/%s", field, oldSub.GetNamespace(), oldSub.GetName()))
+				ret = admissionctl.Denied(fmt.Sprintf("Changing spec.%s of managed Subscription %s/%s is not allowed", field, oldSub.GetNamespace(), oldSub.GetName()))
+				ret.UID = request.AdmissionRequest.UID
+				return ret
+			}
+		}
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderOldAndNewSubscriptions decodes both the Object and OldObject of the
+// request. The OLM types are not vendored, so they are decoded generically.
+// Return order is: new, old, error.
+func (s *SubscriptionWebhook) renderOldAndNewSubscriptions(request admissionctl.Request) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	decoder, err := admissionctl.NewDecoder(s.s)
+	if err != nil {
+		return nil, nil, err
+	}
+	newSub := &unstructured.Unstructured{}
+	oldSub := &unstructured.Unstructured{}
+
+	err = decoder.DecodeRaw(request.Object, newSub)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+	err = decoder.DecodeRaw(request.OldObject, oldSub)
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, nil, err
+	}
+
+	return newSub, oldSub, nil
+}
+
+// isManagedSubscription checks if the Subscription is in the managed inventory
+func isManagedSubscription(sub *unstructured.Unstructured) bool {
+	return utils.SliceContains(sub.GetNamespace()+"/"+sub.GetName(), managedSubscriptions)
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the action
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *SubscriptionWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *SubscriptionWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && (request.Kind.Kind == subscriptionKind)
+	valid = valid && (request.Kind.Group == subscriptionGroup)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *SubscriptionWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *SubscriptionWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *SubscriptionWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *SubscriptionWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *SubscriptionWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *SubscriptionWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *SubscriptionWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *SubscriptionWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedSubscriptions)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *SubscriptionWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
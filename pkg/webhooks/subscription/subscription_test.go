@@ -0,0 +1,155 @@
+package subscription
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type subscriptionTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	oldChannel      string
+	newChannel      string
+	oldApproval     string
+	newApproval     string
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "operators.coreos.com/v1alpha1",
+	"kind": "Subscription",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"channel": "%s",
+		"installPlanApproval": "%s",
+		"name": "%s",
+		"source": "redhat-operators",
+		"sourceNamespace": "openshift-marketplace"
+	}
+}`
+
+func runSubscriptionTests(t *testing.T, tests []subscriptionTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "operators.coreos.com",
+		Version: "v1alpha1",
+		Kind:    "Subscription",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "operators.coreos.com",
+		Version:  "v1alpha1",
+		Resource: "subscriptions",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.newChannel, test.newApproval, test.targetName)),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.oldChannel, test.oldApproval, test.targetName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, admissionv1.Update, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s update the Subscription %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []subscriptionTestSuites{
+		{
+			testID:          "user-cant-change-managed-channel",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			oldChannel:      "production",
+			newChannel:      "staging",
+			oldApproval:     "Automatic",
+			newApproval:     "Automatic",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-change-managed-approval",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			oldChannel:      "production",
+			newChannel:      "production",
+			oldApproval:     "Automatic",
+			newApproval:     "Manual",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runSubscriptionTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []subscriptionTestSuites{
+		{
+			testID:          "user-can-change-customer-channel",
+			targetNamespace: "my-operators",
+			targetName:      "my-operator",
+			oldChannel:      "stable",
+			newChannel:      "beta",
+			oldApproval:     "Automatic",
+			newApproval:     "Manual",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "olm-can-change-managed-channel",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			oldChannel:      "production",
+			newChannel:      "staging",
+			oldApproval:     "Automatic",
+			newApproval:     "Automatic",
+			username:        "system:serviceaccount:openshift-operator-lifecycle-manager:olm-operator-serviceaccount",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-operator-lifecycle-manager"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-make-benign-change-to-managed",
+			targetNamespace: "openshift-must-gather-operator",
+			targetName:      "must-gather-operator",
+			oldChannel:      "production",
+			newChannel:      "production",
+			oldApproval:     "Automatic",
+			newApproval:     "Automatic",
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runSubscriptionTests(t, tests)
+}
@@ -0,0 +1,163 @@
+package tuned
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type tunedTestSuites struct {
+	testID          string
+	targetNamespace string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "tuned.openshift.io/v1",
+	"kind": "Tuned",
+	"metadata": {
+		"name": "%s",
+		"namespace": "%s",
+		"uid": "1234"
+	},
+	"spec": {
+		"profile": [
+			{
+				"name": "%s",
+				"data": "[main]\ninclude=openshift-node\n"
+			}
+		]
+	}
+}`
+
+func runTunedTests(t *testing.T, tests []tunedTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "tuned.openshift.io",
+		Version: "v1",
+		Kind:    "Tuned",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "tuned.openshift.io",
+		Version:  "v1",
+		Resource: "tuneds",
+	}
+
+	for _, test := range tests {
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, test.targetNamespace, test.targetName)),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the Tuned %s/%s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetNamespace, test.targetName, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []tunedTestSuites{
+		{
+			testID:          "user-cant-modify-managed-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "default",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-delete-managed-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "rendered",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runTunedTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []tunedTestSuites{
+		{
+			testID:          "user-can-modify-customer-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "my-tuning",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-delete-customer-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "my-tuning",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "nto-can-modify-managed-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "rendered",
+			operation:       admissionv1.Update,
+			username:        "system:serviceaccount:openshift-cluster-node-tuning-operator:cluster-node-tuning-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-node-tuning-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runTunedTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedTuneds
+	defer func() { managedTuneds = oldInventory }()
+	managedTuneds = []string{"openshift-cluster-node-tuning-operator/managed-hugepages"}
+
+	tests := []tunedTestSuites{
+		{
+			testID:          "user-cant-modify-configured-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "managed-hugepages",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-modify-unconfigured-tuned",
+			targetNamespace: "openshift-cluster-node-tuning-operator",
+			targetName:      "default",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runTunedTests(t, tests)
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// AllowOnceKey is both the annotation and the request.UserInfo.Extra key
+	// carrying an allow-once token. The Extra is for operations such as
+	// deletions, where the object can't be annotated.
+	AllowOnceKey string = "managed.openshift.io/allow-once"
+	// AllowOnceMaxLifetime is how far in the future a token may expire.
+	// Tokens are meant for a single scripted operation, not to be stored, and
+	// an AllowOnceVerifier only remembers the tokens used on its replica.
+	AllowOnceMaxLifetime time.Duration = 5 * time.Minute
+)
+
+// ErrNoAllowOnceToken is returned by AllowOnceVerifier.Verify when the request
+// carries no token, in which case the request is evaluated normally
+var ErrNoAllowOnceToken = errors.New("no allow-once token in the request")
+
+// AllowOnceToken authorizes a single operation on a single object, which a
+// webhook would otherwise deny. It is signed with an HMAC-SHA256 of a cluster
+// secret, and encoded as base64url(JSON token).base64url(signature).
+type AllowOnceToken struct {
+	// ID identifies the token, so that it can only be used once
+	ID        string `json:"id"`
+	Webhook   string `json:"webhook"`
+	Operation string `json:"operation"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Expires is the Unix time the token expires at
+	Expires int64 `json:"exp"`
+}
+
+// SignAllowOnceToken encodes and signs the token with the secret
+func SignAllowOnceToken(secret []byte, token AllowOnceToken) (string, error) {
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(secret, encoded)), nil
+}
+
+func sign(secret []byte, encoded string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// AllowOnceVerifier checks the allow-once tokens of requests, and remembers
+// the tokens it accepted until they expire so that none is used for two
+// requests. The tokens used are only remembered in memory, so a token may be
+// used once on each replica of the webhook, and again after a restart, until
+// it expires; AllowOnceMaxLifetime is kept short for that reason. It is safe
+// for concurrent use.
+type AllowOnceVerifier struct {
+	mu     sync.Mutex
+	secret func() []byte
+	clock  Clock
+	// used maps the ID of each accepted token to its use
+	used map[string]allowOnceUse
+}
+
+// allowOnceUse records the request a token was used for
+type allowOnceUse struct {
+	uid     types.UID
+	expires time.Time
+}
+
+// NewAllowOnceVerifier creates an AllowOnceVerifier. secret returns the
+// current signing secret, and tokens are rejected while it is empty. clock
+// tells the time the expiries are checked against.
+func NewAllowOnceVerifier(secret func() []byte, clock Clock) *AllowOnceVerifier {
+	return &AllowOnceVerifier{
+		secret: secret,
+		clock:  clock,
+		used:   map[string]allowOnceUse{},
+	}
+}
+
+// Verify checks the token of the request against the webhook, the requested
+// operation and the targeted object. A valid token is consumed by the
+// request, and returned. The API server retrying the same request, with the
+// same UID, is accepted again, and dry-run requests don't consume the token.
+// ErrNoAllowOnceToken is returned when the request carries no token.
+func (v *AllowOnceVerifier) Verify(request admissionctl.Request, webhook, namespace, name string) (*AllowOnceToken, error) {
+	raw, err := allowOnceToken(request)
+	if err != nil {
+		return nil, err
+	}
+	secret := v.secret()
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("no allow-once secret is configured")
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed allow-once token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0])) {
+		return nil, fmt.Errorf("invalid allow-once token signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed allow-once token: %v", err)
+	}
+	token := &AllowOnceToken{}
+	if err := json.Unmarshal(payload, token); err != nil {
+		return nil, fmt.Errorf("malformed allow-once token: %v", err)
+	}
+
+	if token.ID == "" {
+		return nil, fmt.Errorf("allow-once token has no ID")
+	}
+	if token.Webhook != webhook || token.Operation != string(request.Operation) || token.Namespace != namespace || token.Name != name {
+		return nil, fmt.Errorf("allow-once token %s is for %s %s/%s in %s", token.ID, token.Operation, token.Namespace, token.Name, token.Webhook)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.clock.Now()
+	expires := time.Unix(token.Expires, 0)
+	if !now.Before(expires) {
+		return nil, fmt.Errorf("allow-once token %s expired at %s", token.ID, expires.UTC().Format(time.RFC3339))
+	}
+	if expires.Sub(now) > AllowOnceMaxLifetime {
+		return nil, fmt.Errorf("allow-once token %s expires more than %s from now", token.ID, AllowOnceMaxLifetime)
+	}
+	for id, use := range v.used {
+		if !now.Before(use.expires) {
+			delete(v.used, id)
+		}
+	}
+	if use, ok := v.used[token.ID]; ok {
+		if use.uid == request.AdmissionRequest.UID {
+			return token, nil
+		}
+		return nil, fmt.Errorf("allow-once token %s was already used", token.ID)
+	}
+	if request.DryRun == nil || !*request.DryRun {
+		v.used[token.ID] = allowOnceUse{uid: request.AdmissionRequest.UID, expires: expires}
+	}
+	return token, nil
+}
+
+// allowOnceToken returns the token of the request, from the Extra of the
+// user first, then from the annotations of the object, or of the existing
+// object
+func allowOnceToken(request admissionctl.Request) (string, error) {
+	if values := request.UserInfo.Extra[AllowOnceKey]; len(values) > 0 && values[0] != "" {
+		return values[0], nil
+	}
+	for _, raw := range [][]byte{request.Object.Raw, request.OldObject.Raw} {
+		if len(raw) == 0 {
+			continue
+		}
+		obj := &metav1.PartialObjectMetadata{}
+		if err := json.Unmarshal(raw, obj); err != nil {
+			return "", err
+		}
+		if token := obj.GetAnnotations()[AllowOnceKey]; token != "" {
+			return token, nil
+		}
+	}
+	return "", ErrNoAllowOnceToken
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var (
+	testAllowOnceSecret = []byte("cluster-secret")
+	testAllowOnceNow    = time.Date(2021, 6, 1, 2, 0, 0, 0, time.UTC)
+)
+
+func testAllowOnceToken(t *testing.T, secret []byte, mutate func(*AllowOnceToken)) string {
+	token := AllowOnceToken{
+		ID:        "token-1",
+		Webhook:   "scc-validation",
+		Operation: "DELETE",
+		Name:      "privileged",
+		Expires:   testAllowOnceNow.Add(2 * time.Minute).Unix(),
+	}
+	if mutate != nil {
+		mutate(&token)
+	}
+	signed, err := SignAllowOnceToken(secret, token)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	return signed
+}
+
+// allowOnceRequest is a DELETE of the privileged SCC with the token in the
+// user's Extra
+func allowOnceRequest(token string) admissionctl.Request {
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:       "allow-once",
+			Operation: admissionv1.Delete,
+			UserInfo:  authenticationv1.UserInfo{Username: "system:serviceaccount:openshift-backplane-managed-scripts:script-runner"},
+			OldObject: runtime.RawExtension{Raw: []byte(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "privileged"}}`)},
+		},
+	}
+	if token != "" {
+		request.UserInfo.Extra = map[string]authenticationv1.ExtraValue{AllowOnceKey: {token}}
+	}
+	return request
+}
+
+func newTestAllowOnceVerifier(secret []byte) *AllowOnceVerifier {
+	return NewAllowOnceVerifier(func() []byte { return secret }, NewFakeClock(testAllowOnceNow))
+}
+
+func TestAllowOnceValidToken(t *testing.T) {
+	verifier := newTestAllowOnceVerifier(testAllowOnceSecret)
+	request := allowOnceRequest(testAllowOnceToken(t, testAllowOnceSecret, nil))
+
+	token, err := verifier.Verify(request, "scc-validation", "", "privileged")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if token.ID != "token-1" {
+		t.Fatalf("Expected token %s, got %s", "token-1", token.ID)
+	}
+
+	if _, err := verifier.Verify(request, "scc-validation", "", "privileged"); err != nil {
+		t.Fatalf("Expected the token to be accepted again for a retry of the same request, got %s", err.Error())
+	}
+	request.UID = "another-request"
+	if _, err := verifier.Verify(request, "scc-validation", "", "privileged"); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("Expected the token to be rejected for another request, got %v", err)
+	}
+}
+
+func TestAllowOnceDryRun(t *testing.T) {
+	verifier := newTestAllowOnceVerifier(testAllowOnceSecret)
+	request := allowOnceRequest(testAllowOnceToken(t, testAllowOnceSecret, nil))
+	dryRun := true
+	request.DryRun = &dryRun
+
+	if _, err := verifier.Verify(request, "scc-validation", "", "privileged"); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	request.UID = "real-request"
+	request.DryRun = nil
+	if _, err := verifier.Verify(request, "scc-validation", "", "privileged"); err != nil {
+		t.Fatalf("Expected a dry run not to consume the token, got %s", err.Error())
+	}
+}
+
+func TestAllowOnceTokenInAnnotation(t *testing.T) {
+	verifier := newTestAllowOnceVerifier(testAllowOnceSecret)
+	token := testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Operation = "UPDATE" })
+	raw := fmt.Sprintf(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "privileged", "annotations": {%q: %q}}}`, AllowOnceKey, token)
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    runtime.RawExtension{Raw: []byte(raw)},
+			OldObject: runtime.RawExtension{Raw: []byte(`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "privileged"}}`)},
+		},
+	}
+
+	if _, err := verifier.Verify(request, "scc-validation", "", "privileged"); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+}
+
+func TestAllowOnceInvalidTokens(t *testing.T) {
+	valid := testAllowOnceToken(t, testAllowOnceSecret, nil)
+	parts := strings.Split(valid, ".")
+	// The payload of another token, under the signature of the valid one
+	tampered := strings.Split(testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Name = "anyuid" }), ".")[0] + "." + parts[1]
+
+	tests := []struct {
+		name     string
+		secret   []byte
+		token    string
+		expected string
+	}{
+		{
+			name:     "no token",
+			secret:   testAllowOnceSecret,
+			token:    "",
+			expected: ErrNoAllowOnceToken.Error(),
+		},
+		{
+			name:     "no secret",
+			secret:   nil,
+			token:    valid,
+			expected: "no allow-once secret",
+		},
+		{
+			name:     "expired",
+			secret:   testAllowOnceSecret,
+			token:    testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Expires = testAllowOnceNow.Unix() }),
+			expected: "expired",
+		},
+		{
+			name:     "long-lived",
+			secret:   testAllowOnceSecret,
+			token:    testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Expires = testAllowOnceNow.Add(24 * time.Hour).Unix() }),
+			expected: "expires more than",
+		},
+		{
+			name:     "tampered",
+			secret:   testAllowOnceSecret,
+			token:    tampered,
+			expected: "invalid allow-once token signature",
+		},
+		{
+			name:     "signed with another secret",
+			secret:   testAllowOnceSecret,
+			token:    testAllowOnceToken(t, []byte("other-secret"), nil),
+			expected: "invalid allow-once token signature",
+		},
+		{
+			name:     "malformed",
+			secret:   testAllowOnceSecret,
+			token:    parts[0],
+			expected: "malformed",
+		},
+		{
+			name:     "for another object",
+			secret:   testAllowOnceSecret,
+			token:    testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Name = "anyuid" }),
+			expected: "is for DELETE /anyuid",
+		},
+		{
+			name:     "for another operation",
+			secret:   testAllowOnceSecret,
+			token:    testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Operation = "UPDATE" }),
+			expected: "is for UPDATE /privileged",
+		},
+		{
+			name:     "for another webhook",
+			secret:   testAllowOnceSecret,
+			token:    testAllowOnceToken(t, testAllowOnceSecret, func(token *AllowOnceToken) { token.Webhook = "secret-validation" }),
+			expected: "in secret-validation",
+		},
+	}
+
+	for _, test := range tests {
+		verifier := newTestAllowOnceVerifier(test.secret)
+		_, err := verifier.Verify(allowOnceRequest(test.token), "scc-validation", "", "privileged")
+		if err == nil {
+			t.Fatalf("Expected the %s token to be rejected", test.name)
+		}
+		if !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("Expected the error for the %s token to mention %q, got %s", test.name, test.expected, err.Error())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("Expected %s, got %s", start, now)
+	}
+
+	clock.Step(time.Minute)
+	if now := clock.Now(); !now.Equal(start.Add(time.Minute)) {
+		t.Fatalf("Expected %s, got %s", start.Add(time.Minute), now)
+	}
+
+	clock.Set(start)
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("Expected %s, got %s", start, now)
+	}
+}
+
+func TestClockFunc(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	var clock Clock = ClockFunc(func() time.Time { return start })
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("Expected %s, got %s", start, now)
+	}
+}
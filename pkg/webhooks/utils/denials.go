@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Denial records a request a webhook denied
+type Denial struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Resource string    `json:"resource"`
+	Reason   string    `json:"reason"`
+}
+
+// DenialLog keeps the most recent denials of a webhook, in a fixed amount of
+// memory. It is safe for concurrent use.
+type DenialLog struct {
+	mu      sync.Mutex
+	entries []Denial
+	// next is the index the next denial is written at
+	next int
+	// full is set once entries has wrapped around
+	full bool
+}
+
+// NewDenialLog creates a DenialLog keeping the last size denials
+func NewDenialLog(size int) *DenialLog {
+	if size < 1 {
+		size = 1
+	}
+	return &DenialLog{
+		entries: make([]Denial, size),
+	}
+}
+
+// Record adds the denial, evicting the oldest one when the log is full
+func (l *DenialLog) Record(denial Denial) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = denial
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns a copy of the recorded denials, oldest first
+func (l *DenialLog) Recent() []Denial {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		recent := make([]Denial, l.next)
+		copy(recent, l.entries[:l.next])
+		return recent
+	}
+	recent := make([]Denial, 0, len(l.entries))
+	recent = append(recent, l.entries[l.next:]...)
+	return append(recent, l.entries[:l.next]...)
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDenialLogWraparound(t *testing.T) {
+	l := NewDenialLog(3)
+	if recent := l.Recent(); len(recent) != 0 {
+		t.Fatalf("Expected an empty log, got %v", recent)
+	}
+
+	for i := 0; i < 2; i++ {
+		l.Record(Denial{User: fmt.Sprintf("user%d", i)})
+	}
+	assertUsers(t, l.Recent(), "user0", "user1")
+
+	for i := 2; i < 5; i++ {
+		l.Record(Denial{User: fmt.Sprintf("user%d", i)})
+	}
+	assertUsers(t, l.Recent(), "user2", "user3", "user4")
+
+	l.Record(Denial{User: "user5"})
+	assertUsers(t, l.Recent(), "user3", "user4", "user5")
+}
+
+func TestDenialLogConcurrency(t *testing.T) {
+	l := NewDenialLog(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Record(Denial{User: fmt.Sprintf("user%d", i)})
+				l.Recent()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if recent := l.Recent(); len(recent) != 10 {
+		t.Fatalf("Expected the log to be bounded to 10 denials, got %d", len(recent))
+	}
+}
+
+func assertUsers(t *testing.T, denials []Denial, users ...string) {
+	t.Helper()
+	if len(denials) != len(users) {
+		t.Fatalf("Expected %d denials, got %d: %v", len(users), len(denials), denials)
+	}
+	for i, user := range users {
+		if denials[i].User != user {
+			t.Fatalf("Expected denial %d to be by %s, got %s", i, user, denials[i].User)
+		}
+	}
+}
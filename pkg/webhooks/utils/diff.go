@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// unsetSummary summarizes a field which is absent from one of the objects
+	unsetSummary string = "<unset>"
+	// summaryLength is how long a summary gets before it is truncated
+	summaryLength int = 64
+)
+
+// serverManagedFields are the fields the API server changes on every write,
+// which DiffObjects leaves out
+var serverManagedFields = []string{
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.managedFields",
+}
+
+// FieldChange is a field which differs between two versions of an object
+type FieldChange struct {
+	// Path is the dot separated path of the field, eg spec.replicas
+	Path string `json:"path"`
+	// Old and New summarize the values of the field, unsetSummary when absent
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// DiffObjects returns the fields which differ between the old and new
+// versions of an object, sorted by path. Maps present on both sides are
+// compared field by field, any other value, slices included, is compared as a
+// whole. A nil object has no fields, so every field of the other one is
+// reported. The fields the API server maintains are left out.
+func DiffObjects(old, new runtime.Object) []FieldChange {
+	changes := diffFields("", toFields(old), toFields(new))
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// ChangedPaths returns the paths of the changes
+func ChangedPaths(changes []FieldChange) []string {
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// toFields returns the fields of the object, as they are serialized. An
+// object which can't be serialized has no fields.
+func toFields(obj runtime.Object) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	if v := reflect.ValueOf(obj); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func diffFields(prefix string, old, new map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	for key, oldValue := range old {
+		path := joinPath(prefix, key)
+		if SliceContains(path, serverManagedFields) {
+			continue
+		}
+		newValue, ok := new[key]
+		if !ok {
+			changes = append(changes, FieldChange{Path: path, Old: summarize(oldValue), New: unsetSummary})
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			changes = append(changes, diffFields(path, oldMap, newMap)...)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Path: path, Old: summarize(oldValue), New: summarize(newValue)})
+		}
+	}
+	for key, newValue := range new {
+		path := joinPath(prefix, key)
+		if _, ok := old[key]; ok || SliceContains(path, serverManagedFields) {
+			continue
+		}
+		changes = append(changes, FieldChange{Path: path, Old: unsetSummary, New: summarize(newValue)})
+	}
+	return changes
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// summarize renders the value as JSON, truncated to summaryLength
+func summarize(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		raw = []byte(fmt.Sprint(value))
+	}
+	summary := string(raw)
+	if len(summary) > summaryLength {
+		summary = strings.TrimSpace(summary[:summaryLength-3]) + "..."
+	}
+	return summary
+}
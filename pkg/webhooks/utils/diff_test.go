@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiffObjects(t *testing.T) {
+	old := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "config",
+			Namespace:       "test",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"team": "a", "tier": "web"},
+			Finalizers:      []string{"one"},
+		},
+		Data: map[string]string{"mode": "fast"},
+	}
+	new := old.DeepCopy()
+	new.ResourceVersion = "2"
+	new.Labels = map[string]string{"team": "b", "env": "prod"}
+	new.Finalizers = []string{"one", "two"}
+	new.Data = map[string]string{"mode": "slow"}
+
+	expected := []FieldChange{
+		{Path: "data.mode", Old: `"fast"`, New: `"slow"`},
+		{Path: "metadata.finalizers", Old: `["one"]`, New: `["one","two"]`},
+		{Path: "metadata.labels.env", Old: unsetSummary, New: `"prod"`},
+		{Path: "metadata.labels.team", Old: `"a"`, New: `"b"`},
+		{Path: "metadata.labels.tier", Old: `"web"`, New: unsetSummary},
+	}
+	if changes := DiffObjects(old, new); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected changes %v, got %v", expected, changes)
+	}
+
+	if changes := DiffObjects(old, old.DeepCopy()); len(changes) != 0 {
+		t.Fatalf("Expected no change between identical objects, got %v", changes)
+	}
+}
+
+func TestDiffObjectsNil(t *testing.T) {
+	obj := &corev1.ConfigMap{Data: map[string]string{"mode": "fast"}}
+
+	created := DiffObjects(nil, obj)
+	if paths := ChangedPaths(created); !reflect.DeepEqual(paths, []string{"data", "metadata"}) {
+		t.Fatalf("Expected every field of a new object to be reported, got %v", paths)
+	}
+	for _, change := range created {
+		if change.Old != unsetSummary {
+			t.Fatalf("Expected %s to be unset in the nil object, got %s", change.Path, change.Old)
+		}
+	}
+
+	var nilConfigMap *corev1.ConfigMap
+	deleted := DiffObjects(obj, nilConfigMap)
+	if paths := ChangedPaths(deleted); !reflect.DeepEqual(paths, []string{"data", "metadata"}) {
+		t.Fatalf("Expected every field of a removed object to be reported, got %v", paths)
+	}
+
+	if changes := DiffObjects(nil, nil); len(changes) != 0 {
+		t.Fatalf("Expected no change between nil objects, got %v", changes)
+	}
+}
+
+func TestDiffObjectsSummary(t *testing.T) {
+	old := &corev1.ConfigMap{Data: map[string]string{"blob": "short"}}
+	new := &corev1.ConfigMap{Data: map[string]string{"blob": strings.Repeat("x", 200)}}
+
+	changes := DiffObjects(old, new)
+	if len(changes) != 1 {
+		t.Fatalf("Expected a single change, got %v", changes)
+	}
+	if len(changes[0].New) != summaryLength || !strings.HasSuffix(changes[0].New, "...") {
+		t.Fatalf("Expected the summary to be truncated to %d characters, got %q", summaryLength, changes[0].New)
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// RequestIdentity is the identity a request is made with, as the API server
+// authenticated it, with the service account it belongs to parsed out
+type RequestIdentity struct {
+	Username string
+	Groups   []string
+	UID      string
+	// IsServiceAccount is only set when both the username and the namespace
+	// group are the ones of a service account, so that a user merely named
+	// like a service account isn't taken for one
+	IsServiceAccount        bool
+	ServiceAccountNamespace string
+	ServiceAccountName      string
+}
+
+// Identity returns the identity the request is made with. Webhooks check it
+// rather than parse the UserInfo of the request themselves.
+func Identity(request admissionctl.Request) RequestIdentity {
+	userInfo := request.UserInfo
+	identity := RequestIdentity{
+		Username: userInfo.Username,
+		Groups:   userInfo.Groups,
+		UID:      userInfo.UID,
+	}
+
+	if !strings.HasPrefix(userInfo.Username, serviceAccountUsernamePrefix) {
+		return identity
+	}
+	parts := strings.Split(strings.TrimPrefix(userInfo.Username, serviceAccountUsernamePrefix), ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return identity
+	}
+	if !SliceContains(serviceAccountGroupPrefix+parts[0], userInfo.Groups) {
+		return identity
+	}
+	identity.IsServiceAccount = true
+	identity.ServiceAccountNamespace = parts[0]
+	identity.ServiceAccountName = parts[1]
+	return identity
+}
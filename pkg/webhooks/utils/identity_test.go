@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestIdentity(t *testing.T) {
+	tests := []struct {
+		testID   string
+		userInfo authenticationv1.UserInfo
+		expected RequestIdentity
+	}{
+		{
+			testID:   "user",
+			userInfo: authenticationv1.UserInfo{Username: "user1", UID: "1234", Groups: []string{"system:authenticated", "system:authenticated:oauth"}},
+			expected: RequestIdentity{Username: "user1", UID: "1234", Groups: []string{"system:authenticated", "system:authenticated:oauth"}},
+		},
+		{
+			testID: "service-account",
+			userInfo: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+				UID:      "5678",
+				Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring", "system:authenticated"},
+			},
+			expected: RequestIdentity{
+				Username:                "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+				UID:                     "5678",
+				Groups:                  []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring", "system:authenticated"},
+				IsServiceAccount:        true,
+				ServiceAccountNamespace: "openshift-monitoring",
+				ServiceAccountName:      "cluster-monitoring-operator",
+			},
+		},
+		{
+			testID:   "anonymous",
+			userInfo: authenticationv1.UserInfo{Username: "system:anonymous", Groups: []string{"system:unauthenticated"}},
+			expected: RequestIdentity{Username: "system:anonymous", Groups: []string{"system:unauthenticated"}},
+		},
+		{
+			testID:   "user-named-like-service-account",
+			userInfo: authenticationv1.UserInfo{Username: "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator", Groups: []string{"system:authenticated"}},
+			expected: RequestIdentity{Username: "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator", Groups: []string{"system:authenticated"}},
+		},
+		{
+			testID:   "malformed-service-account",
+			userInfo: authenticationv1.UserInfo{Username: "system:serviceaccount:openshift-monitoring", Groups: []string{"system:serviceaccounts:openshift-monitoring"}},
+			expected: RequestIdentity{Username: "system:serviceaccount:openshift-monitoring", Groups: []string{"system:serviceaccounts:openshift-monitoring"}},
+		},
+	}
+
+	for _, test := range tests {
+		request := admissionctl.Request{AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: test.userInfo}}
+		if identity := Identity(request); !reflect.DeepEqual(identity, test.expected) {
+			t.Fatalf("%s: expected %+v, got %+v", test.testID, test.expected, identity)
+		}
+	}
+}
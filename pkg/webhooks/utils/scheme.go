@@ -0,0 +1,48 @@
+package utils
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	networkv1 "github.com/openshift/api/network/v1"
+	quotav1 "github.com/openshift/api/quota/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	cl "github.com/openshift/cluster-logging-operator/pkg/apis/logging/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+// Scheme holds every type the webhooks decode. It is built once, at init,
+// and only read afterwards, so all the webhooks share it. Types which aren't
+// vendored are decoded as unstructured objects and need no registration.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		admissionv1.AddToScheme,
+		appsv1.AddToScheme,
+		autoscalingv1.AddToScheme,
+		batchv1.AddToScheme,
+		corev1.AddToScheme,
+		networkingv1.AddToScheme,
+		policyv1.AddToScheme,
+		rbacv1.AddToScheme,
+		storagev1.AddToScheme,
+		configv1.AddToScheme,
+		networkv1.AddToScheme,
+		quotav1.AddToScheme,
+		routev1.AddToScheme,
+		securityv1.AddToScheme,
+		cl.AddToScheme,
+	} {
+		utilruntime.Must(addToScheme(Scheme))
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestSchemeDecodesWebhookTypes(t *testing.T) {
+	tests := []struct {
+		raw  string
+		into runtime.Object
+	}{
+		{`{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "test"}}`, &securityv1.SecurityContextConstraints{}},
+		{`{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRoleBinding", "metadata": {"name": "test"}}`, &rbacv1.ClusterRoleBinding{}},
+		{`{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "RoleBinding", "metadata": {"name": "test"}}`, &rbacv1.RoleBinding{}},
+		{`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "test"}}`, &corev1.Namespace{}},
+		{`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}}`, &corev1.Pod{}},
+		{`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "test"}}`, &appsv1.Deployment{}},
+		{`{"apiVersion": "batch/v1", "kind": "CronJob", "metadata": {"name": "test"}}`, &batchv1.CronJob{}},
+		{`{"apiVersion": "networking.k8s.io/v1", "kind": "NetworkPolicy", "metadata": {"name": "test"}}`, &networkingv1.NetworkPolicy{}},
+		{`{"apiVersion": "storage.k8s.io/v1", "kind": "StorageClass", "metadata": {"name": "test"}}`, &storagev1.StorageClass{}},
+		{`{"apiVersion": "config.openshift.io/v1", "kind": "FeatureGate", "metadata": {"name": "test"}}`, &configv1.FeatureGate{}},
+		{`{"apiVersion": "route.openshift.io/v1", "kind": "Route", "metadata": {"name": "test"}}`, &routev1.Route{}},
+	}
+
+	decoder, err := admissionctl.NewDecoder(Scheme)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	for _, test := range tests {
+		gvks, _, err := Scheme.ObjectKinds(test.into)
+		if err != nil {
+			t.Fatalf("Expected %T to be registered, got %s", test.into, err.Error())
+		}
+		err = decoder.DecodeRaw(runtime.RawExtension{Raw: []byte(test.raw)}, test.into)
+		if err != nil {
+			t.Fatalf("Expected no error decoding %s, got %s", gvks[0].Kind, err.Error())
+		}
+	}
+}
@@ -1,15 +1,23 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -17,6 +25,67 @@ import (
 
 const validContentType string = "application/json"
 
+// AllowlistEntryType is the kind of identity an AllowlistEntry matches
+type AllowlistEntryType string
+
+const (
+	// AllowlistUser matches a user, but never a service account
+	AllowlistUser AllowlistEntryType = "User"
+	// AllowlistServiceAccount matches a service account by namespace and name
+	AllowlistServiceAccount AllowlistEntryType = "ServiceAccount"
+	// AllowlistGroup matches any member of a group, or of a family of groups
+	// when the name ends with "*", see GroupMatches
+	AllowlistGroup AllowlistEntryType = "Group"
+
+	serviceAccountUsernamePrefix string = "system:serviceaccount:"
+	serviceAccountGroupPrefix    string = "system:serviceaccounts:"
+)
+
+// AllowlistEntry is an explicitly typed allowlist entry. Unlike a flat slice
+// of usernames, each entry is only checked against the matching part of the
+// request's UserInfo.
+type AllowlistEntry struct {
+	Type AllowlistEntryType
+	Name string
+	// Namespace of the service account, for AllowlistServiceAccount entries
+	Namespace string
+	// Expires, when set, is when the entry stops matching. Temporary
+	// exceptions, eg for a migration, carry one so that they get reviewed
+	// rather than become permanent.
+	Expires time.Time
+}
+
+// Active checks if the entry still matches at the given time
+func (e AllowlistEntry) Active(now time.Time) bool {
+	return e.Expires.IsZero() || now.Before(e.Expires)
+}
+
+// platformAllowedIdentities are the core platform identities every webhook
+// trusts, on top of its own allowlist. Keep it to the components which
+// manage the whole cluster, as any entry bypasses every webhook.
+var platformAllowedIdentities = []AllowlistEntry{
+	{Type: AllowlistServiceAccount, Namespace: "openshift-cluster-version", Name: "default"},
+}
+
+// PlatformAllowedIdentities returns the baseline of platform identities the
+// webhooks merge with their own allowlist, so that the core platform service
+// accounts are trusted consistently. The returned slice is a copy.
+func PlatformAllowedIdentities() []AllowlistEntry {
+	identities := make([]AllowlistEntry, len(platformAllowedIdentities))
+	copy(identities, platformAllowedIdentities)
+	return identities
+}
+
+// PlatformClock tells the time the expiry of the platform identities is
+// checked against. Tests may replace it with a FakeClock.
+var PlatformClock Clock = RealClock{}
+
+// IsPlatformIdentity checks if the user is one of the platform identities
+// every webhook trusts on top of its own allowlist
+func IsPlatformIdentity(userInfo authenticationv1.UserInfo) bool {
+	return AllowlistContains(userInfo, platformAllowedIdentities, PlatformClock.Now())
+}
+
 var (
 	admissionScheme = runtime.NewScheme()
 	admissionCodecs = serializer.NewCodecFactory(admissionScheme)
@@ -30,6 +99,21 @@ func DefaultLabelSelector() metav1.LabelSelector {
 	}
 }
 
+// HasExemptLabel checks if the labels of the object match the selector, so
+// that a webhook lets it through. An empty selector exempts nothing, rather
+// than everything. Only use labels which customers can't set themselves,
+// otherwise they can opt out of the protection.
+func HasExemptLabel(obj metav1.Object, selector metav1.LabelSelector) (bool, error) {
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return false, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(&selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(obj.GetLabels())), nil
+}
+
 func SliceContains(needle string, haystack []string) bool {
 	for _, check := range haystack {
 		if needle == check {
@@ -49,6 +133,240 @@ func RegexSliceContains(needle string, haystack []string) bool {
 	return false
 }
 
+// GroupMatches checks whether the group matches the allowlisted pattern. A
+// pattern ending with "*" matches groups with the same prefix followed by a
+// single, non-empty name segment, eg "system:serviceaccounts:openshift-*"
+// matches "system:serviceaccounts:openshift-monitoring" but neither
+// "system:serviceaccounts:openshift-" nor "system:serviceaccounts:openshift".
+// A "*" anywhere else is taken literally, and a pattern which is nothing but
+// the wildcard matches nothing. Any other pattern must match exactly.
+func GroupMatches(pattern, group string) bool {
+	if !strings.HasSuffix(pattern, "*") {
+		return pattern == group
+	}
+	prefix := strings.TrimSuffix(pattern, "*")
+	if prefix == "" || !strings.HasPrefix(group, prefix) {
+		return false
+	}
+	rest := strings.TrimPrefix(group, prefix)
+	// The remainder must not reach into another segment of the group name
+	return rest != "" && !strings.Contains(rest, ":")
+}
+
+// GroupsMatch checks whether any of the groups matches any of the patterns,
+// as per GroupMatches
+func GroupsMatch(patterns []string, groups []string) bool {
+	for _, pattern := range patterns {
+		for _, group := range groups {
+			if GroupMatches(pattern, group) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowlistContains checks whether the user matches any of the entries active
+// at now. A service account only matches when both its username and its
+// namespace group match, so a user merely named like a service account is
+// never trusted as one.
+func AllowlistContains(userInfo authenticationv1.UserInfo, allowlist []AllowlistEntry, now time.Time) bool {
+	isServiceAccount := strings.HasPrefix(userInfo.Username, serviceAccountUsernamePrefix)
+	for _, entry := range allowlist {
+		if !entry.Active(now) {
+			continue
+		}
+		switch entry.Type {
+		case AllowlistUser:
+			if !isServiceAccount && userInfo.Username == entry.Name {
+				return true
+			}
+		case AllowlistServiceAccount:
+			if userInfo.Username == serviceAccountUsernamePrefix+entry.Namespace+":"+entry.Name &&
+				SliceContains(serviceAccountGroupPrefix+entry.Namespace, userInfo.Groups) {
+				return true
+			}
+		case AllowlistGroup:
+			if GroupsMatch([]string{entry.Name}, userInfo.Groups) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExtraContains checks whether any of the user's extra attributes (eg the ones
+// the API server sets from a client certificate or a bound service account
+// token) matches one of the allowed values for that attribute. allowed maps an
+// extra attribute key to the values that are trusted for it.
+func ExtraContains(extra map[string]authenticationv1.ExtraValue, allowed map[string][]string) bool {
+	for key, values := range allowed {
+		for _, value := range extra[key] {
+			if SliceContains(value, values) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// KindMatches checks if the request is for the given group and kind, either
+// as sent to the webhook (Kind) or as originally submitted (RequestKind). The
+// two differ when the request was converted on its way, eg when submitted
+// through an aggregated API or another API group serving the same objects.
+func KindMatches(request admissionctl.Request, group, kind string) bool {
+	if request.Kind.Group == group && request.Kind.Kind == kind {
+		return true
+	}
+	return request.RequestKind != nil && request.RequestKind.Group == group && request.RequestKind.Kind == kind
+}
+
+// VerifyObjectKind cross-checks request.Kind against the apiVersion and kind
+// the Object and OldObject of the request declare. The API server serializes
+// the objects as request.Kind, so a mismatch is the sign of a spoofed or
+// mangled request, eg by a proxy setting a generic Kind.
+func VerifyObjectKind(request admissionctl.Request) error {
+	for _, raw := range []runtime.RawExtension{request.Object, request.OldObject} {
+		if len(raw.Raw) == 0 {
+			continue
+		}
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(raw.Raw, &typeMeta); err != nil {
+			return err
+		}
+		gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+		if err != nil {
+			return err
+		}
+		if gv.Group != request.Kind.Group || typeMeta.Kind != request.Kind.Kind {
+			return fmt.Errorf("the request is for a %s.%s, but its object is a %s.%s", request.Kind.Kind, request.Kind.Group, typeMeta.Kind, gv.Group)
+		}
+	}
+	return nil
+}
+
+// RenderObjects decodes the Object and OldObject of the request into new
+// instances created by newObject, eg
+//
+//	newObj, oldObj, err := RenderObjects(scheme, request, func() runtime.Object { return &corev1.ConfigMap{} })
+//
+// Either returned object is nil when absent from the request, as with the
+// OldObject of a CREATE or the Object of a DELETE.
+func RenderObjects(scheme *runtime.Scheme, request admissionctl.Request, newObject func() runtime.Object) (runtime.Object, runtime.Object, error) {
+	decoder, err := admissionctl.NewDecoder(scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decode := func(raw runtime.RawExtension) (runtime.Object, error) {
+		if len(raw.Raw) == 0 {
+			return nil, nil
+		}
+		obj := newObject()
+		if err := decoder.DecodeRaw(raw, obj); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	newObj, err := decode(request.Object)
+	if err != nil {
+		return nil, nil, err
+	}
+	oldObj, err := decode(request.OldObject)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newObj, oldObj, nil
+}
+
+// InternalDeadline is how long a webhook gets to decide on a request, leaving
+// a quarter of its TimeoutSeconds to send the response back before the API
+// server gives up on it
+func InternalDeadline(timeoutSeconds int32) time.Duration {
+	return time.Duration(timeoutSeconds) * time.Second * 3 / 4
+}
+
+// maxLateAuthorizations bounds the authorize calls which AuthorizeWithDeadline
+// gave up on and which are still running
+var maxLateAuthorizations int32 = 64
+
+// lateAuthorizations counts the authorize calls which timed out and are
+// still running
+var lateAuthorizations int32
+
+const (
+	authorizeRunning int32 = iota
+	authorizeDone
+	authorizeAbandoned
+)
+
+// AuthorizeWithDeadline runs authorize on the request and returns its
+// response, unless it takes longer than deadline, in which case onTimeout is
+// returned instead, rather than hanging until the API server gives up.
+// authorize can't be cancelled, so it keeps running in the background when
+// it times out, and its late response is discarded. Once
+// maxLateAuthorizations of them are still running, eg behind a dependency
+// which hangs, requests get onTimeout right away instead of piling up more.
+func AuthorizeWithDeadline(request admissionctl.Request, deadline time.Duration, authorize func(admissionctl.Request) admissionctl.Response, onTimeout admissionctl.Response) admissionctl.Response {
+	onTimeout.UID = request.AdmissionRequest.UID
+	if atomic.LoadInt32(&lateAuthorizations) >= maxLateAuthorizations {
+		return onTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+
+	// Buffered, so that a late authorize doesn't block forever
+	responses := make(chan admissionctl.Response, 1)
+	state := authorizeRunning
+	go func() {
+		responses <- authorize(request)
+		if !atomic.CompareAndSwapInt32(&state, authorizeRunning, authorizeDone) {
+			atomic.AddInt32(&lateAuthorizations, -1)
+		}
+	}()
+
+	select {
+	case ret := <-responses:
+		return ret
+	case <-ctx.Done():
+		atomic.AddInt32(&lateAuthorizations, 1)
+		if !atomic.CompareAndSwapInt32(&state, authorizeRunning, authorizeAbandoned) {
+			// authorize returned meanwhile
+			atomic.AddInt32(&lateAuthorizations, -1)
+			return <-responses
+		}
+		return onTimeout
+	}
+}
+
+// TransientRetryAfterSeconds is how long clients are asked to wait before
+// retrying a request answered with TransientError
+const TransientRetryAfterSeconds int32 = 1
+
+// TransientError answers the request with a retriable 503 ServiceUnavailable
+// error, for when the webhook is briefly unable to decide on it. The API
+// server passes the status on to the client, which retries after
+// TransientRetryAfterSeconds, rather than the request being let through under
+// an Ignore FailurePolicy or denied for good.
+func TransientError(message string) admissionctl.Response {
+	return admissionctl.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusServiceUnavailable,
+				Reason:  metav1.StatusReasonServiceUnavailable,
+				Message: message,
+				Details: &metav1.StatusDetails{
+					RetryAfterSeconds: TransientRetryAfterSeconds,
+				},
+			},
+		},
+	}
+}
+
 func ParseHTTPRequest(r *http.Request) (admissionctl.Request, admissionctl.Response, error) {
 	var resp admissionctl.Response
 	var req admissionctl.Request
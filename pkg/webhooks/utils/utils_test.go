@@ -0,0 +1,398 @@
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	newConfigMapRaw string = `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "new"}}`
+	oldConfigMapRaw string = `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "old"}}`
+)
+
+func TestRenderObjects(t *testing.T) {
+	tests := []struct {
+		testID    string
+		operation admissionv1.Operation
+		object    string
+		oldObject string
+		expectNew string
+		expectOld string
+	}{
+		{
+			testID:    "create",
+			operation: admissionv1.Create,
+			object:    newConfigMapRaw,
+			expectNew: "new",
+		},
+		{
+			testID:    "update",
+			operation: admissionv1.Update,
+			object:    newConfigMapRaw,
+			oldObject: oldConfigMapRaw,
+			expectNew: "new",
+			expectOld: "old",
+		},
+		{
+			testID:    "delete",
+			operation: admissionv1.Delete,
+			oldObject: oldConfigMapRaw,
+			expectOld: "old",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+	for _, test := range tests {
+		request := admissionctl.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: test.operation,
+				Object:    runtime.RawExtension{Raw: []byte(test.object)},
+				OldObject: runtime.RawExtension{Raw: []byte(test.oldObject)},
+			},
+		}
+		newObj, oldObj, err := RenderObjects(scheme, request, func() runtime.Object { return &corev1.ConfigMap{} })
+		if err != nil {
+			t.Fatalf("Expected no error for %s, got %s", test.testID, err.Error())
+		}
+
+		if test.expectNew == "" && newObj != nil {
+			t.Fatalf("Expected no new object for %s, got %v", test.testID, newObj)
+		}
+		if test.expectNew != "" && (newObj == nil || newObj.(*corev1.ConfigMap).Name != test.expectNew) {
+			t.Fatalf("Expected new object %s for %s, got %v", test.expectNew, test.testID, newObj)
+		}
+		if test.expectOld == "" && oldObj != nil {
+			t.Fatalf("Expected no old object for %s, got %v", test.testID, oldObj)
+		}
+		if test.expectOld != "" && (oldObj == nil || oldObj.(*corev1.ConfigMap).Name != test.expectOld) {
+			t.Fatalf("Expected old object %s for %s, got %v", test.expectOld, test.testID, oldObj)
+		}
+	}
+}
+
+func TestRenderObjectsDecodeError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			OldObject: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": 1234}}`)},
+		},
+	}
+	if _, _, err := RenderObjects(scheme, request, func() runtime.Object { return &corev1.ConfigMap{} }); err == nil {
+		t.Fatalf("Expected an error decoding a malformed object")
+	}
+}
+
+func TestAllowlistContains(t *testing.T) {
+	allowlist := []AllowlistEntry{
+		{Type: AllowlistUser, Name: "sre-user"},
+		{Type: AllowlistServiceAccount, Namespace: "openshift-monitoring", Name: "cluster-monitoring-operator"},
+		{Type: AllowlistGroup, Name: "sre-group"},
+	}
+	tests := []struct {
+		testID          string
+		userInfo        authenticationv1.UserInfo
+		shouldBeAllowed bool
+	}{
+		{
+			testID:          "allowed-user",
+			userInfo:        authenticationv1.UserInfo{Username: "sre-user", Groups: []string{"system:authenticated"}},
+			shouldBeAllowed: true,
+		},
+		{
+			testID: "allowed-service-account",
+			userInfo: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+				Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:openshift-monitoring", "system:authenticated"},
+			},
+			shouldBeAllowed: true,
+		},
+		{
+			testID: "user-named-like-service-account",
+			userInfo: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:openshift-monitoring:cluster-monitoring-operator",
+				Groups:   []string{"system:authenticated", "system:authenticated:oauth"},
+			},
+			shouldBeAllowed: false,
+		},
+		{
+			testID: "service-account-named-like-user-entry",
+			userInfo: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:sre-user",
+				Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+			},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "group-name-as-username",
+			userInfo:        authenticationv1.UserInfo{Username: "sre-group", Groups: []string{"system:authenticated"}},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "allowed-group",
+			userInfo:        authenticationv1.UserInfo{Username: "user1", Groups: []string{"system:authenticated", "sre-group"}},
+			shouldBeAllowed: true,
+		},
+	}
+	for _, test := range tests {
+		if allowed := AllowlistContains(test.userInfo, allowlist, time.Now()); allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch for %s: allowed is %t, expected %t", test.testID, allowed, test.shouldBeAllowed)
+		}
+	}
+}
+
+func TestPlatformAllowedIdentities(t *testing.T) {
+	cvo := authenticationv1.UserInfo{
+		Username: "system:serviceaccount:openshift-cluster-version:default",
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-version", "system:authenticated"},
+	}
+	if !AllowlistContains(cvo, PlatformAllowedIdentities(), time.Now()) {
+		t.Fatalf("Expected the cluster version operator to be a platform identity")
+	}
+
+	identities := PlatformAllowedIdentities()
+	identities[0].Name = "changed"
+	if PlatformAllowedIdentities()[0].Name == "changed" {
+		t.Fatalf("Expected PlatformAllowedIdentities to return a copy")
+	}
+}
+
+func TestIsPlatformIdentity(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldIdentities, oldClock := platformAllowedIdentities, PlatformClock
+	defer func() { platformAllowedIdentities, PlatformClock = oldIdentities, oldClock }()
+	clock := NewFakeClock(now)
+	PlatformClock = clock
+	platformAllowedIdentities = append(PlatformAllowedIdentities(), AllowlistEntry{Type: AllowlistUser, Name: "migration-user", Expires: now.Add(time.Hour)})
+
+	cvo := authenticationv1.UserInfo{
+		Username: "system:serviceaccount:openshift-cluster-version:default",
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-version"},
+	}
+	if !IsPlatformIdentity(cvo) {
+		t.Fatalf("Expected the cluster version operator to be a platform identity")
+	}
+	if IsPlatformIdentity(authenticationv1.UserInfo{Username: "user1", Groups: []string{"system:authenticated"}}) {
+		t.Fatalf("Expected a regular user not to be a platform identity")
+	}
+	migration := authenticationv1.UserInfo{Username: "migration-user", Groups: []string{"system:authenticated"}}
+	if !IsPlatformIdentity(migration) {
+		t.Fatalf("Expected migration-user to be a platform identity before it expires")
+	}
+	clock.Step(time.Hour)
+	if IsPlatformIdentity(migration) {
+		t.Fatalf("Expected migration-user not to be a platform identity once PlatformClock passed its expiry")
+	}
+}
+
+func TestAllowlistExpiry(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	allowlist := []AllowlistEntry{
+		{Type: AllowlistUser, Name: "migration-user", Expires: now.Add(time.Hour)},
+		{Type: AllowlistUser, Name: "former-migration-user", Expires: now},
+	}
+	userInfo := func(username string) authenticationv1.UserInfo {
+		return authenticationv1.UserInfo{Username: username, Groups: []string{"system:authenticated"}}
+	}
+
+	if !AllowlistContains(userInfo("migration-user"), allowlist, now) {
+		t.Fatalf("Expected an entry to match before it expires")
+	}
+	if AllowlistContains(userInfo("former-migration-user"), allowlist, now) {
+		t.Fatalf("Expected an entry not to match once it expired")
+	}
+	if AllowlistContains(userInfo("migration-user"), allowlist, now.Add(time.Hour)) {
+		t.Fatalf("Expected an entry not to match from its expiry on")
+	}
+}
+
+func TestAuthorizeWithDeadline(t *testing.T) {
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{UID: "deadline"},
+	}
+	onTimeout := admissionctl.Denied("timed out")
+
+	fast := func(request admissionctl.Request) admissionctl.Response {
+		return admissionctl.Allowed("fast")
+	}
+	response := AuthorizeWithDeadline(request, time.Second, fast, onTimeout)
+	if !response.Allowed || response.Result.Reason != "fast" {
+		t.Fatalf("Expected the response of a fast authorize, got %v", response.Result)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	slow := func(request admissionctl.Request) admissionctl.Response {
+		<-release
+		return admissionctl.Allowed("slow")
+	}
+	response = AuthorizeWithDeadline(request, 10*time.Millisecond, slow, onTimeout)
+	if response.Allowed || response.Result.Reason != "timed out" {
+		t.Fatalf("Expected the timeout response for a slow authorize, got %v", response.Result)
+	}
+	if response.UID != request.UID {
+		t.Fatalf("Expected response UID %s, got %s", request.UID, response.UID)
+	}
+}
+
+func TestAuthorizeWithDeadlineBound(t *testing.T) {
+	oldMax := maxLateAuthorizations
+	defer func() { maxLateAuthorizations = oldMax }()
+	maxLateAuthorizations = 2
+
+	request := admissionctl.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{UID: "bound"},
+	}
+	onTimeout := admissionctl.Denied("timed out")
+	release := make(chan struct{})
+	var calls int32
+	slow := func(request admissionctl.Request) admissionctl.Response {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return admissionctl.Allowed("slow")
+	}
+	for i := 0; i < 3; i++ {
+		AuthorizeWithDeadline(request, time.Millisecond, slow, onTimeout)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("Expected authorize to be called %d times while the late ones are still running, got %d", 2, n)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&lateAuthorizations) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected no late authorization to be counted once they returned, got %d", atomic.LoadInt32(&lateAuthorizations))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	fast := func(request admissionctl.Request) admissionctl.Response {
+		return admissionctl.Allowed("fast")
+	}
+	if response := AuthorizeWithDeadline(request, time.Second, fast, onTimeout); !response.Allowed {
+		t.Fatalf("Expected authorize to be called again once the late ones returned, got %v", response.Result)
+	}
+}
+
+func TestInternalDeadline(t *testing.T) {
+	if d := InternalDeadline(2); d != 1500*time.Millisecond {
+		t.Fatalf("Expected a 1.5s deadline for a 2s timeout, got %s", d)
+	}
+}
+
+func TestGroupMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		group    string
+		expected bool
+	}{
+		{"system:serviceaccounts:openshift-monitoring", "system:serviceaccounts:openshift-monitoring", true},
+		{"system:serviceaccounts:openshift-monitoring", "system:serviceaccounts:openshift-monitoring-extra", false},
+		{"system:serviceaccounts:openshift-*", "system:serviceaccounts:openshift-monitoring", true},
+		{"system:serviceaccounts:openshift-*", "system:serviceaccounts:openshift-backplane-srep", true},
+		{"system:serviceaccounts:openshift-*", "system:serviceaccounts:openshift-", false},
+		{"system:serviceaccounts:openshift-*", "system:serviceaccounts:openshift", false},
+		{"system:serviceaccounts:openshift-*", "system:serviceaccounts:customer-openshift-ns", false},
+		{"system:serviceaccounts:openshift-*", "system:serviceaccounts", false},
+		{"system:serviceaccounts:*", "system:serviceaccounts:openshift-monitoring:extra", false},
+		{"system:serviceaccounts:*", "system:serviceaccounts:customer", true},
+		{"*", "system:authenticated", false},
+		{"*", "cluster-admins", false},
+		{"osd-*-admins", "osd-sre-admins", false},
+		{"osd-*-admins", "osd-*-admins", true},
+	}
+	for _, test := range tests {
+		if actual := GroupMatches(test.pattern, test.group); actual != test.expected {
+			t.Fatalf("Expected GroupMatches(%q, %q) to be %t, got %t", test.pattern, test.group, test.expected, actual)
+		}
+	}
+}
+
+func TestKindMatches(t *testing.T) {
+	sccKind := metav1.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"}
+	otherKind := metav1.GroupVersionKind{Group: "security.example.com", Version: "v1", Kind: "ContainerConstraints"}
+	tests := []struct {
+		kind        metav1.GroupVersionKind
+		requestKind *metav1.GroupVersionKind
+		expected    bool
+	}{
+		{sccKind, nil, true},
+		{sccKind, &otherKind, true},
+		{otherKind, &sccKind, true},
+		{otherKind, nil, false},
+		{otherKind, &otherKind, false},
+	}
+	for _, test := range tests {
+		request := admissionctl.Request{AdmissionRequest: admissionv1.AdmissionRequest{Kind: test.kind, RequestKind: test.requestKind}}
+		if got := KindMatches(request, "security.openshift.io", "SecurityContextConstraints"); got != test.expected {
+			t.Fatalf("Expected KindMatches to be %t for kind %v and request kind %v, got %t", test.expected, test.kind, test.requestKind, got)
+		}
+	}
+}
+
+func TestVerifyObjectKind(t *testing.T) {
+	sccKind := metav1.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"}
+	podKind := metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}
+	scc := `{"apiVersion": "security.openshift.io/v1", "kind": "SecurityContextConstraints", "metadata": {"name": "test"}}`
+	pod := `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}}`
+	tests := []struct {
+		kind      metav1.GroupVersionKind
+		object    string
+		oldObject string
+		matches   bool
+	}{
+		{sccKind, scc, scc, true},
+		{sccKind, "", scc, true},
+		{podKind, pod, "", true},
+		{sccKind, pod, "", false},
+		{sccKind, scc, pod, false},
+		{sccKind, `{"metadata": {"name": "test"}}`, "", false},
+	}
+	for _, test := range tests {
+		request := admissionctl.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      test.kind,
+			Object:    runtime.RawExtension{Raw: []byte(test.object)},
+			OldObject: runtime.RawExtension{Raw: []byte(test.oldObject)},
+		}}
+		if err := VerifyObjectKind(request); (err == nil) != test.matches {
+			t.Fatalf("Expected the objects %s and %s to match %v: %t, got %v", test.object, test.oldObject, test.kind, test.matches, err)
+		}
+	}
+}
+
+func TestHasExemptLabel(t *testing.T) {
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"managed.openshift.io/ignore": "true"}}}
+	tests := []struct {
+		selector metav1.LabelSelector
+		expected bool
+	}{
+		{metav1.LabelSelector{}, false},
+		{metav1.LabelSelector{MatchLabels: map[string]string{"managed.openshift.io/ignore": "true"}}, true},
+		{metav1.LabelSelector{MatchLabels: map[string]string{"managed.openshift.io/ignore": "false"}}, false},
+		{metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "managed.openshift.io/ignore", Operator: metav1.LabelSelectorOpExists}}}, true},
+	}
+	for _, test := range tests {
+		got, err := HasExemptLabel(obj, test.selector)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if got != test.expected {
+			t.Fatalf("Expected HasExemptLabel to be %t for %v, got %t", test.expected, test.selector, got)
+		}
+	}
+
+	invalid := metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: "Sometimes"}}}
+	if _, err := HasExemptLabel(obj, invalid); err == nil {
+		t.Fatalf("Expected an error for an invalid selector")
+	}
+}
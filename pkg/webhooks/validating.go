@@ -0,0 +1,49 @@
+package webhooks
+
+import (
+	"fmt"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ValidatingWebhookConfiguration renders the ValidatingWebhookConfiguration
+// which registers the hook with the API server
+func ValidatingWebhookConfiguration(hook Webhook, service ServiceConfig) admissionregv1.ValidatingWebhookConfiguration {
+	failPolicy := hook.FailurePolicy()
+	timeout := hook.TimeoutSeconds()
+	matchPolicy := hook.MatchPolicy()
+	sideEffects := hook.SideEffects()
+
+	return admissionregv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ValidatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("sre-%s", hook.Name()),
+
+			Annotations: map[string]string{
+				// service.beta.openshift.io/inject-cabundle annotation will instruct
+				// service-ca-operator to install a CA cert in the
+				// ValidatingWebhookConfiguration object, which is required for
+				// Kubernetes to communicate securely to the Service.
+				"service.beta.openshift.io/inject-cabundle": "true",
+			},
+		},
+		Webhooks: []admissionregv1.ValidatingWebhook{
+			{
+				AdmissionReviewVersions: []string{"v1"},
+				TimeoutSeconds:          &timeout,
+				SideEffects:             &sideEffects,
+				MatchPolicy:             &matchPolicy,
+				Name:                    fmt.Sprintf("%s.managed.openshift.io", hook.Name()),
+				NamespaceSelector:       namespaceSelector(hook),
+				ObjectSelector:          hook.ObjectSelector(),
+				FailurePolicy:           &failPolicy,
+				ClientConfig:            ClientConfig(hook, service),
+				Rules:                   hook.Rules(),
+			},
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package webhooks_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/alertmanager"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/scc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceSelector(t *testing.T) {
+	service := webhooks.ServiceConfig{Namespace: "openshift-validation-webhook", Name: "validation-webhook", Port: 443}
+
+	configuration := webhooks.ValidatingWebhookConfiguration(alertmanager.NewWebhook(), service)
+	expected := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"kubernetes.io/metadata.name": "openshift-monitoring"},
+	}
+	if selector := configuration.Webhooks[0].NamespaceSelector; !reflect.DeepEqual(selector, expected) {
+		t.Fatalf("Expected %s to only be called for the monitoring namespace, got %v", alertmanager.WebhookName, selector)
+	}
+
+	configuration = webhooks.ValidatingWebhookConfiguration(scc.NewWebhook(), service)
+	if selector := configuration.Webhooks[0].NamespaceSelector; selector != nil {
+		t.Fatalf("Expected %s to be called whatever the namespace, got %v", scc.WebhookName, selector)
+	}
+}
@@ -0,0 +1,230 @@
+package volumesnapshotclass
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/config"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/metrics"
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/webhooks/utils"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	admissionctl "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	WebhookName             string = "volumesnapshotclass-validation"
+	docString               string = `Managed OpenShift Customers may not update or delete the following managed VolumeSnapshotClasses: %s`
+	volumeSnapshotClassKind string = "VolumeSnapshotClass"
+	snapshotGroup           string = "snapshot.storage.k8s.io"
+)
+
+var (
+	timeout int32 = 2
+	log           = logf.Log.WithName(WebhookName)
+	scope         = admissionregv1.ClusterScope
+	rules         = []admissionregv1.RuleWithOperations{
+		{
+			Operations: []admissionregv1.OperationType{"UPDATE", "DELETE"},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{snapshotGroup},
+				APIVersions: []string{"*"},
+				Resources:   []string{"volumesnapshotclasses"},
+				Scope:       &scope,
+			},
+		},
+	}
+	allowedUsers = []string{
+		"kube:admin",
+		"system:admin",
+		"backplane-cluster-admin",
+		"system:serviceaccount:openshift-cluster-storage-operator:cluster-storage-operator",
+	}
+	allowedGroups = []string{
+		"system:serviceaccounts:openshift-backplane-srep",
+	}
+	// managedVolumeSnapshotClasses is the inventory of managed
+	// VolumeSnapshotClasses, which the backup and snapshot workflows depend on
+	managedVolumeSnapshotClasses = []string{
+		"csi-aws-vsc",
+		"csi-gce-pd-vsc",
+		"csi-azuredisk-vsc",
+	}
+)
+
+func init() {
+	config.RegisterPolicySource(WebhookName, effectivePolicy)
+	config.RegisterSection(WebhookName, applySettings,
+		&timeout, &allowedUsers, &allowedGroups, &managedVolumeSnapshotClasses)
+}
+
+// applySettings validates the section of the webhook in the configuration
+// file, and returns how to apply it
+func applySettings(settings config.WebhookSettings) (func(), error) {
+	if settings.Mode == config.ModeDryRun {
+		return nil, fmt.Errorf("mode %s is not supported", settings.Mode)
+	}
+	return func() {
+		if settings.TimeoutSeconds != 0 {
+			timeout = settings.TimeoutSeconds
+		}
+		if settings.Protected != nil {
+			managedVolumeSnapshotClasses = settings.Protected
+		}
+		if settings.AllowedUsers != nil {
+			allowedUsers = settings.AllowedUsers
+		}
+		if settings.AllowedGroups != nil {
+			allowedGroups = settings.AllowedGroups
+		}
+	}, nil
+}
+
+// effectivePolicy returns everything the decisions of the webhook depend on,
+// for config.PolicyVersion
+func effectivePolicy() interface{} {
+	return map[string]interface{}{
+		"managedVolumeSnapshotClasses": managedVolumeSnapshotClasses,
+		"allowedUsers":                 allowedUsers,
+		"allowedGroups":                allowedGroups,
+	}
+}
+
+// VolumeSnapshotClassWebhook protects the VolumeSnapshotClasses the backup
+// and snapshot workflows depend on
+type VolumeSnapshotClassWebhook struct {
+	s *runtime.Scheme
+}
+
+// NewWebhook creates the new webhook
+func NewWebhook() *VolumeSnapshotClassWebhook {
+	return &VolumeSnapshotClassWebhook{
+		s: utils.Scheme,
+	}
+}
+
+// Authorized implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) Authorized(request admissionctl.Request) admissionctl.Response {
+	return s.authorized(request)
+}
+
+func (s *VolumeSnapshotClassWebhook) authorized(request admissionctl.Request) admissionctl.Response {
+	var ret admissionctl.Response
+
+	if isAllowedUserGroup(request) {
+		ret = admissionctl.Allowed("Request is allowed")
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	class, err := s.renderVolumeSnapshotClass(request)
+	if err != nil {
+		log.Error(err, "Couldn't render a VolumeSnapshotClass from the incoming request")
+		return admissionctl.Errored(http.StatusBadRequest, err)
+	}
+
+	if utils.SliceContains(class.GetName(), managedVolumeSnapshotClasses) {
+		log.Info(fmt.Sprintf("%s operation detected on managed VolumeSnapshotClass: %v", request.Operation, class.GetName()))
+		ret = admissionctl.Denied(fmt.Sprintf("Updating or deleting managed VolumeSnapshotClass %v is not allowed, create a VolumeSnapshotClass of your own instead", class.GetName()))
+		ret.UID = request.AdmissionRequest.UID
+		return ret
+	}
+
+	ret = admissionctl.Allowed("Request is allowed")
+	ret.UID = request.AdmissionRequest.UID
+	return ret
+}
+
+// renderVolumeSnapshotClass decodes the existing VolumeSnapshotClass, which
+// both UPDATE and DELETE carry as the OldObject. The snapshot API isn't
+// vendored, so it is decoded generically.
+func (s *VolumeSnapshotClassWebhook) renderVolumeSnapshotClass(request admissionctl.Request) (*unstructured.Unstructured, error) {
+	_, oldObj, err := utils.RenderObjects(s.s, request, func() runtime.Object { return &unstructured.Unstructured{} })
+	if err != nil {
+		metrics.IncrementDecodeErrors(WebhookName, request.Kind.Kind)
+		return nil, err
+	}
+	if oldObj == nil {
+		return nil, fmt.Errorf("VolumeSnapshotClass %s request is missing the existing object", request.Operation)
+	}
+	return oldObj.(*unstructured.Unstructured), nil
+}
+
+// isAllowedUserGroup checks if the user or group is allowed to perform the
+// action. The platform identities shared by every webhook are allowed too.
+func isAllowedUserGroup(request admissionctl.Request) bool {
+	if utils.SliceContains(request.UserInfo.Username, allowedUsers) {
+		return true
+	}
+
+	for _, group := range allowedGroups {
+		if utils.SliceContains(group, request.UserInfo.Groups) {
+			return true
+		}
+	}
+
+	return utils.IsPlatformIdentity(request.UserInfo)
+}
+
+// GetURI implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) GetURI() string {
+	return "/" + WebhookName
+}
+
+// Validate implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) Validate(request admissionctl.Request) bool {
+	valid := true
+	valid = valid && (request.UserInfo.Username != "")
+	valid = valid && utils.KindMatches(request, snapshotGroup, volumeSnapshotClassKind)
+
+	return valid
+}
+
+// Name implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) Name() string {
+	return WebhookName
+}
+
+// FailurePolicy implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) FailurePolicy() admissionregv1.FailurePolicyType {
+	return admissionregv1.Ignore
+}
+
+// MatchPolicy implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) MatchPolicy() admissionregv1.MatchPolicyType {
+	return admissionregv1.Equivalent
+}
+
+// Rules implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) Rules() []admissionregv1.RuleWithOperations {
+	return rules
+}
+
+// ObjectSelector implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) ObjectSelector() *metav1.LabelSelector {
+	return nil
+}
+
+// SideEffects implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) SideEffects() admissionregv1.SideEffectClass {
+	return admissionregv1.SideEffectClassNone
+}
+
+// TimeoutSeconds implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) TimeoutSeconds() int32 {
+	return timeout
+}
+
+// Doc implements Webhook interface
+func (s *VolumeSnapshotClassWebhook) Doc() string {
+	return fmt.Sprintf(docString, managedVolumeSnapshotClasses)
+}
+
+// SyncSetLabelSelector returns the label selector to use in the SyncSet.
+// Return utils.DefaultLabelSelector() to stick with the default
+func (s *VolumeSnapshotClassWebhook) SyncSetLabelSelector() metav1.LabelSelector {
+	return utils.DefaultLabelSelector()
+}
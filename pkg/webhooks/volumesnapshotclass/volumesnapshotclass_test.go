@@ -0,0 +1,152 @@
+package volumesnapshotclass
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift/managed-cluster-validating-webhooks/pkg/testutils"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type volumeSnapshotClassTestSuites struct {
+	testID          string
+	targetName      string
+	operation       admissionv1.Operation
+	username        string
+	userGroups      []string
+	shouldBeAllowed bool
+}
+
+const testObjectRaw string = `
+{
+	"apiVersion": "snapshot.storage.k8s.io/v1",
+	"kind": "VolumeSnapshotClass",
+	"metadata": {
+		"name": "%s",
+		"uid": "1234"
+	},
+	"driver": "ebs.csi.aws.com",
+	"deletionPolicy": "%s"
+}`
+
+func runVolumeSnapshotClassTests(t *testing.T, tests []volumeSnapshotClassTestSuites) {
+	gvk := metav1.GroupVersionKind{
+		Group:   "snapshot.storage.k8s.io",
+		Version: "v1",
+		Kind:    "VolumeSnapshotClass",
+	}
+	gvr := metav1.GroupVersionResource{
+		Group:    "snapshot.storage.k8s.io",
+		Version:  "v1",
+		Resource: "volumesnapshotclasses",
+	}
+
+	for _, test := range tests {
+		// The object of a DELETE is sent as the OldObject
+		obj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, "Retain")),
+		}
+		oldObj := runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(testObjectRaw, test.targetName, "Delete")),
+		}
+
+		hook := NewWebhook()
+		httprequest, err := testutils.CreateHTTPRequest(hook.GetURI(),
+			test.testID, gvk, gvr, test.operation, test.username, test.userGroups, &obj, &oldObj)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+
+		response, err := testutils.SendHTTPRequest(httprequest, hook)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		if response.UID == "" {
+			t.Fatalf("No tracking UID associated with the response.")
+		}
+
+		if response.Allowed != test.shouldBeAllowed {
+			t.Fatalf("Mismatch: %s (groups=%s) %s %s the VolumeSnapshotClass %s in test %s. Test's expectation is that the user %s", test.username, test.userGroups, testutils.CanCanNot(response.Allowed), test.operation, test.targetName, test.testID, testutils.CanCanNot(test.shouldBeAllowed))
+		}
+	}
+}
+
+func TestUserNegative(t *testing.T) {
+	tests := []volumeSnapshotClassTestSuites{
+		{
+			testID:          "user-cant-delete-managed-class",
+			targetName:      "csi-aws-vsc",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-cant-update-managed-class",
+			targetName:      "csi-aws-vsc",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+	}
+	runVolumeSnapshotClassTests(t, tests)
+}
+
+func TestUserPositive(t *testing.T) {
+	tests := []volumeSnapshotClassTestSuites{
+		{
+			testID:          "user-can-delete-customer-class",
+			targetName:      "my-snapshot-class",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "user-can-update-customer-class",
+			targetName:      "my-snapshot-class",
+			operation:       admissionv1.Update,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+		{
+			testID:          "storage-operator-can-delete-managed-class",
+			targetName:      "csi-aws-vsc",
+			operation:       admissionv1.Delete,
+			username:        "system:serviceaccount:openshift-cluster-storage-operator:cluster-storage-operator",
+			userGroups:      []string{"system:serviceaccounts", "system:serviceaccounts:openshift-cluster-storage-operator"},
+			shouldBeAllowed: true,
+		},
+	}
+	runVolumeSnapshotClassTests(t, tests)
+}
+
+func TestConfiguredInventory(t *testing.T) {
+	oldInventory := managedVolumeSnapshotClasses
+	defer func() { managedVolumeSnapshotClasses = oldInventory }()
+	managedVolumeSnapshotClasses = []string{"ocs-storagecluster-rbdplugin-snapclass"}
+
+	tests := []volumeSnapshotClassTestSuites{
+		{
+			testID:          "user-cant-delete-configured-class",
+			targetName:      "ocs-storagecluster-rbdplugin-snapclass",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: false,
+		},
+		{
+			testID:          "user-can-delete-unconfigured-class",
+			targetName:      "csi-aws-vsc",
+			operation:       admissionv1.Delete,
+			username:        "user1",
+			userGroups:      []string{"system:authenticated", "system:authenticated:oauth"},
+			shouldBeAllowed: true,
+		},
+	}
+	runVolumeSnapshotClassTests(t, tests)
+}